@@ -2,15 +2,33 @@ package api
 
 import (
 	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
 	"database/sql"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"net/http"
+	"time"
 )
 
+type accountResponse struct {
+	db.Account
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:          account,
+		AvailableBalance: db.AvailableBalance(account),
+	}
+}
+
 type createAccountRequest struct {
 	Owner    string `json:"owner" binding:"required"`
 	Currency string `json:"currency" binding:"required,currency"`
+	// Type is checking when left out
+	Type string `json:"type" binding:"omitempty,oneof=checking savings"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -19,11 +37,15 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if !server.requireTenantCurrency(ctx, req.Currency) {
+		return
+	}
 
 	arg := db.CreateAccountParams{
 		Owner:    req.Owner,
 		Currency: req.Currency,
 		Balance:  0,
+		Type:     sql.NullString{String: req.Type, Valid: req.Type != ""},
 	}
 
 	account, err := server.store.CreateAccount(ctx, arg)
@@ -39,12 +61,66 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
 }
 
+// getAllAccountsRequest pages with offsets when a page number is given and with cursors otherwise.
+// Every filter is optional. Cursor pages always follow creation order, so sorting needs a page number.
 type getAllAccountsRequest struct {
-	Page int32 `form:"page" binding:"required,min=1"`
-	Size int32 `form:"size" binding:"required,min=5,max=20"`
+	Page          int32      `form:"page" binding:"omitempty,min=1"`
+	Size          int32      `form:"size" binding:"required,min=5,max=20"`
+	Cursor        string     `form:"cursor"`
+	Currency      string     `form:"currency" binding:"omitempty,currency"`
+	MinBalance    *int64     `form:"min_balance"`
+	MaxBalance    *int64     `form:"max_balance"`
+	CreatedAfter  *time.Time `form:"created_after"`
+	CreatedBefore *time.Time `form:"created_before"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=balance created_at id"`
+	Order         string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Status        string     `form:"status" binding:"omitempty,oneof=active frozen closed"`
+}
+
+// accountFilters holds the optional search conditions shared by both pagination modes
+type accountFilters struct {
+	Currency      sql.NullString
+	MinBalance    sql.NullInt64
+	MaxBalance    sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	Status        sql.NullString
+}
+
+func (req getAllAccountsRequest) filters() accountFilters {
+	var filters accountFilters
+	if req.Currency != "" {
+		filters.Currency = sql.NullString{String: req.Currency, Valid: true}
+	}
+	if req.MinBalance != nil {
+		filters.MinBalance = sql.NullInt64{Int64: *req.MinBalance, Valid: true}
+	}
+	if req.MaxBalance != nil {
+		filters.MaxBalance = sql.NullInt64{Int64: *req.MaxBalance, Valid: true}
+	}
+	if req.CreatedAfter != nil {
+		filters.CreatedAfter = sql.NullTime{Time: *req.CreatedAfter, Valid: true}
+	}
+	if req.CreatedBefore != nil {
+		filters.CreatedBefore = sql.NullTime{Time: *req.CreatedBefore, Valid: true}
+	}
+	if req.Status != "" {
+		filters.Status = sql.NullString{String: req.Status, Valid: true}
+	}
+	return filters
+}
+
+func (req getAllAccountsRequest) validate() error {
+	if req.MinBalance != nil && req.MaxBalance != nil && *req.MinBalance > *req.MaxBalance {
+		return errors.New("min_balance must not be above max_balance")
+	}
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	return nil
 }
 
 func (server *Server) getAllAccounts(ctx *gin.Context) {
@@ -53,10 +129,32 @@ func (server *Server) getAllAccounts(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
+	keyset, ok := keysetMode(ctx, req.Page, req.Cursor)
+	if !ok {
+		return
+	}
+	if keyset {
+		server.getAccountsAfter(ctx, req)
+		return
+	}
+
+	filters := req.filters()
 	arg := db.ListAccountsParams{
-		Limit:  req.Size,
-		Offset: (req.Page - 1) * req.Size,
+		Currency:      filters.Currency,
+		MinBalance:    filters.MinBalance,
+		MaxBalance:    filters.MaxBalance,
+		CreatedAfter:  filters.CreatedAfter,
+		CreatedBefore: filters.CreatedBefore,
+		Status:        filters.Status,
+		Sort:          req.Sort,
+		Descending:    req.Order == "desc",
+		Limit:         req.Size,
+		Offset:        (req.Page - 1) * req.Size,
 	}
 	accounts, err := server.store.ListAccounts(ctx, arg)
 
@@ -65,7 +163,55 @@ func (server *Server) getAllAccounts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	response := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		response[i] = newAccountResponse(account)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (server *Server) getAccountsAfter(ctx *gin.Context, req getAllAccountsRequest) {
+	if (req.Sort != "" && req.Sort != "created_at") || req.Order == "desc" {
+		err := errors.New("cursor pages are sorted by created_at ascending, use page to sort otherwise")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := server.decodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filters := req.filters()
+	accounts, err := server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+		Currency:        filters.Currency,
+		MinBalance:      filters.MinBalance,
+		MaxBalance:      filters.MaxBalance,
+		CreatedAfter:    filters.CreatedAfter,
+		CreatedBefore:   filters.CreatedBefore,
+		Status:          filters.Status,
+		CursorCreatedAt: cursor.createdAt(),
+		CursorID:        cursor.id(),
+		Limit:           req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var next *pageCursor
+	if len(accounts) > int(req.Size) {
+		accounts = accounts[:req.Size]
+		last := accounts[len(accounts)-1]
+		next = &pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	response := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		response[i] = newAccountResponse(account)
+	}
+	server.writeCursorPage(ctx, response, next)
 }
 
 type getAccountByIdRequest struct {
@@ -89,5 +235,122 @@ func (server *Server) getAccountById(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// loadHeldAccount fetches an account for a banker or a holder with at least the access level,
+// and writes the error response otherwise
+func (server *Server) loadHeldAccount(ctx *gin.Context, accountID int64, access int) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role == util.BankerRole {
+		return account, true
+	}
+	ok := server.requireHolder(ctx, account, access, "account doesn't belong to the authenticated user")
+	return account, ok
+}
+
+// updateAccountRequest changes only the given attributes, the overdraft limit is set by bankers
+type updateAccountRequest struct {
+	Nickname       *string `json:"nickname" binding:"omitempty,max=64"`
+	OverdraftLimit *int64  `json:"overdraft_limit" binding:"omitempty,min=0"`
+}
+
+func (server *Server) updateAccount(ctx *gin.Context) {
+	var uri getAccountByIdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Nickname == nil && req.OverdraftLimit == nil {
+		err := errors.New("nothing to update")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.OverdraftLimit != nil && authPayload.Role != util.BankerRole {
+		err := errors.New("only bankers can change the overdraft limit")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessManage)
+	if !ok {
+		return
+	}
+	if account.Status == db.AccountStatusClosed {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrAccountClosed))
+		return
+	}
+
+	arg := db.UpdateAccountDetailsParams{ID: account.ID}
+	if req.Nickname != nil {
+		arg.Nickname = sql.NullString{String: *req.Nickname, Valid: true}
+	}
+	if req.OverdraftLimit != nil {
+		arg.OverdraftLimit = sql.NullInt64{Int64: *req.OverdraftLimit, Valid: true}
+	}
+	account, err := server.store.UpdateAccountDetails(ctx, arg)
+	if err != nil {
+		// pot names are unique under their parent
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// deleteAccount closes the account without removing it, the history stays available. Only the primary holder may do it.
+func (server *Server) deleteAccount(ctx *gin.Context) {
+	var uri getAccountByIdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessManage)
+	if !ok {
+		return
+	}
+
+	// closing a frozen account would lift the freeze, only a banker can do that
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	banker := authPayload.Role == util.BankerRole
+	if account.Status == db.AccountStatusFrozen && !banker {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrAccountFrozen))
+		return
+	}
+
+	result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    db.AccountStatusClosed,
+		Actor:     authPayload.Username,
+		Reason:    "closed through the API",
+		Banker:    banker,
+	})
+	if err != nil {
+		writeAccountStatusError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(result.Account))
 }
@@ -0,0 +1,289 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"net/http"
+)
+
+// access levels of account holders, each level allows everything the lower ones do
+const (
+	accessView = iota + 1
+	accessTransact
+	accessManage
+)
+
+var holderAccess = map[string]int{
+	db.AccountHolderViewer:  accessView,
+	db.AccountHolderCoOwner: accessTransact,
+	db.AccountHolderPrimary: accessManage,
+}
+
+// holderAccountID is the account whose holders hold the given one, pots share the holders of their parent
+func holderAccountID(account db.Account) int64 {
+	if account.ParentID.Valid {
+		return account.ParentID.Int64
+	}
+	return account.ID
+}
+
+// errPotHolders rejects holder changes on pots
+var errPotHolders = errors.New("pots share the holders of their parent account")
+
+// holdsAccount tells whether the user holds the account with at least the access level.
+// The owner is the primary holder, so the holders are only looked up for other users.
+func (server *Server) holdsAccount(ctx *gin.Context, account db.Account, username string, access int) (bool, error) {
+	if account.Owner == username {
+		return true, nil
+	}
+
+	holder, err := server.store.GetAccountHolder(ctx, db.GetAccountHolderParams{
+		AccountID: holderAccountID(account),
+		Username:  username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return holderAccess[holder.Role] >= access, nil
+}
+
+// requireHolder checks the authenticated user holds the account with at least the access level
+// and writes the error response otherwise
+func (server *Server) requireHolder(ctx *gin.Context, account db.Account, access int, message string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	held, err := server.holdsAccount(ctx, account, authPayload.Username, access)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !held {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New(message)))
+		return false
+	}
+	return true
+}
+
+type accountHoldersUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) listAccountHolders(ctx *gin.Context) {
+	var uri accountHoldersUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessView)
+	if !ok {
+		return
+	}
+
+	holders, err := server.store.ListAccountHolders(ctx, holderAccountID(account))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, holders)
+}
+
+type inviteAccountHolderRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=co_owner viewer"`
+}
+
+// inviteAccountHolder lets the primary holder invite another user, who becomes a holder once they accept
+func (server *Server) inviteAccountHolder(ctx *gin.Context) {
+	var uri accountHoldersUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req inviteAccountHolderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessManage)
+	if !ok {
+		return
+	}
+	if account.ParentID.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(errPotHolders))
+		return
+	}
+	if account.Status == db.AccountStatusClosed {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrAccountClosed))
+		return
+	}
+
+	held, err := server.holdsAccount(ctx, account, req.Username, accessView)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if held {
+		err := errors.New("user already holds the account")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitation, err := server.store.CreateAccountInvitation(ctx, db.CreateAccountInvitationParams{
+		AccountID: account.ID,
+		Invitee:   req.Username,
+		Role:      req.Role,
+		InvitedBy: authPayload.Username,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			case "unique_violation":
+				ctx.JSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitation)
+}
+
+type accountHolderUri struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+// removeAccountHolder lets the primary holder remove another holder, or a holder leave the account.
+// The primary holder can't be removed.
+func (server *Server) removeAccountHolder(ctx *gin.Context) {
+	var uri accountHolderUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	access := accessManage
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username == authPayload.Username {
+		access = accessView
+	}
+	account, ok := server.loadHeldAccount(ctx, uri.ID, access)
+	if !ok {
+		return
+	}
+	if account.ParentID.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(errPotHolders))
+		return
+	}
+
+	holder, err := server.store.GetAccountHolder(ctx, db.GetAccountHolderParams{
+		AccountID: account.ID,
+		Username:  uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if holder.Role == db.AccountHolderPrimary {
+		err := errors.New("the primary holder can't be removed")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteAccountHolder(ctx, db.DeleteAccountHolderParams{
+		AccountID: account.ID,
+		Username:  holder.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// listAccountInvitations shows the authenticated user the invitations waiting for an answer
+func (server *Server) listAccountInvitations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitations, err := server.store.ListPendingAccountInvitations(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+type accountInvitationUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) acceptAccountInvitation(ctx *gin.Context) {
+	server.respondAccountInvitation(ctx, true)
+}
+
+func (server *Server) declineAccountInvitation(ctx *gin.Context) {
+	server.respondAccountInvitation(ctx, false)
+}
+
+// respondAccountInvitation answers an invitation, only the invitee may do it
+func (server *Server) respondAccountInvitation(ctx *gin.Context, accept bool) {
+	var uri accountInvitationUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	invitation, err := server.store.GetAccountInvitation(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if invitation.Invitee != authPayload.Username {
+		err := errors.New("invitation isn't for the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RespondAccountInvitationTx(ctx, db.RespondAccountInvitationTxParams{
+		InvitationID: invitation.ID,
+		Accept:       accept,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInvitationNotPending),
+			errors.Is(err, db.ErrAccountClosed),
+			isUniqueViolation(err):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
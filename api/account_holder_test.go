@@ -0,0 +1,376 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApi_InviteAccountHolder(t *testing.T) {
+	account := randomAccount()
+	invitee := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderCoOwner},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Eq(db.GetAccountHolderParams{AccountID: account.ID, Username: invitee})).
+					Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				arg := db.CreateAccountInvitationParams{
+					AccountID: account.ID,
+					Invitee:   invitee,
+					Role:      db.AccountHolderCoOwner,
+					InvitedBy: account.Owner,
+				}
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.AccountInvitation{ID: 1, AccountID: account.ID, Invitee: invitee, Status: db.InvitationStatusPending}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var invitation db.AccountInvitation
+				err := json.Unmarshal(recorder.Body.Bytes(), &invitation)
+				require.NoError(t, err)
+				require.Equal(t, invitee, invitation.Invitee)
+				require.Equal(t, db.InvitationStatusPending, invitation.Status)
+			},
+		},
+		{
+			name:     "CoOwnerCannotInvite",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderViewer},
+			username: "partner",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountHolder{AccountID: account.ID, Username: "partner", Role: db.AccountHolderCoOwner}, nil)
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyHolder",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderViewer},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountHolder{AccountID: account.ID, Username: invitee, Role: db.AccountHolderViewer}, nil)
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyInvited",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderViewer},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountInvitation{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownUser",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderViewer},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountInvitation{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "PrimaryRole",
+			body:     gin.H{"username": invitee, "role": db.AccountHolderPrimary},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/invitations", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_RespondAccountInvitation(t *testing.T) {
+	invitation := db.AccountInvitation{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: util.RandomInt(1, 1000),
+		Invitee:   util.RandomOwner(),
+		Role:      db.AccountHolderCoOwner,
+		Status:    db.InvitationStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Accept",
+			action:   "accept",
+			username: invitation.Invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				arg := db.RespondAccountInvitationTxParams{InvitationID: invitation.ID, Accept: true}
+				store.EXPECT().RespondAccountInvitationTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Decline",
+			action:   "decline",
+			username: invitation.Invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				arg := db.RespondAccountInvitationTxParams{InvitationID: invitation.ID, Accept: false}
+				store.EXPECT().RespondAccountInvitationTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotInvitee",
+			action:   "accept",
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				store.EXPECT().RespondAccountInvitationTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotPending",
+			action:   "accept",
+			username: invitation.Invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				store.EXPECT().RespondAccountInvitationTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.RespondAccountInvitationTxResult{}, db.ErrInvitationNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			action:   "accept",
+			username: invitation.Invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(db.AccountInvitation{}, sql.ErrNoRows)
+				store.EXPECT().RespondAccountInvitationTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invitations/%d/%s", invitation.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_RemoveAccountHolder(t *testing.T) {
+	account := randomAccount()
+	viewer := db.AccountHolder{AccountID: account.ID, Username: util.RandomOwner(), Role: db.AccountHolderViewer}
+	coOwner := db.AccountHolder{AccountID: account.ID, Username: util.RandomOwner(), Role: db.AccountHolderCoOwner}
+	primary := db.AccountHolder{AccountID: account.ID, Username: account.Owner, Role: db.AccountHolderPrimary}
+
+	testCases := []struct {
+		name          string
+		target        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "PrimaryRemovesViewer",
+			target: viewer.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Eq(db.GetAccountHolderParams{AccountID: account.ID, Username: viewer.Username})).
+					Times(1).Return(viewer, nil)
+				arg := db.DeleteAccountHolderParams{AccountID: account.ID, Username: viewer.Username}
+				store.EXPECT().DeleteAccountHolder(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "HolderLeaves",
+			target: viewer.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(2).Return(viewer, nil)
+				store.EXPECT().DeleteAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "CoOwnerCannotRemoveOthers",
+			target: viewer.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, coOwner.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(coOwner, nil)
+				store.EXPECT().DeleteAccountHolder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "PrimaryStays",
+			target: account.Owner,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(primary, nil)
+				store.EXPECT().DeleteAccountHolder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotAHolder",
+			target: util.RandomOwner(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().DeleteAccountHolder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/holders/%s", account.ID, tc.target)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
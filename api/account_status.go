@@ -0,0 +1,97 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type accountStatusUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type accountStatusRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountStatusFrozen)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountStatusActive)
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountStatusClosed)
+}
+
+// changeAccountStatus moves an account to the status, recording the caller and the reason they gave
+func (server *Server) changeAccountStatus(ctx *gin.Context, status string) {
+	var uri accountStatusUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req accountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: uri.ID,
+		Status:    status,
+		Actor:     authPayload.Username,
+		Reason:    req.Reason,
+		Banker:    authPayload.Role == util.BankerRole,
+	})
+	if err != nil {
+		writeAccountStatusError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func writeAccountStatusError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrInvalidStatusTransition),
+		errors.Is(err, db.ErrAccountNotEmpty),
+		errors.Is(err, db.ErrAccountHasHolds),
+		errors.Is(err, db.ErrPotsNotEmpty),
+		errors.Is(err, db.ErrAccountFrozen):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// listAccountStatusChanges shows the holders or a banker every status change of the account, oldest first
+func (server *Server) listAccountStatusChanges(ctx *gin.Context) {
+	var uri accountStatusUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessView)
+	if !ok {
+		return
+	}
+
+	changes, err := server.store.ListAccountStatusChanges(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	mockdb "code-with-go/db/mock"
 	db "code-with-go/db/sqlc"
+	"code-with-go/token"
 	"code-with-go/util"
 	"database/sql"
 	"encoding/json"
@@ -128,6 +129,10 @@ func TestApi_CreateAccount(t *testing.T) {
 					Balance:  account.Balance,
 					Currency: account.Currency,
 				}
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq(db.DefaultTenantID)).
+					Times(1).
+					Return(defaultTenant(), nil)
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Eq(params)).
 					Times(1).
@@ -144,6 +149,54 @@ func TestApi_CreateAccount(t *testing.T) {
 				requireBodyMatchesAccount(t, account, recorder.Body)
 			},
 		},
+		{
+			name: "Savings",
+			body: gin.H{
+				"owner":    account.Owner,
+				"currency": account.Currency,
+				"type":     db.AccountTypeSavings,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Balance:  account.Balance,
+					Currency: account.Currency,
+					Type:     sql.NullString{String: db.AccountTypeSavings, Valid: true},
+				}
+				savings := account
+				savings.Type = db.AccountTypeSavings
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq(db.DefaultTenantID)).
+					Times(1).
+					Return(defaultTenant(), nil)
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(savings, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				savings := account
+				savings.Type = db.AccountTypeSavings
+				requireBodyMatchesAccount(t, savings, recorder.Body)
+			},
+		},
+		{
+			name: "InvalidType",
+			body: gin.H{
+				"owner":    account.Owner,
+				"currency": account.Currency,
+				"type":     "brokerage",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Bad Request",
 			body: gin.H{
@@ -160,6 +213,27 @@ func TestApi_CreateAccount(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "CurrencyNotOffered",
+			body: gin.H{
+				"owner":    account.Owner,
+				"currency": account.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				tenant := defaultTenant()
+				tenant.Currencies = []string{}
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq(db.DefaultTenantID)).
+					Times(1).
+					Return(tenant, nil)
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Internal Server Error",
 			body: gin.H{
@@ -167,6 +241,10 @@ func TestApi_CreateAccount(t *testing.T) {
 				"currency": account.Currency,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTenant(gomock.Any(), gomock.Eq(db.DefaultTenantID)).
+					Times(1).
+					Return(defaultTenant(), nil)
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -306,15 +384,636 @@ func TestApi_GetAllAccounts(t *testing.T) {
 	}
 }
 
+func TestApi_GetAllAccountsKeyset(t *testing.T) {
+	n := 6
+	accounts := make([]db.Account, n)
+	for i := 0; i < n; i++ {
+		accounts[i] = randomAccount()
+		accounts[i].CreatedAt = time.Date(2022, 1, 1, 0, 0, i, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name          string
+		query         func(server *Server) string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstPage",
+			query: func(server *Server) string {
+				return "size=5"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{Limit: 6}
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response struct {
+					Items      []accountResponse `json:"items"`
+					NextCursor string            `json:"next_cursor"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Items, 5)
+
+				cursor, err := server.decodeCursor(response.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, accounts[4].ID, cursor.ID)
+				require.True(t, accounts[4].CreatedAt.Equal(cursor.CreatedAt))
+
+				link := recorder.Header().Get("Link")
+				require.Contains(t, link, "cursor="+response.NextCursor)
+				require.Contains(t, link, `rel="next"`)
+			},
+		},
+		{
+			name: "LastPage",
+			query: func(server *Server) string {
+				return "size=5&cursor=" + server.encodeCursor(pageCursor{CreatedAt: accounts[4].CreatedAt, ID: accounts[4].ID})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{
+					CursorCreatedAt: sql.NullTime{Time: accounts[4].CreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: accounts[4].ID, Valid: true},
+					Limit:           6,
+				}
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts[5:], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response cursorPageResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Empty(t, response.NextCursor)
+				require.Empty(t, recorder.Header().Get("Link"))
+			},
+		},
+		{
+			name: "TamperedCursor",
+			query: func(server *Server) string {
+				return "size=5&cursor=" + server.encodeCursor(pageCursor{ID: 1}) + "x"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PageAndCursor",
+			query: func(server *Server) string {
+				return "page=1&size=5&cursor=" + server.encodeCursor(pageCursor{ID: 1})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: func(server *Server) string {
+				return "size=5"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query(server), nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestApi_SearchAccounts(t *testing.T) {
+	createdAfter := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdBefore := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	dates := fmt.Sprintf("created_after=%s&created_before=%s", createdAfter.Format(time.RFC3339), createdBefore.Format(time.RFC3339))
+
+	currency := sql.NullString{String: util.USD, Valid: true}
+	minBalance := sql.NullInt64{Int64: 10, Valid: true}
+	maxBalance := sql.NullInt64{Int64: 100, Valid: true}
+	after := sql.NullTime{Time: createdAfter, Valid: true}
+	before := sql.NullTime{Time: createdBefore, Valid: true}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected db.ListAccountsParams
+	}{
+		{
+			name:     "NoFilter",
+			query:    "",
+			expected: db.ListAccountsParams{},
+		},
+		{
+			name:     "Currency",
+			query:    "currency=USD",
+			expected: db.ListAccountsParams{Currency: currency},
+		},
+		{
+			name:     "BalanceRange",
+			query:    "min_balance=10&max_balance=100",
+			expected: db.ListAccountsParams{MinBalance: minBalance, MaxBalance: maxBalance},
+		},
+		{
+			name:     "MinBalanceOnly",
+			query:    "min_balance=10",
+			expected: db.ListAccountsParams{MinBalance: minBalance},
+		},
+		{
+			name:     "DateRange",
+			query:    dates,
+			expected: db.ListAccountsParams{CreatedAfter: after, CreatedBefore: before},
+		},
+		{
+			name:  "AllFilters",
+			query: "currency=USD&min_balance=10&max_balance=100&" + dates,
+			expected: db.ListAccountsParams{
+				Currency:      currency,
+				MinBalance:    minBalance,
+				MaxBalance:    maxBalance,
+				CreatedAfter:  after,
+				CreatedBefore: before,
+			},
+		},
+		{
+			name:     "SortBalanceAsc",
+			query:    "sort=balance&order=asc",
+			expected: db.ListAccountsParams{Sort: "balance"},
+		},
+		{
+			name:     "SortBalanceDesc",
+			query:    "sort=balance&order=desc",
+			expected: db.ListAccountsParams{Sort: "balance", Descending: true},
+		},
+		{
+			name:     "SortCreatedAtDesc",
+			query:    "sort=created_at&order=desc",
+			expected: db.ListAccountsParams{Sort: "created_at", Descending: true},
+		},
+		{
+			name:     "SortIDDesc",
+			query:    "sort=id&order=desc",
+			expected: db.ListAccountsParams{Sort: "id", Descending: true},
+		},
+		{
+			name:     "Status",
+			query:    "status=frozen",
+			expected: db.ListAccountsParams{Status: sql.NullString{String: db.AccountStatusFrozen, Valid: true}},
+		},
+		{
+			name:     "FiltersAndSort",
+			query:    "currency=USD&max_balance=100&sort=balance&order=desc",
+			expected: db.ListAccountsParams{Currency: currency, MaxBalance: maxBalance, Sort: "balance", Descending: true},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			arg := tc.expected
+			arg.Limit = 5
+			arg.Offset = 5
+			store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{}, nil)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?page=2&size=5&"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
+func TestApi_SearchAccountsInvalid(t *testing.T) {
+	for _, query := range []string{
+		"page=1&size=5&sort=owner",
+		"page=1&size=5&sort=balance&order=up",
+		"page=1&size=5&currency=XYZ",
+		"page=1&size=5&status=deleted",
+		"page=1&size=5&min_balance=100&max_balance=10",
+		"page=1&size=5&created_after=2022-02-01T00:00:00Z&created_before=2022-01-01T00:00:00Z",
+		"page=1&size=5&min_balance=ten",
+		"size=5&sort=balance",
+		"size=5&order=desc",
+	} {
+		t.Run(query, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
+
+func TestApi_SearchAccountsKeyset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListAccountsAfterParams{
+		Currency:   sql.NullString{String: util.EUR, Valid: true},
+		MinBalance: sql.NullInt64{Int64: 0, Valid: true},
+		Limit:      6,
+	}
+	store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{}, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/accounts?size=5&currency=EUR&min_balance=0&sort=created_at", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestApi_UpdateAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Nickname",
+			body: gin.H{"nickname": "rainy day"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAccountDetailsParams{
+					ID:       account.ID,
+					Nickname: sql.NullString{String: "rainy day", Valid: true},
+				}
+				updated := account
+				updated.Nickname = "rainy day"
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				updated := account
+				updated.Nickname = "rainy day"
+				requireBodyMatchesAccount(t, updated, recorder.Body)
+			},
+		},
+		{
+			name: "BankerOverdraftLimit",
+			body: gin.H{"overdraft_limit": 500},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAccountDetailsParams{
+					ID:             account.ID,
+					OverdraftLimit: sql.NullInt64{Int64: 500, Valid: true},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OwnerOverdraftLimit",
+			body: gin.H{"overdraft_limit": 500},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			body: gin.H{"nickname": "mine now"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Closed",
+			body: gin.H{"nickname": "old"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account
+				closed.Status = db.AccountStatusClosed
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Empty",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeOverdraftLimit",
+			body: gin.H{"overdraft_limit": -1},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"nickname": "rainy day"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountDetails(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d", account.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_DeleteAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account
+				closed.Status = db.AccountStatusClosed
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Eq(db.ChangeAccountStatusTxParams{
+					AccountID: account.ID,
+					Status:    db.AccountStatusClosed,
+					Actor:     account.Owner,
+					Reason:    "closed through the API",
+				})).Times(1).Return(db.ChangeAccountStatusTxResult{Account: closed}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				closed := account
+				closed.Status = db.AccountStatusClosed
+				requireBodyMatchesAccount(t, closed, recorder.Body)
+			},
+		},
+		{
+			name: "FrozenByHolder",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "FrozenByBanker",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+				closed := account
+				closed.Status = db.AccountStatusClosed
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Eq(db.ChangeAccountStatusTxParams{
+					AccountID: account.ID,
+					Status:    db.AccountStatusClosed,
+					Actor:     "banker",
+					Reason:    "closed through the API",
+					Banker:    true,
+				})).Times(1).Return(db.ChangeAccountStatusTxResult{Account: closed}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NonZeroBalance",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "PendingHolds",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrAccountHasHolds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyClosed",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrInvalidStatusTransition)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d", account.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
 func requireBodyMatchesAccount(t *testing.T, expected db.Account, body *bytes.Buffer) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
 
-	var response db.Account
+	var response accountResponse
 	err = json.Unmarshal(data, &response)
 
 	require.NoError(t, err)
-	require.Equal(t, expected, response)
+	require.Equal(t, expected, response.Account)
+	require.Equal(t, expected.Balance-expected.HeldBalance+expected.OverdraftLimit, response.AvailableBalance)
 }
 
 func requireBodyMatchAccounts(t *testing.T, expected []db.Account, body *bytes.Buffer) {
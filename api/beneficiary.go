@@ -0,0 +1,257 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var (
+	errBeneficiaryNotOwned  = errors.New("beneficiary doesn't belong to the authenticated user")
+	errBeneficiaryAmbiguous = errors.New("a beneficiary can't be given along with another recipient")
+)
+
+// getRecipientAccount fetches the account a transfer goes to, by id, through its payee or its beneficiary.
+// An account the caller saved as a beneficiary keeps its cooling-off however it is given.
+func (server *Server) getRecipientAccount(ctx *gin.Context, req createTransferRequest) (db.Account, int, error) {
+	if req.BeneficiaryID != 0 {
+		return server.getBeneficiaryAccount(ctx, req)
+	}
+
+	account, status, err := server.getPayeeAccount(ctx, req.ToAccountID, req.payee(), req.Currency)
+	if err != nil {
+		return account, status, err
+	}
+	if status, err := server.checkRecipientCoolingOff(ctx, account.ID, req.Amount); err != nil {
+		return db.Account{}, status, err
+	}
+	return account, http.StatusOK, nil
+}
+
+// checkRecipientCoolingOff fails when the caller saved the account with the id as a beneficiary
+// and the amount is too large for it while its cooling-off lasts.
+// Every way of paying an account goes through it, holds, escrows and payment requests included.
+func (server *Server) checkRecipientCoolingOff(ctx *gin.Context, accountID int64, amount int64) (int, error) {
+	if amount <= server.config.BeneficiaryLargeAmount || server.config.BeneficiaryCoolingOff <= 0 {
+		return http.StatusOK, nil
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	beneficiary, err := server.store.GetBeneficiaryByAccount(ctx, db.GetBeneficiaryByAccountParams{
+		Owner:     authPayload.Username,
+		AccountID: accountID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusOK, nil
+		}
+		return http.StatusInternalServerError, err
+	}
+	if err := server.checkCoolingOff(beneficiary, amount); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+// getBeneficiaryAccount fetches the account of the caller's beneficiary a transfer goes to
+func (server *Server) getBeneficiaryAccount(ctx *gin.Context, req createTransferRequest) (db.Account, int, error) {
+	if req.ToAccountID != 0 || req.payee().given() > 0 {
+		return db.Account{}, http.StatusBadRequest, errBeneficiaryAmbiguous
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	beneficiary, err := server.store.GetBeneficiary(ctx, req.BeneficiaryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Account{}, http.StatusNotFound, err
+		}
+		return db.Account{}, http.StatusInternalServerError, err
+	}
+	if beneficiary.Owner != authPayload.Username {
+		return db.Account{}, http.StatusForbidden, errBeneficiaryNotOwned
+	}
+	if beneficiary.Currency != req.Currency {
+		err := fmt.Errorf("beneficiary [%d] currency mismatch: %s vs %s", beneficiary.ID, beneficiary.Currency, req.Currency)
+		return db.Account{}, http.StatusBadRequest, err
+	}
+	if err := server.checkCoolingOff(beneficiary, req.Amount); err != nil {
+		return db.Account{}, http.StatusUnprocessableEntity, err
+	}
+	return server.getAccountInCurrency(ctx, beneficiary.AccountID, req.Currency)
+}
+
+// checkCoolingOff fails when the amount is too large for a beneficiary still in its cooling-off period
+func (server *Server) checkCoolingOff(beneficiary db.Beneficiary, amount int64) error {
+	if amount > server.config.BeneficiaryLargeAmount && time.Now().Before(beneficiary.CoolingOffUntil) {
+		return fmt.Errorf("beneficiary can't receive more than %d until %s", server.config.BeneficiaryLargeAmount,
+			beneficiary.CoolingOffUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// createBeneficiaryRequest names the account by id or by exactly one of a username, a verified email or an alias
+type createBeneficiaryRequest struct {
+	Nickname  string `json:"nickname" binding:"required,max=64"`
+	AccountID int64  `json:"account_id" binding:"required_without_all=Username Email Alias,min=0"`
+	Username  string `json:"username" binding:"omitempty,alphanum"`
+	Email     string `json:"email" binding:"omitempty,email"`
+	Alias     string `json:"alias" binding:"omitempty,alphanum"`
+	Currency  string `json:"currency" binding:"required,currency"`
+}
+
+// createBeneficiary saves a destination of the authenticated user's transfers.
+// Large amounts can only be sent to it once the cooling-off period is over.
+func (server *Server) createBeneficiary(ctx *gin.Context) {
+	var req createBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	p := payee{Username: req.Username, Email: req.Email, Alias: req.Alias}
+	account, status, err := server.getPayeeAccount(ctx, req.AccountID, p, req.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	beneficiary, err := server.store.CreateBeneficiary(ctx, db.CreateBeneficiaryParams{
+		Owner:           authPayload.Username,
+		Nickname:        req.Nickname,
+		AccountID:       account.ID,
+		Currency:        account.Currency,
+		CoolingOffUntil: time.Now().Add(server.config.BeneficiaryCoolingOff),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			err := errors.New("the nickname or the account is already saved as a beneficiary")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type listBeneficiariesRequest struct {
+	Page int32 `form:"page" binding:"required,min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listBeneficiaries lists the authenticated user's beneficiaries by nickname
+func (server *Server) listBeneficiaries(ctx *gin.Context) {
+	var req listBeneficiariesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	beneficiaries, err := server.store.ListBeneficiaries(ctx, db.ListBeneficiariesParams{
+		Owner:  authPayload.Username,
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiaries)
+}
+
+type beneficiaryUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadBeneficiary fetches the beneficiary of the uri and makes sure the authenticated user saved it.
+// It writes the error response itself and returns false on failure.
+func (server *Server) loadBeneficiary(ctx *gin.Context) (db.Beneficiary, bool) {
+	var uri beneficiaryUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Beneficiary{}, false
+	}
+
+	beneficiary, err := server.store.GetBeneficiary(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return beneficiary, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return beneficiary, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if beneficiary.Owner != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errBeneficiaryNotOwned))
+		return beneficiary, false
+	}
+	return beneficiary, true
+}
+
+func (server *Server) getBeneficiary(ctx *gin.Context) {
+	beneficiary, ok := server.loadBeneficiary(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type updateBeneficiaryRequest struct {
+	Nickname string `json:"nickname" binding:"required,max=64"`
+}
+
+// updateBeneficiary renames a beneficiary, pointing at another account means saving a new one
+func (server *Server) updateBeneficiary(ctx *gin.Context) {
+	var req updateBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiary, ok := server.loadBeneficiary(ctx)
+	if !ok {
+		return
+	}
+
+	beneficiary, err := server.store.UpdateBeneficiary(ctx, db.UpdateBeneficiaryParams{
+		ID:       beneficiary.ID,
+		Nickname: req.Nickname,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			err := fmt.Errorf("a beneficiary named %q already exists", req.Nickname)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+func (server *Server) deleteBeneficiary(ctx *gin.Context) {
+	beneficiary, ok := server.loadBeneficiary(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.store.DeleteBeneficiary(ctx, beneficiary.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"crypto/ed25519"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type listChainRootsRequest struct {
+	Page int32 `form:"page" binding:"required,min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=20"`
+}
+
+type listChainRootsResponse struct {
+	// PublicKey verifies the signature of the date followed by the root hash
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	Roots     []db.ChainRoot    `json:"roots"`
+}
+
+// listChainRoots exports the signed daily roots of the entry hash chains, latest first, for off-site anchoring
+func (server *Server) listChainRoots(ctx *gin.Context) {
+	var req listChainRootsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	roots, err := server.store.ListChainRoots(ctx, db.ListChainRootsParams{
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listChainRootsResponse{
+		PublicKey: server.chainPublicKey,
+		Roots:     roots,
+	})
+}
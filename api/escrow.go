@@ -0,0 +1,331 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var errNotEscrowParty = errors.New("escrow agreement doesn't belong to the authenticated user")
+
+type createEscrowRequest struct {
+	FromAccountID   int64     `json:"from_account_id" binding:"required,min=1"`
+	SellerAccountID int64     `json:"seller_account_id" binding:"required,min=1"`
+	Amount          int64     `json:"amount" binding:"required,gt=0"`
+	Currency        string    `json:"currency" binding:"required,currency"`
+	Condition       string    `json:"condition" binding:"required,max=500"`
+	Deadline        time.Time `json:"deadline" binding:"required"`
+}
+
+// createEscrow moves funds from the buyer's account into escrow until the seller meets the condition.
+// The buyer gets them back if they are neither released nor disputed by the deadline.
+func (server *Server) createEscrow(ctx *gin.Context) {
+	var req createEscrowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	now := time.Now()
+	if !req.Deadline.After(now) || req.Deadline.After(now.Add(server.config.EscrowMaxDuration)) {
+		err := fmt.Errorf("deadline must be in the next %s", server.config.EscrowMaxDuration)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, status, err := server.getAccountInCurrency(ctx, req.FromAccountID, req.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	if !server.requireHolder(ctx, fromAccount, accessTransact, "from account doesn't belong to the authenticated user") {
+		return
+	}
+
+	sellerAccount, status, err := server.getAccountInCurrency(ctx, req.SellerAccountID, req.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	if sellerAccount.Type == db.AccountTypeEscrow || sellerAccount.ID == fromAccount.ID {
+		err := errors.New("seller account must be another customer account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if status, err := server.checkRecipientCoolingOff(ctx, sellerAccount.ID, req.Amount); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	escrowAccount, err := server.store.GetAccountByOwnerAndCurrency(ctx, db.GetAccountByOwnerAndCurrencyParams{
+		Owner:    db.EscrowOwner,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.EscrowFundingTransfer(db.EscrowAgreement{
+		BuyerAccountID:  fromAccount.ID,
+		EscrowAccountID: escrowAccount.ID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+	})
+	if server.needsApproval(arg) {
+		err := fmt.Errorf("transfers above %d need approval and can't fund an escrow", server.config.TransferApprovalThreshold)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+	decision, err := server.screenTransferNow(ctx, fromAccount, &arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if decision != nil {
+		writeFraudDecision(ctx, *decision)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.CreateEscrowTx(ctx, db.CreateEscrowTxParams{
+		Buyer:           authPayload.Username,
+		BuyerAccountID:  fromAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Condition:       req.Condition,
+		Deadline:        req.Deadline,
+		Screening:       arg.Screening,
+	})
+	if err != nil {
+		writeEscrowError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type listEscrowsUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEscrowsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=funded disputed released refunded"`
+	Page   int32  `form:"page" binding:"required,min=1"`
+	Size   int32  `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listEscrows lists the agreements an account is the buyer or the seller of, newest first
+func (server *Server) listEscrows(ctx *gin.Context) {
+	var uri listEscrowsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listEscrowsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessView)
+	if !ok {
+		return
+	}
+
+	agreements, err := server.store.ListEscrowAgreements(ctx, db.ListEscrowAgreementsParams{
+		AccountID: account.ID,
+		Status:    sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:     req.Size,
+		Offset:    (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, agreements)
+}
+
+type escrowUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadEscrow fetches the agreement in the uri along with the buyer's and the seller's accounts
+func (server *Server) loadEscrow(ctx *gin.Context) (agreement db.EscrowAgreement, buyerAccount, sellerAccount db.Account, ok bool) {
+	var uri escrowUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	agreement, err := server.store.GetEscrowAgreement(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	buyerAccount, err = server.store.GetAccount(ctx, agreement.BuyerAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sellerAccount, err = server.store.GetAccount(ctx, agreement.SellerAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	return agreement, buyerAccount, sellerAccount, true
+}
+
+func (server *Server) getEscrow(ctx *gin.Context) {
+	agreement, buyerAccount, sellerAccount, ok := server.loadEscrow(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role != util.BankerRole {
+		if _, ok := server.escrowParty(ctx, buyerAccount, sellerAccount, accessView); !ok {
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, agreement)
+}
+
+// releaseEscrow pays the escrowed funds to the seller.
+// The buyer releases them once the condition is met, bankers release disputed ones.
+func (server *Server) releaseEscrow(ctx *gin.Context) {
+	server.settleEscrow(ctx, true)
+}
+
+// refundEscrow gives the escrowed funds back to the buyer.
+// The seller refunds when they can't meet the condition, bankers refund disputed ones.
+func (server *Server) refundEscrow(ctx *gin.Context) {
+	server.settleEscrow(ctx, false)
+}
+
+func (server *Server) settleEscrow(ctx *gin.Context, release bool) {
+	agreement, buyerAccount, sellerAccount, ok := server.loadEscrow(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	banker := authPayload.Role == util.BankerRole
+	if !banker {
+		// each side can only give the funds up to the other one
+		account, message := buyerAccount, "only a banker or the buyer can release escrowed funds"
+		if !release {
+			account, message = sellerAccount, "only a banker or the seller can refund escrowed funds"
+		}
+		if !server.requireHolder(ctx, account, accessTransact, message) {
+			return
+		}
+	}
+
+	arg := db.SettleEscrowTxParams{
+		AgreementID:    agreement.ID,
+		Actor:          authPayload.Username,
+		ResolveDispute: banker,
+	}
+
+	var result db.EscrowTxResult
+	var err error
+	if release {
+		result, err = server.store.ReleaseEscrowTx(ctx, arg)
+	} else {
+		result, err = server.store.RefundEscrowTx(ctx, arg)
+	}
+	if err != nil {
+		writeEscrowError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type disputeEscrowRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// disputeEscrow stops the deadline from refunding the buyer, a banker then decides where the funds go
+func (server *Server) disputeEscrow(ctx *gin.Context) {
+	var req disputeEscrowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	agreement, buyerAccount, sellerAccount, ok := server.loadEscrow(ctx)
+	if !ok {
+		return
+	}
+
+	username, ok := server.escrowParty(ctx, buyerAccount, sellerAccount, accessTransact)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.DisputeEscrowTx(ctx, db.DisputeEscrowTxParams{
+		AgreementID: agreement.ID,
+		DisputedBy:  username,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		writeEscrowError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// escrowParty checks the authenticated user holds the buyer's or the seller's account with the access level,
+// and writes the error response otherwise
+func (server *Server) escrowParty(ctx *gin.Context, buyerAccount, sellerAccount db.Account, access int) (string, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	for _, account := range []db.Account{buyerAccount, sellerAccount} {
+		held, err := server.holdsAccount(ctx, account, authPayload.Username, access)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return "", false
+		}
+		if held {
+			return authPayload.Username, true
+		}
+	}
+
+	ctx.JSON(http.StatusForbidden, errorResponse(errNotEscrowParty))
+	return "", false
+}
+
+func writeEscrowError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrEscrowSettled),
+		errors.Is(err, db.ErrEscrowDisputed),
+		errors.Is(err, db.ErrEscrowDeadlinePassed):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrEscrowAccountMismatch):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		writeTransferError(ctx, err)
+	}
+}
@@ -0,0 +1,469 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApi_CreateEscrow(t *testing.T) {
+	buyerAccount := randomAccount()
+	buyerAccount.Currency = util.USD
+	sellerAccount := randomAccount()
+	sellerAccount.Currency = util.USD
+	escrowAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: db.EscrowOwner, Currency: util.USD, Type: db.AccountTypeEscrow}
+	deadline := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	body := func(amount int64, deadline time.Time, sellerAccountID int64) gin.H {
+		return gin.H{
+			"from_account_id":   buyerAccount.ID,
+			"seller_account_id": sellerAccountID,
+			"amount":            amount,
+			"currency":          util.USD,
+			"condition":         "Ship the bike",
+			"deadline":          deadline,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     body(100, deadline, sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+					Owner:    db.EscrowOwner,
+					Currency: util.USD,
+				})).Times(1).Return(escrowAccount, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+
+				arg := db.CreateEscrowTxParams{
+					Buyer:           buyerAccount.Owner,
+					BuyerAccountID:  buyerAccount.ID,
+					SellerAccountID: sellerAccount.ID,
+					Amount:          100,
+					Currency:        util.USD,
+					Condition:       "Ship the bike",
+					Deadline:        deadline,
+					Screening:       allowedScreening(),
+				}
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.EscrowTxResult{Agreement: db.EscrowAgreement{ID: 1, Status: db.EscrowFunded}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "DeadlineTooFar",
+			body:     body(100, time.Now().Add(48*time.Hour+time.Hour), sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "DeadlinePassed",
+			body:     body(100, time.Now().Add(-time.Minute), sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "SellerIsEscrowAccount",
+			body:     body(100, deadline, escrowAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(escrowAccount.ID)).Times(1).Return(escrowAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotHolder",
+			body:     body(100, deadline, sellerAccount.ID),
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NeedsApproval",
+			body:     body(testApprovalThreshold+1, deadline, sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Beneficiary{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(1).Return(escrowAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "SellerCoolingOff",
+			body:     body(testBeneficiaryLargeAmount+1, deadline, sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				beneficiary := db.Beneficiary{Owner: buyerAccount.Owner, AccountID: sellerAccount.ID, CoolingOffUntil: time.Now().Add(time.Hour)}
+				store.EXPECT().
+					GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(db.GetBeneficiaryByAccountParams{Owner: buyerAccount.Owner, AccountID: sellerAccount.ID})).
+					Times(1).
+					Return(beneficiary, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			body:     body(100, deadline, sellerAccount.ID),
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(1).Return(escrowAccount, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.EscrowMaxDuration = 48*time.Hour + time.Minute
+			server.config.TransferApprovalThreshold = testApprovalThreshold
+			server.config.BeneficiaryCoolingOff = 24 * time.Hour
+			server.config.BeneficiaryLargeAmount = testBeneficiaryLargeAmount
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_SettleEscrow(t *testing.T) {
+	buyerAccount := randomAccount()
+	sellerAccount := randomAccount()
+	agreement := db.EscrowAgreement{
+		ID:              util.RandomInt(1, 1000),
+		Buyer:           buyerAccount.Owner,
+		BuyerAccountID:  buyerAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Amount:          100,
+		Status:          db.EscrowFunded,
+	}
+
+	loadEscrow := func(store *mockdb.MockStore) {
+		store.EXPECT().GetEscrowAgreement(gomock.Any(), gomock.Eq(agreement.ID)).Times(1).Return(agreement, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "BuyerReleases",
+			action:   "release",
+			username: buyerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				arg := db.SettleEscrowTxParams{AgreementID: agreement.ID, Actor: buyerAccount.Owner}
+				store.EXPECT().ReleaseEscrowTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.EscrowTxResult{Agreement: db.EscrowAgreement{Status: db.EscrowReleased}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.EscrowTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, db.EscrowReleased, result.Agreement.Status)
+			},
+		},
+		{
+			name:     "SellerReleases",
+			action:   "release",
+			username: sellerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().ReleaseEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "SellerRefunds",
+			action:   "refund",
+			username: sellerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				arg := db.SettleEscrowTxParams{AgreementID: agreement.ID, Actor: sellerAccount.Owner}
+				store.EXPECT().RefundEscrowTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.EscrowTxResult{Agreement: db.EscrowAgreement{Status: db.EscrowRefunded}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Disputed",
+			action:   "release",
+			username: buyerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().ReleaseEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowDisputed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "BankerResolvesDispute",
+			action:   "refund",
+			username: util.RandomOwner(),
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RefundEscrowTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.SettleEscrowTxParams) (db.EscrowTxResult, error) {
+						require.True(t, arg.ResolveDispute)
+						return db.EscrowTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadySettled",
+			action:   "refund",
+			username: sellerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().RefundEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowSettled)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			action:   "release",
+			username: buyerAccount.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetEscrowAgreement(gomock.Any(), gomock.Eq(agreement.ID)).Times(1).Return(db.EscrowAgreement{}, sql.ErrNoRows)
+				store.EXPECT().ReleaseEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/escrows/%d/%s", agreement.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_DisputeEscrow(t *testing.T) {
+	buyerAccount := randomAccount()
+	sellerAccount := randomAccount()
+	agreement := db.EscrowAgreement{
+		ID:              util.RandomInt(1, 1000),
+		BuyerAccountID:  buyerAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Status:          db.EscrowFunded,
+	}
+
+	loadEscrow := func(store *mockdb.MockStore) {
+		store.EXPECT().GetEscrowAgreement(gomock.Any(), gomock.Eq(agreement.ID)).Times(1).Return(agreement, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     gin.H{"reason": "The bike never arrived"},
+			username: sellerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				// the seller doesn't hold the buyer's account
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+
+				arg := db.DisputeEscrowTxParams{
+					AgreementID: agreement.ID,
+					DisputedBy:  sellerAccount.Owner,
+					Reason:      "The bike never arrived",
+				}
+				store.EXPECT().DisputeEscrowTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.EscrowTxResult{Agreement: db.EscrowAgreement{Status: db.EscrowDisputed}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NoReason",
+			body:     gin.H{},
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DisputeEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Stranger",
+			body:     gin.H{"reason": "Because"},
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(2).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().DisputeEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "DeadlinePassed",
+			body:     gin.H{"reason": "Too late"},
+			username: buyerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				loadEscrow(store)
+				store.EXPECT().DisputeEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowDeadlinePassed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/escrows/%d/dispute", agreement.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
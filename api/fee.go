@@ -0,0 +1,107 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"net/http"
+)
+
+type quoteFeeRequest struct {
+	Amount   int64  `form:"amount" binding:"required,gt=0"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+type quoteFeeResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Fee      db.Fee `json:"fee"`
+	// TotalDebit is what leaves the sender's account: the amount plus the fee
+	TotalDebit int64 `json:"total_debit"`
+}
+
+// quoteFee previews the fee a transfer would be charged without moving any money
+func (server *Server) quoteFee(ctx *gin.Context) {
+	var req quoteFeeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fee, err := server.store.QuoteFee(ctx, req.Currency, req.Amount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quoteFeeResponse{
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Fee:        fee,
+		TotalDebit: req.Amount + fee.Total,
+	})
+}
+
+func (server *Server) listFeeRules(ctx *gin.Context) {
+	rules, err := server.store.ListFeeRules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+type upsertFeeRuleRequest struct {
+	Currency      string `json:"currency" binding:"required,currency"`
+	MinAmount     int64  `json:"min_amount" binding:"min=0"`
+	FlatFee       int64  `json:"flat_fee" binding:"min=0"`
+	PercentageBps int64  `json:"percentage_bps" binding:"min=0,max=10000"`
+}
+
+// upsertFeeRule sets the fee tier of a currency starting at min_amount, replacing the existing one
+func (server *Server) upsertFeeRule(ctx *gin.Context) {
+	var req upsertFeeRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rule, err := server.store.UpsertFeeRule(ctx, db.UpsertFeeRuleParams{
+		Currency:      req.Currency,
+		MinAmount:     req.MinAmount,
+		FlatFee:       req.FlatFee,
+		PercentageBps: req.PercentageBps,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+type feeRuleUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deleteFeeRule removes a fee tier. Rules already charged on transfers can't be deleted, only overwritten.
+func (server *Server) deleteFeeRule(ctx *gin.Context) {
+	var req feeRuleUri
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteFeeRule(ctx, req.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
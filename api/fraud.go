@@ -0,0 +1,268 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/fraud"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var errTransferDenied = errors.New("transfer denied by fraud screening")
+
+// reviewMode tells what becomes of a transfer the fraud rules send to review
+type reviewMode int
+
+const (
+	// reviewQueue records the review for a banker, approving it performs the transfer
+	reviewQueue reviewMode = iota
+	// reviewDeny records the review as a denial
+	reviewDeny
+	// reviewApproval lets the transfer through with its assessment, for the approver to see
+	reviewApproval
+)
+
+// screenTransfer runs the fraud rules over a transfer from the account. An allowed transfer carries its assessment
+// so it's recorded along with it, any other decision is recorded on its own and returned.
+func (server *Server) screenTransfer(ctx *gin.Context, from db.Account, arg *db.TransferTxParams) (*db.FraudDecision, error) {
+	return server.screen(ctx, from, arg, reviewQueue)
+}
+
+// screenTransferNow is screenTransfer for the transfers that can't wait in the review queue,
+// since approving the review would perform the transfer on its own. A review is recorded as a denial.
+func (server *Server) screenTransferNow(ctx *gin.Context, from db.Account, arg *db.TransferTxParams) (*db.FraudDecision, error) {
+	return server.screen(ctx, from, arg, reviewDeny)
+}
+
+// screenTransferForApproval is screenTransfer for the transfers waiting for a second person's approval.
+// A review goes along with the transfer to its approver instead of the review queue, a denial stays one.
+func (server *Server) screenTransferForApproval(ctx *gin.Context, from db.Account, arg *db.TransferTxParams) (*db.FraudDecision, error) {
+	return server.screen(ctx, from, arg, reviewApproval)
+}
+
+func (server *Server) screen(ctx *gin.Context, from db.Account, arg *db.TransferTxParams, mode reviewMode) (*db.FraudDecision, error) {
+	assessment, err := server.screener.Screen(ctx, from, *arg)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Decision == db.FraudDecisionAllow ||
+		mode == reviewApproval && assessment.Decision == db.FraudDecisionReview {
+		arg.Screening = &assessment
+		return nil, nil
+	}
+	if mode == reviewDeny && assessment.Decision == db.FraudDecisionReview {
+		assessment.Decision = db.FraudDecisionDeny
+	}
+
+	decision, err := server.store.RecordFraudDecision(ctx, *arg, assessment)
+	if err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// writeFraudDecision answers a transfer that wasn't allowed: a review is accepted for later, a denial is forbidden
+func writeFraudDecision(ctx *gin.Context, decision db.FraudDecision) {
+	if decision.Decision == db.FraudDecisionReview {
+		ctx.JSON(http.StatusAccepted, gin.H{"fraud_decision": decision})
+		return
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": errTransferDenied.Error(), "fraud_decision": decision})
+}
+
+// fraudDecisionError explains why a batch item wasn't performed
+func fraudDecisionError(decision db.FraudDecision) error {
+	return fmt.Errorf("%w as decision %d", errTransferDenied, decision.ID)
+}
+
+type listFraudDecisionsRequest struct {
+	Decision     string `form:"decision" binding:"omitempty,oneof=allow review deny"`
+	ReviewStatus string `form:"review_status" binding:"omitempty,oneof=pending approved rejected"`
+	Page         int32  `form:"page" binding:"required,min=1"`
+	Size         int32  `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listFraudDecisions lists the screening decisions newest first, the review queue is review_status=pending
+func (server *Server) listFraudDecisions(ctx *gin.Context) {
+	var req listFraudDecisionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decisions, err := server.store.ListFraudDecisions(ctx, db.ListFraudDecisionsParams{
+		Decision:     sql.NullString{String: req.Decision, Valid: req.Decision != ""},
+		ReviewStatus: sql.NullString{String: req.ReviewStatus, Valid: req.ReviewStatus != ""},
+		Limit:        req.Size,
+		Offset:       (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decisions)
+}
+
+type fraudDecisionUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getFraudDecision(ctx *gin.Context) {
+	var uri fraudDecisionUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decision, err := server.store.GetFraudDecision(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decision)
+}
+
+func (server *Server) approveFraudDecision(ctx *gin.Context) {
+	server.reviewFraudDecision(ctx, true)
+}
+
+func (server *Server) rejectFraudDecision(ctx *gin.Context) {
+	server.reviewFraudDecision(ctx, false)
+}
+
+// reviewFraudDecision completes a pending review, approving it performs the transfer
+func (server *Server) reviewFraudDecision(ctx *gin.Context, approve bool) {
+	var uri fraudDecisionUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ReviewFraudDecisionTx(ctx, db.ReviewFraudDecisionTxParams{
+		DecisionID: uri.ID,
+		Approve:    approve,
+		Reviewer:   authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrReviewNotPending):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			writeTransferError(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) listFraudRules(ctx *gin.Context) {
+	rules, err := server.store.ListFraudRules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// upsertFraudRuleRequest defines a rule at runtime, the window is a duration such as 10m or 24h.
+// A rule named like one of the rules file replaces it, and a disabled one turns it off.
+type upsertFraudRuleRequest struct {
+	Name      string `json:"name" binding:"required,max=64"`
+	Kind      string `json:"kind" binding:"required"`
+	Action    string `json:"action" binding:"required,oneof=review deny"`
+	Score     int64  `json:"score" binding:"min=0"`
+	Currency  string `json:"currency" binding:"omitempty,currency"`
+	MinAmount int64  `json:"min_amount" binding:"min=0"`
+	MinCount  int64  `json:"min_count" binding:"min=0"`
+	Window    string `json:"window"`
+	RoundTo   int64  `json:"round_to" binding:"min=0"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+// upsertFraudRule sets a rule, replacing the stored one of the same name
+func (server *Server) upsertFraudRule(ctx *gin.Context) {
+	var req upsertFraudRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var window time.Duration
+	if req.Window != "" {
+		var err error
+		if window, err = time.ParseDuration(req.Window); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	arg := db.UpsertFraudRuleParams{
+		Name:          req.Name,
+		Kind:          req.Kind,
+		Action:        req.Action,
+		Score:         req.Score,
+		Currency:      req.Currency,
+		MinAmount:     req.MinAmount,
+		MinCount:      req.MinCount,
+		WindowSeconds: int64(window / time.Second),
+		RoundTo:       req.RoundTo,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+	rule := fraud.RuleFromDB(db.FraudRule{
+		Name:          arg.Name,
+		Kind:          arg.Kind,
+		Action:        arg.Action,
+		Score:         arg.Score,
+		Currency:      arg.Currency,
+		MinAmount:     arg.MinAmount,
+		MinCount:      arg.MinCount,
+		WindowSeconds: arg.WindowSeconds,
+		RoundTo:       arg.RoundTo,
+	})
+	if err := rule.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	stored, err := server.store.UpsertFraudRule(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stored)
+}
+
+type fraudRuleUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteFraudRule(ctx *gin.Context) {
+	var req fraudRuleUri
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteFraudRule(ctx, req.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,384 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// allowedScreening is what a transfer carries when no fraud rule matched it
+func allowedScreening() *db.FraudAssessment {
+	return &db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}}
+}
+
+func TestApi_CreateTransferScreening(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          5000,
+		"currency":        util.USD,
+	}
+	largeAmount := func(action string) db.FraudRule {
+		return db.FraudRule{Name: "large", Kind: "large_amount", Action: action, Score: 50, MinAmount: 1000, Enabled: true}
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Review",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{largeAmount(db.FraudDecisionReview)}, nil)
+
+				assessment := db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 50, MatchedRules: []string{"large"}}
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Eq(assessment)).Times(1).
+					Return(db.FraudDecision{ID: 7, Decision: db.FraudDecisionReview}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var response struct {
+					FraudDecision db.FraudDecision `json:"fraud_decision"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, int64(7), response.FraudDecision.ID)
+			},
+		},
+		{
+			name: "Deny",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{largeAmount(db.FraudDecisionDeny)}, nil)
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(db.FraudDecision{ID: 8, Decision: db.FraudDecisionDeny}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "DisabledRule",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				rule := largeAmount(db.FraudDecisionDeny)
+				rule.Enabled = false
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{rule}, nil)
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        5000,
+					Currency:      util.USD,
+					Actor:         account1.Owner,
+					Screening:     allowedScreening(),
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ListRulesError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_ReviewFraudDecision(t *testing.T) {
+	decisionID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		action        string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			action: "approve",
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ReviewFraudDecisionTxParams) (db.ReviewFraudDecisionTxResult, error) {
+						require.Equal(t, decisionID, arg.DecisionID)
+						require.True(t, arg.Approve)
+						require.NotEmpty(t, arg.Reviewer)
+						return db.ReviewFraudDecisionTxResult{Transfer: &db.TransferTxResult{}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Reject",
+			action: "reject",
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ReviewFraudDecisionTxParams) (db.ReviewFraudDecisionTxResult, error) {
+						require.False(t, arg.Approve)
+						return db.ReviewFraudDecisionTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotPending",
+			action: "approve",
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrReviewNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "reject",
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "AccountFrozen",
+			action: "approve",
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotBanker",
+			action: "approve",
+			role:   util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/fraud-decisions/%d/%s", decisionID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_ListFraudDecisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListFraudDecisionsParams{
+		ReviewStatus: sql.NullString{String: db.FraudReviewPending, Valid: true},
+		Limit:        5,
+		Offset:       5,
+	}
+	decisions := []db.FraudDecision{{ID: 1, Decision: db.FraudDecisionReview}}
+	store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(decisions, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/fraud-decisions?review_status=pending&page=2&size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []db.FraudDecision
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Len(t, response, 1)
+}
+
+func TestApi_UpsertFraudRule(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "bursts", "kind": "rapid_succession", "action": "deny", "score": 80, "min_count": 5, "window": "10m"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertFraudRuleParams{
+					Name:          "bursts",
+					Kind:          "rapid_succession",
+					Action:        db.FraudDecisionDeny,
+					Score:         80,
+					MinCount:      5,
+					WindowSeconds: 600,
+					Enabled:       true,
+				}
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.FraudRule{ID: 1}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Disable",
+			body: gin.H{"name": "large_transfer", "kind": "large_amount", "action": "review", "enabled": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertFraudRuleParams{Name: "large_transfer", Kind: "large_amount", Action: db.FraudDecisionReview}
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.FraudRule{ID: 2}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingWindow",
+			body: gin.H{"name": "bursts", "kind": "rapid_succession", "action": "review", "min_count": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidWindow",
+			body: gin.H{"name": "bursts", "kind": "rapid_succession", "action": "review", "min_count": 5, "window": "soon"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownKind",
+			body: gin.H{"name": "odd", "kind": "odd_hours", "action": "review"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/fraud-rules", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type accountHistoryUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// accountHistoryRequest holds the filters shared by the entry and transfer history of an account.
+// Every filter is optional, amounts are compared in absolute value.
+// Without a page number the history is paginated with cursors.
+type accountHistoryRequest struct {
+	Page                  int32      `form:"page" binding:"omitempty,min=1"`
+	Size                  int32      `form:"size" binding:"required,min=5,max=20"`
+	Cursor                string     `form:"cursor"`
+	StartTime             *time.Time `form:"start_time"`
+	EndTime               *time.Time `form:"end_time"`
+	Direction             string     `form:"direction" binding:"omitempty,oneof=in out"`
+	MinAmount             *int64     `form:"min_amount" binding:"omitempty,min=0"`
+	MaxAmount             *int64     `form:"max_amount" binding:"omitempty,min=0"`
+	CounterpartyAccountID *int64     `form:"counterparty_account_id" binding:"omitempty,min=1"`
+}
+
+// accountHistoryQuery is a validated history request turned into query arguments
+type accountHistoryQuery struct {
+	AccountID             int64
+	StartTime             sql.NullTime
+	EndTime               sql.NullTime
+	Direction             sql.NullString
+	MinAmount             sql.NullInt64
+	MaxAmount             sql.NullInt64
+	CounterpartyAccountID sql.NullInt64
+	CursorCreatedAt       sql.NullTime
+	CursorID              sql.NullInt64
+	Limit                 int32
+	Offset                int32
+	// Keyset pages fetch one extra row to know whether there is a next page
+	Keyset bool
+	Size   int32
+}
+
+// bindAccountHistory parses the history request of an account the caller owns
+func (server *Server) bindAccountHistory(ctx *gin.Context) (accountHistoryQuery, bool) {
+	var query accountHistoryQuery
+	var uri accountHistoryUri
+	var req accountHistoryRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return query, false
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return query, false
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.StartTime.Before(*req.EndTime) {
+		err := errors.New("start_time must be before end_time")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return query, false
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		err := errors.New("min_amount must not be above max_amount")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return query, false
+	}
+
+	keyset, ok := keysetMode(ctx, req.Page, req.Cursor)
+	if !ok {
+		return query, false
+	}
+	cursor, err := server.decodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return query, false
+	}
+
+	if _, ok := server.loadHeldAccount(ctx, uri.ID, accessView); !ok {
+		return query, false
+	}
+
+	query.AccountID = uri.ID
+	query.Keyset = keyset
+	query.Size = req.Size
+	if keyset {
+		query.CursorCreatedAt = cursor.createdAt()
+		query.CursorID = cursor.id()
+		query.Limit = req.Size + 1
+	} else {
+		query.Limit = req.Size
+		query.Offset = (req.Page - 1) * req.Size
+	}
+
+	if req.StartTime != nil {
+		query.StartTime = sql.NullTime{Time: *req.StartTime, Valid: true}
+	}
+	if req.EndTime != nil {
+		query.EndTime = sql.NullTime{Time: *req.EndTime, Valid: true}
+	}
+	if req.Direction != "" {
+		query.Direction = sql.NullString{String: req.Direction, Valid: true}
+	}
+	if req.MinAmount != nil {
+		query.MinAmount = sql.NullInt64{Int64: *req.MinAmount, Valid: true}
+	}
+	if req.MaxAmount != nil {
+		query.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+	if req.CounterpartyAccountID != nil {
+		query.CounterpartyAccountID = sql.NullInt64{Int64: *req.CounterpartyAccountID, Valid: true}
+	}
+	return query, true
+}
+
+// listAccountEntries returns the entries of an account, newest first, with the balance after each one
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	query, ok := server.bindAccountHistory(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := server.store.ListAccountEntries(ctx, db.ListAccountEntriesParams{
+		AccountID:             query.AccountID,
+		StartTime:             query.StartTime,
+		EndTime:               query.EndTime,
+		Direction:             query.Direction,
+		MinAmount:             query.MinAmount,
+		MaxAmount:             query.MaxAmount,
+		CounterpartyAccountID: query.CounterpartyAccountID,
+		CursorCreatedAt:       query.CursorCreatedAt,
+		CursorID:              query.CursorID,
+		Limit:                 query.Limit,
+		Offset:                query.Offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !query.Keyset {
+		ctx.JSON(http.StatusOK, entries)
+		return
+	}
+
+	var next *pageCursor
+	if len(entries) > int(query.Size) {
+		entries = entries[:query.Size]
+		last := entries[len(entries)-1]
+		next = &pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	server.writeCursorPage(ctx, entries, next)
+}
+
+// listAccountTransfers returns the transfers sent or received by an account, newest first,
+// with the balance of the account after each one
+func (server *Server) listAccountTransfers(ctx *gin.Context) {
+	query, ok := server.bindAccountHistory(ctx)
+	if !ok {
+		return
+	}
+
+	transfers, err := server.store.ListAccountTransfers(ctx, db.ListAccountTransfersParams{
+		AccountID:             query.AccountID,
+		StartTime:             query.StartTime,
+		EndTime:               query.EndTime,
+		Direction:             query.Direction,
+		MinAmount:             query.MinAmount,
+		MaxAmount:             query.MaxAmount,
+		CounterpartyAccountID: query.CounterpartyAccountID,
+		CursorCreatedAt:       query.CursorCreatedAt,
+		CursorID:              query.CursorID,
+		Limit:                 query.Limit,
+		Offset:                query.Offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !query.Keyset {
+		ctx.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	var next *pageCursor
+	if len(transfers) > int(query.Size) {
+		transfers = transfers[:query.Size]
+		last := transfers[len(transfers)-1]
+		next = &pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	server.writeCursorPage(ctx, transfers, next)
+}
+
+type accountBalanceRequest struct {
+	AsOf time.Time `form:"as_of" binding:"required"`
+}
+
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	AsOf      time.Time `json:"as_of"`
+	Balance   int64     `json:"balance"`
+}
+
+// getAccountBalance returns the balance an account had at a past instant, computed from its entries
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri accountHistoryUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req accountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, uri.ID, accessView)
+	if !ok {
+		return
+	}
+	if req.AsOf.Before(account.CreatedAt) {
+		err := errors.New("as_of is before the account was created")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	balance, err := server.store.GetBalanceAsOf(ctx, account.ID, req.AsOf)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		AsOf:      req.AsOf,
+		Balance:   balance,
+	})
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type createHoldRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// createHold reserves funds on the caller's account for a later capture by the recipient.
+// The hold is screened like the transfer capturing it, and can't be above the approval threshold.
+func (server *Server) createHold(ctx *gin.Context) {
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.requireHolder(ctx, fromAccount, accessTransact, "from account doesn't belong to the authenticated user") {
+		return
+	}
+
+	if _, valid := server.validateAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+	if status, err := server.checkRecipientCoolingOff(ctx, req.ToAccountID, req.Amount); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	capture := db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}
+	if server.needsApproval(capture) {
+		err := fmt.Errorf("holds above %d need approval and can't be captured", server.config.TransferApprovalThreshold)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	// approving a fraud review would perform the transfer at once, a hold waits for its capture instead
+	decision, err := server.screenTransferNow(ctx, fromAccount, &capture)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if decision != nil {
+		writeFraudDecision(ctx, *decision)
+		return
+	}
+
+	arg := db.AuthorizeHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ExpiresAt:     time.Now().Add(server.config.HoldDuration),
+		Screening:     capture.Screening,
+	}
+
+	result, err := server.store.AuthorizeHoldTx(ctx, arg)
+	if err != nil {
+		writeHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type holdUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	hold, fromAccount, toAccount, ok := server.loadHold(ctx)
+	if !ok {
+		return
+	}
+
+	if !server.authorizeHoldParty(ctx, fromAccount, toAccount, accessView) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type captureHoldRequest struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// captureHold settles a hold, fully when no amount is given. Only the recipient or a banker may do it.
+// The recipient picks when the sender is debited, so the capture is screened again against the sender's history by then.
+func (server *Server) captureHold(ctx *gin.Context) {
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, fromAccount, toAccount, ok := server.loadHold(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role != util.BankerRole &&
+		!server.requireHolder(ctx, toAccount, accessTransact, "only a banker or the recipient can capture a hold") {
+		return
+	}
+
+	capture := db.TransferTxParams{
+		FromAccountID: hold.FromAccountID,
+		ToAccountID:   hold.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	}
+	if capture.Amount == 0 {
+		capture.Amount = hold.Amount
+	}
+	decision, err := server.screenTransferNow(ctx, fromAccount, &capture)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if decision != nil {
+		writeFraudDecision(ctx, *decision)
+		return
+	}
+
+	arg := db.CaptureHoldTxParams{
+		HoldID:    hold.ID,
+		Amount:    req.Amount,
+		Screening: capture.Screening,
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, arg)
+	if err != nil {
+		writeHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) voidHold(ctx *gin.Context) {
+	hold, fromAccount, toAccount, ok := server.loadHold(ctx)
+	if !ok {
+		return
+	}
+
+	if !server.authorizeHoldParty(ctx, fromAccount, toAccount, accessTransact) {
+		return
+	}
+
+	result, err := server.store.VoidHoldTx(ctx, hold.ID)
+	if err != nil {
+		writeHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// loadHold fetches the hold in the uri along with both of its accounts
+func (server *Server) loadHold(ctx *gin.Context) (hold db.Hold, fromAccount, toAccount db.Account, ok bool) {
+	var uri holdUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, err := server.store.GetHold(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	fromAccount, err = server.store.GetAccount(ctx, hold.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err = server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	return hold, fromAccount, toAccount, true
+}
+
+// authorizeHoldParty lets bankers and the holders of either side of a hold with the access level through
+func (server *Server) authorizeHoldParty(ctx *gin.Context, fromAccount, toAccount db.Account, access int) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role == util.BankerRole {
+		return true
+	}
+
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		held, err := server.holdsAccount(ctx, account, authPayload.Username, access)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+		if held {
+			return true
+		}
+	}
+
+	err := errors.New("hold doesn't belong to the authenticated user")
+	ctx.JSON(http.StatusForbidden, errorResponse(err))
+	return false
+}
+
+func writeHoldError(ctx *gin.Context, err error) {
+	var limitErr *db.LimitExceededError
+	switch {
+	case errors.As(err, &limitErr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "limit": limitErr.Usage})
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrHoldNotPending),
+		errors.Is(err, db.ErrHoldExpired),
+		errors.Is(err, db.ErrCaptureExceedsHold),
+		errors.Is(err, db.ErrApprovalHold),
+		errors.Is(err, db.ErrAccountFrozen),
+		errors.Is(err, db.ErrAccountClosed):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (server *Server) listInterestRates(ctx *gin.Context) {
+	rates, err := server.store.ListInterestRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+type upsertInterestRateRequest struct {
+	Currency      string `json:"currency" binding:"required,currency"`
+	AccountType   string `json:"account_type" binding:"required,oneof=checking savings"`
+	AnnualRateBps int64  `json:"annual_rate_bps" binding:"min=0,max=10000"`
+}
+
+// upsertInterestRate sets the annual rate of an account type in a currency, from the next accrual on
+func (server *Server) upsertInterestRate(ctx *gin.Context) {
+	var req upsertInterestRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, err := server.store.UpsertInterestRate(ctx, db.UpsertInterestRateParams{
+		Currency:      req.Currency,
+		AccountType:   req.AccountType,
+		AnnualRateBps: req.AnnualRateBps,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
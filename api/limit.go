@@ -0,0 +1,145 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type getLimitsRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+}
+
+type getLimitsResponse struct {
+	AccountID int64           `json:"account_id"`
+	Currency  string          `json:"currency"`
+	Usage     []db.LimitUsage `json:"usage"`
+}
+
+// getLimits shows how much of its transfer limits an account and the caller used in the current day, week and month
+func (server *Server) getLimits(ctx *gin.Context) {
+	var req getLimitsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, req.AccountID, accessView)
+	if !ok {
+		return
+	}
+
+	// bankers see the usage of the owner
+	var username string
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); authPayload.Role != util.BankerRole {
+		username = authPayload.Username
+	}
+	usage, err := server.store.TransferLimitUsage(ctx, account, username, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getLimitsResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		Usage:     usage,
+	})
+}
+
+func (server *Server) listTransferLimits(ctx *gin.Context) {
+	limits, err := server.store.ListTransferLimits(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limits)
+}
+
+// upsertTransferLimitRequest sets the maximum amount, count or both of a period.
+// The subject is the account id or the username, and is left out for currency limits.
+type upsertTransferLimitRequest struct {
+	Scope     string `json:"scope" binding:"required,oneof=currency user account"`
+	Subject   string `json:"subject" binding:"max=64"`
+	Currency  string `json:"currency" binding:"required,currency"`
+	Period    string `json:"period" binding:"required,oneof=day week month"`
+	MaxAmount *int64 `json:"max_amount" binding:"omitempty,min=0"`
+	MaxCount  *int64 `json:"max_count" binding:"omitempty,min=0"`
+}
+
+// upsertTransferLimit sets a limit, replacing the one of the same scope, subject, currency and period
+func (server *Server) upsertTransferLimit(ctx *gin.Context) {
+	var req upsertTransferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.MaxAmount == nil && req.MaxCount == nil {
+		err := errors.New("max_amount or max_count is required")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if (req.Scope == db.LimitScopeCurrency) != (req.Subject == "") {
+		err := errors.New("subject is required for user and account limits, and only for them")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Scope == db.LimitScopeAccount {
+		accountID, err := strconv.ParseInt(req.Subject, 10, 64)
+		if err != nil || accountID < 1 {
+			err := errors.New("subject of an account limit must be an account id")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if _, valid := server.validateAccount(ctx, accountID, req.Currency); !valid {
+			return
+		}
+	}
+
+	arg := db.UpsertTransferLimitParams{
+		Scope:    req.Scope,
+		Subject:  req.Subject,
+		Currency: req.Currency,
+		Period:   req.Period,
+	}
+	if req.MaxAmount != nil {
+		arg.MaxAmount.Int64, arg.MaxAmount.Valid = *req.MaxAmount, true
+	}
+	if req.MaxCount != nil {
+		arg.MaxCount.Int64, arg.MaxCount.Valid = *req.MaxCount, true
+	}
+
+	limit, err := server.store.UpsertTransferLimit(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+type transferLimitUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteTransferLimit(ctx *gin.Context) {
+	var req transferLimitUri
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteTransferLimit(ctx, req.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -14,6 +14,7 @@ func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
 		TokenKey:      util.RandomString(32),
 		TokenDuration: time.Minute,
+		HoldDuration:  time.Hour,
 	}
 	server, err := NewServer(store, config)
 	require.NoError(t, err)
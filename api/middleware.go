@@ -0,0 +1,125 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	// hostTenantKey holds the tenant served on the host of the request, when it is one of theirs
+	hostTenantKey = "host_tenant_id"
+)
+
+var errTenantMismatch = errors.New("token belongs to another tenant than the host")
+
+// tenantMiddleware scopes the request to the tenant served on its host, store calls made with the
+// request context only see that tenant's rows. Other hosts, and every host when the lookup is turned off,
+// are scoped to the default tenant until authMiddleware scopes them to the tenant of the token.
+func tenantMiddleware(store db.Store, byHost bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID := db.DefaultTenantID
+		if host := requestHost(ctx.Request); byHost && host != "" {
+			tenant, err := store.GetTenantByHost(ctx, sql.NullString{String: host, Valid: true})
+			switch {
+			case err == nil:
+				tenantID = tenant.ID
+				ctx.Set(hostTenantKey, tenant.ID)
+			case err != sql.ErrNoRows:
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.Set(db.TenantKey, tenantID)
+		ctx.Next()
+	}
+}
+
+// requestHost returns the host name of the request, lower cased and without the port
+func requestHost(request *http.Request) string {
+	host := request.Host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	return strings.ToLower(host)
+}
+
+// platformMiddleware only lets through requests whose token belongs to the default tenant, which runs the platform.
+// It must run after authMiddleware.
+func platformMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if authPayload.TenantID != db.DefaultTenantID {
+			err := errors.New("this action is reserved to the platform")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// authMiddleware rejects requests without a valid bearer token and stores its payload in the context.
+// The request is scoped to the tenant of the token, which must be the one of the host when it serves one.
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if hostTenantID, ok := ctx.Get(hostTenantKey); ok && hostTenantID.(int64) != payload.TenantID {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errTenantMismatch))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(db.TenantKey, payload.TenantID)
+		ctx.Next()
+	}
+}
+
+// roleMiddleware only lets through requests whose token carries the given role.
+// It must run after authMiddleware.
+func roleMiddleware(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if authPayload.Role != role {
+			err := fmt.Errorf("this action requires the %s role", role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
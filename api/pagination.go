@@ -0,0 +1,119 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the position of the last row of a keyset page.
+// Rows keyed by a username instead of a numeric id set Username.
+type pageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+}
+
+func (cursor *pageCursor) createdAt() sql.NullTime {
+	if cursor == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+}
+
+func (cursor *pageCursor) id() sql.NullInt64 {
+	if cursor == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: cursor.ID, Valid: true}
+}
+
+func (cursor *pageCursor) username() sql.NullString {
+	if cursor == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: cursor.Username, Valid: true}
+}
+
+// encodeCursor turns a cursor into an opaque token signed with the server key,
+// so clients can't forge positions they were never handed.
+func (server *Server) encodeCursor(cursor pageCursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(server.signCursor(encoded))
+}
+
+// decodeCursor verifies a token made by encodeCursor. An empty token means the first page and gives nil.
+func (server *Server) decodeCursor(token string) (*pageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(token, ".")
+	if len(fields) != 2 {
+		return nil, errInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(fields[1])
+	if err != nil || !hmac.Equal(signature, server.signCursor(fields[0])) {
+		return nil, errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(fields[0])
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func (server *Server) signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, []byte(server.config.TokenKey))
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// cursorPageResponse is returned by list endpoints in keyset mode
+type cursorPageResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// keysetMode tells whether a list request uses cursors, which is the case unless a page number is given.
+// Asking for both is rejected.
+func keysetMode(ctx *gin.Context, page int32, cursor string) (bool, bool) {
+	if page > 0 && cursor != "" {
+		err := errors.New("page and cursor can't be used together")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false, false
+	}
+	return page == 0, true
+}
+
+// writeCursorPage responds with a keyset page. When there is a next page, its cursor is also
+// advertised in an RFC 5988 Link header pointing at the same request with the new cursor.
+func (server *Server) writeCursorPage(ctx *gin.Context, items interface{}, next *pageCursor) {
+	response := cursorPageResponse{Items: items}
+	if next != nil {
+		response.NextCursor = server.encodeCursor(*next)
+
+		query := ctx.Request.URL.Query()
+		query.Set("cursor", response.NextCursor)
+		ctx.Header("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, ctx.Request.URL.Path, query.Encode()))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
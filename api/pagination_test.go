@@ -0,0 +1,32 @@
+package api
+
+import (
+	"code-with-go/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestApi_PageCursor(t *testing.T) {
+	server := NewTestServer(t, nil)
+	cursor := pageCursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: util.RandomInt(1, 1000)}
+
+	token := server.encodeCursor(cursor)
+	decoded, err := server.decodeCursor(token)
+	require.NoError(t, err)
+	require.Equal(t, cursor, *decoded)
+
+	decoded, err = server.decodeCursor("")
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	// a cursor signed by another server is rejected
+	other := NewTestServer(t, nil)
+	_, err = other.decodeCursor(token)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	for _, invalid := range []string{"abc", "abc.def", token + "." + token, "!!." + token} {
+		_, err = server.decodeCursor(invalid)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var (
+	errPayeeRequired  = errors.New("exactly one of a username, a verified email or an alias must be given")
+	errPayeeNotFound  = errors.New("payee not found")
+	errPayeeAmbiguous = errors.New("an account id can't be given along with a payee")
+)
+
+// payee is how a payer names the recipient instead of its account id
+type payee struct {
+	Username string
+	Email    string
+	Alias    string
+}
+
+func (p payee) given() int {
+	count := 0
+	for _, field := range []string{p.Username, p.Email, p.Alias} {
+		if field != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// resolvePayee finds the user named by exactly one of the payee fields, and the account payments to
+// them in the currency land on. On failure, it also returns the http status matching the error.
+func (server *Server) resolvePayee(ctx *gin.Context, p payee, currency string) (db.User, db.Account, int, error) {
+	if p.given() != 1 {
+		return db.User{}, db.Account{}, http.StatusBadRequest, errPayeeRequired
+	}
+
+	var user db.User
+	var err error
+	switch {
+	case p.Username != "":
+		user, err = server.store.GetUser(ctx, p.Username)
+	case p.Email != "":
+		// an unverified email could belong to anyone
+		user, err = server.store.GetUserByVerifiedEmail(ctx, p.Email)
+	default:
+		user, err = server.store.GetUserByAlias(ctx, p.Alias)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, db.Account{}, http.StatusNotFound, errPayeeNotFound
+		}
+		return user, db.Account{}, http.StatusInternalServerError, err
+	}
+
+	account, err := server.store.GetPayeeAccount(ctx, db.GetPayeeAccountParams{
+		Owner:    user.Username,
+		Currency: currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, account, http.StatusNotFound, fmt.Errorf("payee has no account in %s", currency)
+		}
+		return user, account, http.StatusInternalServerError, err
+	}
+	return user, account, http.StatusOK, nil
+}
+
+// getPayeeAccount fetches an account by id, or through its payee when no id is given
+func (server *Server) getPayeeAccount(ctx *gin.Context, accountID int64, p payee, currency string) (db.Account, int, error) {
+	if accountID == 0 {
+		_, account, status, err := server.resolvePayee(ctx, p, currency)
+		return account, status, err
+	}
+	if p.given() > 0 {
+		return db.Account{}, http.StatusBadRequest, errPayeeAmbiguous
+	}
+	return server.getAccountInCurrency(ctx, accountID, currency)
+}
+
+type previewPayeeRequest struct {
+	Username string `form:"username" binding:"omitempty,alphanum"`
+	Email    string `form:"email" binding:"omitempty,email"`
+	Alias    string `form:"alias" binding:"omitempty,alphanum"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+type previewPayeeResponse struct {
+	MaskedName string `json:"masked_name"`
+	Currency   string `json:"currency"`
+}
+
+// previewPayee confirms who a payment would go to before it is sent.
+// Only the masked name of the payee is shown, never their account.
+func (server *Server) previewPayee(ctx *gin.Context) {
+	var req previewPayeeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	p := payee{Username: req.Username, Email: req.Email, Alias: req.Alias}
+	user, account, status, err := server.resolvePayee(ctx, p, req.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, previewPayeeResponse{
+		MaskedName: util.MaskName(user.FullName),
+		Currency:   account.Currency,
+	})
+}
+
+type setAliasRequest struct {
+	Alias string `json:"alias" binding:"required,alphanum,min=3,max=30"`
+}
+
+// setAlias gives the authenticated user an alias to be paid by, replacing the one they had
+func (server *Server) setAlias(ctx *gin.Context) {
+	var req setAliasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.updateAlias(ctx, sql.NullString{String: req.Alias, Valid: true})
+}
+
+// removeAlias stops the authenticated user from being paid by alias
+func (server *Server) removeAlias(ctx *gin.Context) {
+	server.updateAlias(ctx, sql.NullString{})
+}
+
+func (server *Server) updateAlias(ctx *gin.Context, alias sql.NullString) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.SetUserAlias(ctx, db.SetUserAliasParams{
+		Alias:    alias,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case isUniqueViolation(err):
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New("alias is already taken")))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type verifyEmailUri struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// verifyEmail marks the email of a user as verified once a banker checked it, so they can be paid by email
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var uri verifyEmailUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.VerifyUserEmail(ctx, uri.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -0,0 +1,360 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/notify"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"net/http"
+	"time"
+)
+
+var errNotPayer = errors.New("the authenticated user isn't a payer of the payment request")
+
+type paymentRequestPayer struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	// Amount is left out of every payer to split the request evenly
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type createPaymentRequestRequest struct {
+	ToAccountID int64                 `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64                 `json:"amount" binding:"required,gt=0"`
+	Currency    string                `json:"currency" binding:"required,currency"`
+	Description string                `json:"description" binding:"max=255"`
+	Payers      []paymentRequestPayer `json:"payers" binding:"required,min=1,max=20,dive"`
+}
+
+// shares splits the amount of the request between its payers, evenly unless each payer has an amount
+func (req createPaymentRequestRequest) shares(requester string) ([]db.PaymentRequestShare, error) {
+	shares := make([]db.PaymentRequestShare, len(req.Payers))
+	seen := make(map[string]bool, len(req.Payers))
+	var withAmount, total int64
+	for i, payer := range req.Payers {
+		if payer.Username == requester {
+			return nil, errors.New("the requester can't be one of the payers")
+		}
+		if seen[payer.Username] {
+			return nil, fmt.Errorf("payer %s is given twice", payer.Username)
+		}
+		seen[payer.Username] = true
+
+		shares[i] = db.PaymentRequestShare{Payer: payer.Username, Amount: payer.Amount}
+		if payer.Amount > 0 {
+			withAmount++
+			total += payer.Amount
+		}
+	}
+
+	count := int64(len(shares))
+	switch withAmount {
+	case 0:
+		if req.Amount < count {
+			return nil, fmt.Errorf("%d can't be split between %d payers", req.Amount, count)
+		}
+		// the first payers cover the remainder
+		for i := range shares {
+			shares[i].Amount = req.Amount / count
+			if int64(i) < req.Amount%count {
+				shares[i].Amount++
+			}
+		}
+	case count:
+		if total != req.Amount {
+			return nil, fmt.Errorf("the amounts of the payers add up to %d instead of %d", total, req.Amount)
+		}
+	default:
+		return nil, errors.New("either every payer or none of them must have an amount")
+	}
+	return shares, nil
+}
+
+// paymentRequestResponse is a request along with the share of each of its payers
+type paymentRequestResponse struct {
+	db.PaymentRequest
+	Payers []db.PaymentRequestPayer `json:"payers"`
+}
+
+func newPaymentRequestResponse(result db.PaymentRequestTxResult) paymentRequestResponse {
+	return paymentRequestResponse{PaymentRequest: result.PaymentRequest, Payers: result.Payers}
+}
+
+// createPaymentRequest asks one or more users to pay into one of the authenticated user's accounts.
+// Each payer is notified and can accept or decline their share until the request expires.
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	shares, err := req.shares(authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	threshold := server.config.TransferApprovalThreshold
+	for _, share := range shares {
+		if threshold > 0 && share.Amount > threshold {
+			err := fmt.Errorf("shares above %d need approval and can't be requested", threshold)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	account, status, err := server.getAccountInCurrency(ctx, req.ToAccountID, req.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	if !server.requireHolder(ctx, account, accessTransact, "account doesn't belong to the authenticated user") {
+		return
+	}
+
+	result, err := server.store.CreatePaymentRequestTx(ctx, db.CreatePaymentRequestTxParams{
+		Requester:   authPayload.Username,
+		ToAccountID: account.ID,
+		Currency:    account.Currency,
+		Description: req.Description,
+		ExpiresAt:   time.Now().Add(server.config.PaymentRequestDuration),
+		Shares:      shares,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("payer not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payers := make([]string, len(shares))
+	for i, share := range shares {
+		payers[i] = share.Payer
+	}
+	notify.Send(ctx, server.notifier, notify.KindPaymentRequestCreated, result, payers...)
+
+	ctx.JSON(http.StatusOK, newPaymentRequestResponse(result))
+}
+
+// listPaymentRequestsRequest lists the incoming requests the caller is asked to pay, or the outgoing ones they made
+type listPaymentRequestsRequest struct {
+	Direction string `form:"direction" binding:"required,oneof=incoming outgoing"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending settled declined expired"`
+	Page      int32  `form:"page" binding:"required,min=1"`
+	Size      int32  `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listPaymentRequests lists the authenticated user's payment requests newest first
+func (server *Server) listPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	status := sql.NullString{String: req.Status, Valid: req.Status != ""}
+	var requests []db.PaymentRequest
+	var err error
+	if req.Direction == "incoming" {
+		requests, err = server.store.ListPaymentRequestsByPayer(ctx, db.ListPaymentRequestsByPayerParams{
+			Payer:  authPayload.Username,
+			Status: status,
+			Limit:  req.Size,
+			Offset: (req.Page - 1) * req.Size,
+		})
+	} else {
+		requests, err = server.store.ListPaymentRequestsByRequester(ctx, db.ListPaymentRequestsByRequesterParams{
+			Requester: authPayload.Username,
+			Status:    status,
+			Limit:     req.Size,
+			Offset:    (req.Page - 1) * req.Size,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+type paymentRequestUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadPaymentRequest fetches the payment request of the uri with its payers, for its requester or one of its payers.
+// It writes the error response itself and returns false on failure.
+func (server *Server) loadPaymentRequest(ctx *gin.Context) (paymentRequestResponse, bool) {
+	var response paymentRequestResponse
+	var uri paymentRequestUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return response, false
+	}
+
+	var err error
+	response.PaymentRequest, err = server.store.GetPaymentRequest(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return response, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return response, false
+	}
+
+	response.Payers, err = server.store.ListPaymentRequestPayers(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return response, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if response.Requester != authPayload.Username && response.payer(authPayload.Username) == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("payment request doesn't concern the authenticated user")))
+		return response, false
+	}
+	return response, true
+}
+
+// payer returns the share of the user, nil when they aren't a payer
+func (response paymentRequestResponse) payer(username string) *db.PaymentRequestPayer {
+	for i := range response.Payers {
+		if response.Payers[i].Payer == username {
+			return &response.Payers[i]
+		}
+	}
+	return nil
+}
+
+func (server *Server) getPaymentRequest(ctx *gin.Context) {
+	response, ok := server.loadPaymentRequest(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+// acceptPaymentRequest pays the authenticated user's share of a request from one of their accounts.
+// The transfer is screened like any other, but can't wait for a fraud review or an approval.
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	request, ok := server.loadPaymentRequest(ctx)
+	if !ok {
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	payer := request.payer(authPayload.Username)
+	if payer == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotPayer))
+		return
+	}
+	if request.Status != db.PaymentRequestPending || payer.Status != db.PaymentRequestPayerPending {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrPaymentRequestNotPending))
+		return
+	}
+
+	fromAccount, status, err := server.getAccountInCurrency(ctx, req.FromAccountID, request.Currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	if !server.requireHolder(ctx, fromAccount, accessTransact, "from account doesn't belong to the authenticated user") {
+		return
+	}
+
+	if status, err := server.checkRecipientCoolingOff(ctx, request.ToAccountID, payer.Amount); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	arg := db.PaymentRequestTransfer(request.PaymentRequest, *payer, fromAccount.ID)
+	if server.needsApproval(arg) {
+		err := fmt.Errorf("transfers above %d need approval and can't pay a request", server.config.TransferApprovalThreshold)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+	decision, err := server.screenTransferNow(ctx, fromAccount, &arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if decision != nil {
+		writeFraudDecision(ctx, *decision)
+		return
+	}
+
+	result, err := server.store.AcceptPaymentRequestTx(ctx, db.AcceptPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            authPayload.Username,
+		FromAccountID:    fromAccount.ID,
+		Screening:        arg.Screening,
+	})
+	if err != nil {
+		writePaymentRequestError(ctx, err)
+		return
+	}
+
+	// the requester isn't told about the payer's account
+	subject := result
+	subject.Transfer = nil
+	notify.Send(ctx, server.notifier, notify.KindPaymentRequestAccepted, subject, request.Requester)
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// declinePaymentRequest refuses the authenticated user's share of a request
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	request, ok := server.loadPaymentRequest(ctx)
+	if !ok {
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if request.payer(authPayload.Username) == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotPayer))
+		return
+	}
+
+	result, err := server.store.DeclinePaymentRequestTx(ctx, db.DeclinePaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            authPayload.Username,
+	})
+	if err != nil {
+		writePaymentRequestError(ctx, err)
+		return
+	}
+
+	notify.Send(ctx, server.notifier, notify.KindPaymentRequestDeclined, result, request.Requester)
+	ctx.JSON(http.StatusOK, newPaymentRequestResponse(result))
+}
+
+func writePaymentRequestError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrPaymentRequestNotPending),
+		errors.Is(err, db.ErrPaymentRequestExpired):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		writeTransferError(ctx, err)
+	}
+}
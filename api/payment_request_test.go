@@ -0,0 +1,489 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/notify"
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordingNotifier keeps the notifications for the tests to check
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (notifier *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	notifier.notifications = append(notifier.notifications, notification)
+	return nil
+}
+
+func TestApi_CreatePaymentRequest(t *testing.T) {
+	account := randomAccount()
+	account.Currency = util.USD
+	payers := []string{util.RandomOwner(), util.RandomOwner(), util.RandomOwner()}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier)
+	}{
+		{
+			name: "EvenSplit",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD, "description": "Dinner",
+				"payers": []gin.H{{"username": payers[0]}, {"username": payers[1]}, {"username": payers[2]}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestTxParams) (db.PaymentRequestTxResult, error) {
+						require.Equal(t, account.Owner, arg.Requester)
+						require.Equal(t, account.ID, arg.ToAccountID)
+						require.Equal(t, "Dinner", arg.Description)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Second)
+						require.Equal(t, []db.PaymentRequestShare{
+							{Payer: payers[0], Amount: 34},
+							{Payer: payers[1], Amount: 33},
+							{Payer: payers[2], Amount: 33},
+						}, arg.Shares)
+						return db.PaymentRequestTxResult{PaymentRequest: db.PaymentRequest{ID: 1, Amount: 100}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				require.Len(t, notifier.notifications, 3)
+				for i, notification := range notifier.notifications {
+					require.Equal(t, payers[i], notification.Username)
+					require.Equal(t, notify.KindPaymentRequestCreated, notification.Kind)
+				}
+			},
+		},
+		{
+			name: "GivenAmounts",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD,
+				"payers": []gin.H{{"username": payers[0], "amount": 70}, {"username": payers[1], "amount": 30}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestTxParams) (db.PaymentRequestTxResult, error) {
+						require.Equal(t, []db.PaymentRequestShare{
+							{Payer: payers[0], Amount: 70},
+							{Payer: payers[1], Amount: 30},
+						}, arg.Shares)
+						return db.PaymentRequestTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AmountsDontAddUp",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD,
+				"payers": []gin.H{{"username": payers[0], "amount": 70}, {"username": payers[1], "amount": 20}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Empty(t, notifier.notifications)
+			},
+		},
+		{
+			name: "SomeAmounts",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD,
+				"payers": []gin.H{{"username": payers[0], "amount": 70}, {"username": payers[1]}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RequesterPays",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD,
+				"payers": []gin.H{{"username": account.Owner}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ShareNeedsApproval",
+			body: gin.H{"to_account_id": account.ID, "amount": testApprovalThreshold + 1, "currency": util.USD,
+				"payers": []gin.H{{"username": payers[0]}}},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotHolder",
+			body: gin.H{"to_account_id": account.ID, "amount": 100, "currency": util.USD,
+				"payers": []gin.H{{"username": payers[0]}}},
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.PaymentRequestDuration = time.Hour
+			server.config.TransferApprovalThreshold = testApprovalThreshold
+			notifier := &recordingNotifier{}
+			server.notifier = notifier
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payment-requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, notifier)
+		})
+	}
+}
+
+func TestApi_AnswerPaymentRequest(t *testing.T) {
+	payerAccount := randomAccount()
+	payerAccount.Currency = util.USD
+	request := db.PaymentRequest{
+		ID:          util.RandomInt(1, 1000),
+		Requester:   util.RandomOwner(),
+		ToAccountID: util.RandomInt(1001, 2000),
+		Amount:      100,
+		Currency:    util.USD,
+		Status:      db.PaymentRequestPending,
+	}
+	payers := []db.PaymentRequestPayer{
+		{ID: 1, PaymentRequestID: request.ID, Payer: payerAccount.Owner, Amount: 60, Status: db.PaymentRequestPayerPending},
+		{ID: 2, PaymentRequestID: request.ID, Payer: util.RandomOwner(), Amount: 40, Status: db.PaymentRequestPayerAccepted},
+	}
+	answered := []db.PaymentRequestPayer{payers[0], payers[1]}
+	answered[0].Status = db.PaymentRequestPayerDeclined
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier)
+	}{
+		{
+			name:     "Accept",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+
+				arg := db.AcceptPaymentRequestTxParams{
+					PaymentRequestID: request.ID,
+					Payer:            payerAccount.Owner,
+					FromAccountID:    payerAccount.ID,
+					Screening:        allowedScreening(),
+				}
+				settled := request
+				settled.Status = db.PaymentRequestSettled
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.PaymentRequestTxResult{PaymentRequest: settled, Transfer: &db.TransferTxResult{}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				require.Len(t, notifier.notifications, 1)
+				require.Equal(t, db.DefaultTenantID, notifier.notifications[0].TenantID)
+				require.Equal(t, request.Requester, notifier.notifications[0].Username)
+				require.Equal(t, notify.KindPaymentRequestAccepted, notifier.notifications[0].Kind)
+				subject := notifier.notifications[0].Subject.(db.PaymentRequestTxResult)
+				require.Equal(t, db.PaymentRequestSettled, subject.PaymentRequest.Status)
+				require.Nil(t, subject.Transfer)
+			},
+		},
+		{
+			name:     "AcceptHeldForReview",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				rule := db.FraudRule{Name: "any", Kind: "large_amount", Action: db.FraudDecisionReview, Score: 10, Enabled: true}
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{rule}, nil)
+
+				// a review would perform the transfer without settling the share
+				assessment := db.FraudAssessment{Decision: db.FraudDecisionDeny, Score: 10, MatchedRules: []string{"any"}}
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Eq(assessment)).Times(1).
+					Return(db.FraudDecision{ID: 3, Decision: db.FraudDecisionDeny}, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Empty(t, notifier.notifications)
+			},
+		},
+		{
+			name:     "AcceptRecipientCoolingOff",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				largeShare := []db.PaymentRequestPayer{payers[0]}
+				largeShare[0].Amount = testBeneficiaryLargeAmount + 1
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(largeShare, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+
+				beneficiary := db.Beneficiary{Owner: payerAccount.Owner, AccountID: request.ToAccountID, CoolingOffUntil: time.Now().Add(time.Hour)}
+				store.EXPECT().
+					GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(db.GetBeneficiaryByAccountParams{Owner: payerAccount.Owner, AccountID: request.ToAccountID})).
+					Times(1).
+					Return(beneficiary, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Empty(t, notifier.notifications)
+			},
+		},
+		{
+			name:     "AcceptFromOthersAccount",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: payers[1].Payer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				// the second payer already paid their share
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptByRequester",
+			action:   "accept",
+			body:     gin.H{"from_account_id": payerAccount.ID},
+			username: request.Requester,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Decline",
+			action:   "decline",
+			username: payerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+
+				arg := db.DeclinePaymentRequestTxParams{PaymentRequestID: request.ID, Payer: payerAccount.Owner}
+				settled := request
+				settled.Status = db.PaymentRequestSettled
+				store.EXPECT().DeclinePaymentRequestTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.PaymentRequestTxResult{PaymentRequest: settled, Payers: answered}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response paymentRequestResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.PaymentRequestSettled, response.Status)
+				require.Equal(t, answered, response.Payers)
+
+				require.Len(t, notifier.notifications, 1)
+				require.Equal(t, notify.KindPaymentRequestDeclined, notifier.notifications[0].Kind)
+			},
+		},
+		{
+			name:     "DeclineExpired",
+			action:   "decline",
+			username: payerAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().DeclinePaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PaymentRequestTxResult{}, db.ErrPaymentRequestExpired)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Empty(t, notifier.notifications)
+			},
+		},
+		{
+			name:     "DeclineByStranger",
+			action:   "decline",
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().ListPaymentRequestPayers(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(payers, nil)
+				store.EXPECT().DeclinePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.BeneficiaryCoolingOff = 24 * time.Hour
+			server.config.BeneficiaryLargeAmount = testBeneficiaryLargeAmount
+			notifier := &recordingNotifier{}
+			server.notifier = notifier
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/payment-requests/%d/%s", request.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, notifier)
+		})
+	}
+}
+
+func TestApi_ListPaymentRequests(t *testing.T) {
+	username := util.RandomOwner()
+	requests := []db.PaymentRequest{{ID: 2, Requester: util.RandomOwner()}, {ID: 1, Requester: util.RandomOwner()}}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Incoming",
+			query: "direction=incoming&status=pending&page=1&size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPaymentRequestsByPayerParams{
+					Payer:  username,
+					Status: sql.NullString{String: db.PaymentRequestPending, Valid: true},
+					Limit:  5,
+				}
+				store.EXPECT().ListPaymentRequestsByPayer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(requests, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []db.PaymentRequest
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 2)
+			},
+		},
+		{
+			name:  "Outgoing",
+			query: "direction=outgoing&page=2&size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPaymentRequestsByRequesterParams{Requester: username, Limit: 5, Offset: 5}
+				store.EXPECT().ListPaymentRequestsByRequester(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NoDirection",
+			query: "page=1&size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPaymentRequestsByPayer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListPaymentRequestsByRequester(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/payment-requests?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
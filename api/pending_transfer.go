@@ -0,0 +1,197 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// needsApproval tells whether a transfer is above the threshold that requires a second person's approval
+func (server *Server) needsApproval(arg db.TransferTxParams) bool {
+	threshold := server.config.TransferApprovalThreshold
+	return threshold > 0 && !arg.Internal && arg.Amount > threshold
+}
+
+// requestTransferApproval holds a transfer for approval, the authenticated caller being its maker
+func (server *Server) requestTransferApproval(ctx *gin.Context, arg db.TransferTxParams) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := server.store.RequestTransferApprovalTx(ctx, db.RequestTransferApprovalTxParams{
+		Transfer:  arg,
+		Maker:     authPayload.Username,
+		ExpiresAt: time.Now().Add(server.config.TransferApprovalDuration),
+	})
+	if err != nil {
+		writePendingTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, result)
+}
+
+type listPendingTransfersRequest struct {
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending approved rejected expired"`
+	Page      int32  `form:"page" binding:"required,min=1"`
+	Size      int32  `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listPendingTransfers lists the transfers from an account that needed approval, newest first
+func (server *Server) listPendingTransfers(ctx *gin.Context) {
+	var req listPendingTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.loadHeldAccount(ctx, req.AccountID, accessView)
+	if !ok {
+		return
+	}
+
+	pending, err := server.store.ListPendingTransfers(ctx, db.ListPendingTransfersParams{
+		FromAccountID: account.ID,
+		Status:        sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:         req.Size,
+		Offset:        (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pending)
+}
+
+type pendingTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// pendingTransferResponse is a transfer that needed approval, with every step of its approval
+type pendingTransferResponse struct {
+	db.PendingTransfer
+	Trail []db.PendingTransferEvent `json:"trail"`
+}
+
+// getPendingTransfer shows a transfer waiting for approval and its trail to whoever may approve it and to the sender's holders
+func (server *Server) getPendingTransfer(ctx *gin.Context) {
+	pending, ok := server.loadPendingTransfer(ctx, accessView)
+	if !ok {
+		return
+	}
+
+	trail, err := server.store.ListPendingTransferEvents(ctx, pending.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransferResponse{PendingTransfer: pending, Trail: trail})
+}
+
+type reviewPendingTransferRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+func (server *Server) approvePendingTransfer(ctx *gin.Context) {
+	server.reviewPendingTransfer(ctx, true)
+}
+
+func (server *Server) rejectPendingTransfer(ctx *gin.Context) {
+	server.reviewPendingTransfer(ctx, false)
+}
+
+// reviewPendingTransfer approves or rejects a transfer on behalf of an approver or a co-holder of the sender,
+// who can't be the maker
+func (server *Server) reviewPendingTransfer(ctx *gin.Context, approve bool) {
+	var req reviewPendingTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pending, ok := server.loadPendingTransfer(ctx, accessTransact)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ReviewPendingTransferTx(ctx, db.ReviewPendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approve:           approve,
+		Approver:          authPayload.Username,
+		Note:              req.Note,
+	})
+	if err != nil {
+		writePendingTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// loadPendingTransfer fetches the transfer of the uri and checks the caller has the approver role
+// or holds the sender with at least the access level, writing the error response otherwise
+func (server *Server) loadPendingTransfer(ctx *gin.Context, access int) (db.PendingTransfer, bool) {
+	var uri pendingTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.PendingTransfer{}, false
+	}
+
+	pending, err := server.store.GetPendingTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return pending, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role == util.ApproverRole {
+		return pending, true
+	}
+	if authPayload.Role == util.BankerRole && access == accessView {
+		return pending, true
+	}
+
+	sender, err := server.store.GetAccount(ctx, pending.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return pending, false
+	}
+	message := "only an approver or a co-holder of the sender can approve a transfer"
+	if access == accessView {
+		message = "transfer doesn't involve an account of the authenticated user"
+	}
+	return pending, server.requireHolder(ctx, sender, access, message)
+}
+
+func writePendingTransferError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrSelfApproval):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, db.ErrPendingTransferNotPending),
+		errors.Is(err, db.ErrPendingTransferExpired):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		writeTransferError(ctx, err)
+	}
+}
+
+// approvalRequiredError rejects a batch item that would need approval, those can only be made on their own
+func approvalRequiredError(threshold int64) error {
+	return fmt.Errorf("transfers above %d need approval and can't be batched", threshold)
+}
@@ -0,0 +1,556 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testApprovalThreshold = 1000
+
+func TestApi_CreateTransferNeedsApproval(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	partner := util.RandomOwner()
+
+	bodyOf := func(amount int64) gin.H {
+		return gin.H{
+			"from_account_id": account1.ID,
+			"to_account_id":   account2.ID,
+			"amount":          amount,
+			"currency":        util.USD,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OwnerMaker",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RequestTransferApprovalTxParams) (db.PendingTransferTxResult, error) {
+						require.Equal(t, account1.Owner, arg.Maker)
+						require.Equal(t, int64(testApprovalThreshold+1), arg.Transfer.Amount)
+						require.Equal(t, allowedScreening(), arg.Transfer.Screening)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+						return db.PendingTransferTxResult{PendingTransfer: db.PendingTransfer{ID: 1, Status: db.PendingTransferPending}}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var result db.PendingTransferTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, db.PendingTransferPending, result.PendingTransfer.Status)
+			},
+		},
+		{
+			name: "CoOwnerMaker",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, partner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Eq(db.GetAccountHolderParams{AccountID: account1.ID, Username: partner})).
+					Times(1).Return(db.AccountHolder{Role: db.AccountHolderCoOwner}, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RequestTransferApprovalTxParams) (db.PendingTransferTxResult, error) {
+						require.Equal(t, partner, arg.Maker)
+						return db.PendingTransferTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "AtThreshold",
+			body: bodyOf(testApprovalThreshold),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PendingTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "FraudReview",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				rule := db.FraudRule{Name: "large", Kind: "large_amount", Action: db.FraudDecisionReview, Score: 50, MinAmount: 1000, Enabled: true}
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{rule}, nil)
+
+				// the approver sees the review instead of the review queue
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RequestTransferApprovalTxParams) (db.PendingTransferTxResult, error) {
+						assessment := &db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 50, MatchedRules: []string{"large"}}
+						require.Equal(t, assessment, arg.Transfer.Screening)
+						return db.PendingTransferTxResult{PendingTransfer: db.PendingTransfer{ID: 1, Status: db.PendingTransferPending}}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "FraudDeny",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account1.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				rule := db.FraudRule{Name: "large", Kind: "large_amount", Action: db.FraudDecisionDeny, Score: 50, MinAmount: 1000, Enabled: true}
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{rule}, nil)
+				store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(db.FraudDecision{ID: 9, Decision: db.FraudDecisionDeny}, nil)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: bodyOf(testApprovalThreshold + 1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(authorizationHeaderKey, "bearer nonsense")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.TransferApprovalThreshold = testApprovalThreshold
+			server.config.TransferApprovalDuration = time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_CreateTransferNeedsApprovalWithDefaultConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config, err := util.LoadConfig("..")
+	require.NoError(t, err)
+	config.FraudRulesFile = filepath.Join("..", config.FraudRulesFile)
+	require.NotZero(t, config.TransferApprovalThreshold)
+
+	payer := randomAccount()
+	payee := randomAccount()
+	payer.Currency = util.USD
+	payee.Currency = util.USD
+	amount := config.TransferApprovalThreshold + 1
+
+	// the shipped rules send large transfers to review, the approver reviews them instead
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+	store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(db.Beneficiary{}, sql.ErrNoRows)
+	store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+	store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(3), nil)
+	store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+	store.EXPECT().CountRoundTransfersSince(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Owner)).AnyTimes().
+		Return(db.User{Username: payer.Owner, PasswordChangedAt: time.Now().Add(-30 * 24 * time.Hour)}, nil)
+	store.EXPECT().RecordFraudDecision(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ interface{}, arg db.RequestTransferApprovalTxParams) (db.PendingTransferTxResult, error) {
+			require.NotNil(t, arg.Transfer.Screening)
+			require.Equal(t, db.FraudDecisionReview, arg.Transfer.Screening.Decision)
+			require.Contains(t, arg.Transfer.Screening.MatchedRules, "large_transfer")
+			return db.PendingTransferTxResult{PendingTransfer: db.PendingTransfer{ID: 1, Status: db.PendingTransferPending}}, nil
+		})
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, err := NewServer(store, config)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	body := gin.H{
+		"from_account_id": payer.ID,
+		"to_account_id":   payee.ID,
+		"amount":          amount,
+		"currency":        util.USD,
+	}
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Owner, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+}
+
+func TestApi_BatchTransferNeedsApproval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payer := randomAccount()
+	payee := randomAccount()
+	payer.Currency = util.USD
+	payee.Currency = util.USD
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(payer, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+	store.EXPECT().ListFraudRules(gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewTestServer(t, store)
+	server.config.TransferApprovalThreshold = testApprovalThreshold
+	recorder := httptest.NewRecorder()
+
+	body := gin.H{"mode": "best_effort", "items": []gin.H{
+		{"from_account_id": payer.ID, "to_account_id": payee.ID, "amount": testApprovalThreshold + 1, "currency": util.USD},
+	}}
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Owner, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusMultiStatus, recorder.Code)
+
+	response := requireBatchResponse(t, recorder)
+	require.Equal(t, batchItemFailed, response.Items[0].Status)
+	require.Contains(t, response.Items[0].Error, "need approval")
+}
+
+func TestApi_ReviewPendingTransfer(t *testing.T) {
+	sender := randomAccount()
+	pending := db.PendingTransfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: sender.ID,
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        5000,
+		Maker:         sender.Owner,
+		Status:        db.PendingTransferPending,
+	}
+	partner := util.RandomOwner()
+	holderOf := func(role string) db.AccountHolder {
+		return db.AccountHolder{AccountID: sender.ID, Username: partner, Role: role}
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "ApproverApproves",
+			action:   "approve",
+			username: util.RandomOwner(),
+			role:     util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ReviewPendingTransferTxParams) (db.PendingTransferTxResult, error) {
+						require.Equal(t, pending.ID, arg.PendingTransferID)
+						require.True(t, arg.Approve)
+						require.Equal(t, "looks fine", arg.Note)
+						return db.PendingTransferTxResult{Transfer: &db.TransferTxResult{}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CoOwnerRejects",
+			action:   "reject",
+			username: partner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(holderOf(db.AccountHolderCoOwner), nil)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ReviewPendingTransferTxParams) (db.PendingTransferTxResult, error) {
+						require.False(t, arg.Approve)
+						require.Equal(t, partner, arg.Approver)
+						return db.PendingTransferTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ViewerCannotApprove",
+			action:   "approve",
+			username: partner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(holderOf(db.AccountHolderViewer), nil)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "BankerCannotApprove",
+			action:   "approve",
+			username: util.RandomOwner(),
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "MakerCannotApprove",
+			action:   "approve",
+			username: sender.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PendingTransferTxResult{}, db.ErrSelfApproval)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotPending",
+			action:   "approve",
+			username: util.RandomOwner(),
+			role:     util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PendingTransferTxResult{}, db.ErrPendingTransferNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Expired",
+			action:   "approve",
+			username: util.RandomOwner(),
+			role:     util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PendingTransferTxResult{}, db.ErrPendingTransferExpired)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			action:   "reject",
+			username: util.RandomOwner(),
+			role:     util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+				store.EXPECT().ReviewPendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"note": "looks fine"})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/pending-transfers/%d/%s", pending.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_GetPendingTransfer(t *testing.T) {
+	sender := randomAccount()
+	pending := db.PendingTransfer{ID: util.RandomInt(1, 1000), FromAccountID: sender.ID, Maker: sender.Owner, Status: db.PendingTransferPending}
+	trail := []db.PendingTransferEvent{
+		{ID: 1, PendingTransferID: pending.ID, Action: db.PendingTransferRequested, Actor: sql.NullString{String: sender.Owner, Valid: true}},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			username: sender.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().ListPendingTransferEvents(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(trail, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response pendingTransferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, pending.ID, response.ID)
+				require.Equal(t, trail, response.Trail)
+			},
+		},
+		{
+			name:     "Outsider",
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sender.ID)).Times(1).Return(sender, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().ListPendingTransferEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/pending-transfers/%d", pending.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type potsUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createPotRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	// Type is the parent's when left out
+	Type string `json:"type" binding:"omitempty,oneof=checking savings"`
+}
+
+// createPot opens a named sub-account under an active top level account, in the same currency
+func (server *Server) createPot(ctx *gin.Context) {
+	var uri potsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req createPotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	parent, ok := server.loadHeldAccount(ctx, uri.ID, accessTransact)
+	if !ok {
+		return
+	}
+	if parent.ParentID.Valid {
+		err := errors.New("pots can't have pots of their own")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+	if parent.Status != db.AccountStatusActive {
+		err := fmt.Errorf("account is %s", parent.Status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	pot, err := server.store.CreatePot(ctx, db.CreatePotParams{
+		Type:     sql.NullString{String: req.Type, Valid: req.Type != ""},
+		Name:     req.Name,
+		ParentID: parent.ID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			err := fmt.Errorf("a pot named %q already exists", req.Name)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(pot))
+}
+
+type listPotsResponse struct {
+	Account accountResponse   `json:"account"`
+	Pots    []accountResponse `json:"pots"`
+	// ConsolidatedBalance adds up the balance of the account and of its pots
+	ConsolidatedBalance int64 `json:"consolidated_balance"`
+}
+
+// listPots shows an account with its pots and their consolidated balance
+func (server *Server) listPots(ctx *gin.Context) {
+	var uri potsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	parent, ok := server.loadHeldAccount(ctx, uri.ID, accessView)
+	if !ok {
+		return
+	}
+
+	pots, err := server.store.ListPots(ctx, parent.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := listPotsResponse{
+		Account:             newAccountResponse(parent),
+		Pots:                make([]accountResponse, len(pots)),
+		ConsolidatedBalance: parent.Balance,
+	}
+	for i, pot := range pots {
+		response.Pots[i] = newAccountResponse(pot)
+		response.ConsolidatedBalance += pot.Balance
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type movePotFundsRequest struct {
+	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// movePotFunds moves money between an account and its pots, or between two pots of the same account.
+// The move is an internal transfer, so it is instant and free.
+func (server *Server) movePotFunds(ctx *gin.Context) {
+	var uri potsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req movePotFundsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.ToAccountID == uri.ID {
+		err := errors.New("can't move money to the same account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, ok := server.loadHeldAccount(ctx, uri.ID, accessTransact)
+	if !ok {
+		return
+	}
+
+	to, err := server.store.GetAccount(ctx, req.ToAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if holderAccountID(from) != holderAccountID(to) {
+		err := errors.New("money only moves between an account and its pots")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        req.Amount,
+		Currency:      from.Currency,
+		Description:   moveDescription(to),
+		Internal:      true,
+	})
+	if err != nil {
+		writeTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func moveDescription(to db.Account) string {
+	if to.ParentID.Valid {
+		return fmt.Sprintf("Move to pot %s", to.Nickname)
+	}
+	return "Move from pots"
+}
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomPot(parent db.Account, name string) db.Account {
+	return db.Account{
+		ID:       parent.ID + util.RandomInt(1, 1000),
+		Owner:    parent.Owner,
+		Balance:  util.RandomMoney(),
+		Currency: parent.Currency,
+		Status:   db.AccountStatusActive,
+		Nickname: name,
+		ParentID: sql.NullInt64{Int64: parent.ID, Valid: true},
+	}
+}
+
+func TestApi_CreatePot(t *testing.T) {
+	parent := randomAccount()
+	parent.Status = db.AccountStatusActive
+	pot := randomPot(parent, "Holidays")
+	pot.Balance = 0
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: parent.ID,
+			body:      gin.H{"name": pot.Nickname},
+			username:  parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				arg := db.CreatePotParams{Name: pot.Nickname, ParentID: parent.ID}
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(pot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesAccount(t, pot, recorder.Body)
+			},
+		},
+		{
+			name:      "SavingsPot",
+			accountID: parent.ID,
+			body:      gin.H{"name": pot.Nickname, "type": db.AccountTypeSavings},
+			username:  parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				arg := db.CreatePotParams{
+					Type:     sql.NullString{String: db.AccountTypeSavings, Valid: true},
+					Name:     pot.Nickname,
+					ParentID: parent.ID,
+				}
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(pot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "PotOfPot",
+			accountID: pot.ID,
+			body:      gin.H{"name": "Flights"},
+			username:  parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "DuplicateName",
+			accountID: parent.ID,
+			body:      gin.H{"name": pot.Nickname},
+			username:  parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "ViewerCannotCreate",
+			accountID: parent.ID,
+			body:      gin.H{"name": pot.Nickname},
+			username:  "viewer",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountHolder{AccountID: parent.ID, Username: "viewer", Role: db.AccountHolderViewer}, nil)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingName",
+			accountID: parent.ID,
+			body:      gin.H{},
+			username:  parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pots", tc.accountID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApi_ListPots(t *testing.T) {
+	parent := randomAccount()
+	pots := []db.Account{randomPot(parent, "Holidays"), randomPot(parent, "Rainy day")}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+	store.EXPECT().ListPots(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(pots, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d/pots", parent.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, parent.Owner, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response listPotsResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, parent, response.Account.Account)
+	require.Len(t, response.Pots, 2)
+	require.Equal(t, pots[0], response.Pots[0].Account)
+	require.Equal(t, pots[1], response.Pots[1].Account)
+	require.Equal(t, parent.Balance+pots[0].Balance+pots[1].Balance, response.ConsolidatedBalance)
+}
+
+func TestApi_MovePotFunds(t *testing.T) {
+	parent := randomAccount()
+	pot := randomPot(parent, "Holidays")
+	other := randomAccount()
+	other.Currency = parent.Currency
+	amount := int64(10)
+
+	testCases := []struct {
+		name          string
+		fromID        int64
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "ToPot",
+			fromID:   parent.ID,
+			body:     gin.H{"to_account_id": pot.ID, "amount": amount},
+			username: parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				arg := db.TransferTxParams{
+					FromAccountID: parent.ID,
+					ToAccountID:   pot.ID,
+					Amount:        amount,
+					Currency:      parent.Currency,
+					Description:   "Move to pot Holidays",
+					Internal:      true,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "FromPot",
+			fromID:   pot.ID,
+			body:     gin.H{"to_account_id": parent.ID, "amount": amount},
+			username: parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				arg := db.TransferTxParams{
+					FromAccountID: pot.ID,
+					ToAccountID:   parent.ID,
+					Amount:        amount,
+					Currency:      parent.Currency,
+					Description:   "Move from pots",
+					Internal:      true,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OutsideTheAccount",
+			fromID:   parent.ID,
+			body:     gin.H{"to_account_id": other.ID, "amount": amount},
+			username: parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "CoOwner",
+			fromID:   pot.ID,
+			body:     gin.H{"to_account_id": parent.ID, "amount": amount},
+			username: "partner",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				// pots share the holders of their parent
+				holder := db.GetAccountHolderParams{AccountID: parent.ID, Username: "partner"}
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Eq(holder)).Times(1).
+					Return(db.AccountHolder{AccountID: parent.ID, Username: "partner", Role: db.AccountHolderCoOwner}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotHolder",
+			fromID:   pot.ID,
+			body:     gin.H{"to_account_id": parent.ID, "amount": amount},
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccountHolder(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountHolder{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "FrozenPot",
+			fromID:   pot.ID,
+			body:     gin.H{"to_account_id": parent.ID, "amount": amount},
+			username: parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "SameAccount",
+			fromID:   pot.ID,
+			body:     gin.H{"to_account_id": pot.ID, "amount": amount},
+			username: parent.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/moves", tc.fromID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
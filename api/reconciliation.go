@@ -0,0 +1,99 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type listReconciliationReportsRequest struct {
+	Page int32 `form:"page" binding:"required,min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=20"`
+}
+
+// listReconciliationReports lists the ledger checks, latest first
+func (server *Server) listReconciliationReports(ctx *gin.Context) {
+	var req listReconciliationReportsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	reports, err := server.store.ListReconciliationReports(ctx, db.ListReconciliationReportsParams{
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reports)
+}
+
+type reconciliationReportUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getReconciliationReport returns a report with all its findings
+func (server *Server) getReconciliationReport(ctx *gin.Context) {
+	var uri reconciliationReportUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	report, err := server.store.GetReconciliationReport(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	findings, err := server.store.ListReconciliationFindings(ctx, report.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.ReconciliationResult{
+		Report:   report,
+		Findings: findings,
+	})
+}
+
+// repairReconciliationReport is the banker's approval of an open report.
+// It resets drifted balances to their entries, the other findings stay for manual correction.
+func (server *Server) repairReconciliationReport(ctx *gin.Context) {
+	var uri reconciliationReportUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.RepairReconciliationTx(ctx, db.RepairReconciliationTxParams{
+		ReportID:   uri.ID,
+		ReviewedBy: authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrReportNotOpen) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
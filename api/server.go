@@ -2,13 +2,17 @@ package api
 
 import (
 	db "code-with-go/db/sqlc"
+	"code-with-go/fraud"
+	"code-with-go/notify"
 	"code-with-go/token"
 	"code-with-go/util"
+	"crypto/ed25519"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"log"
+	"time"
 )
 
 // Server serves HTTP requests to our services
@@ -17,6 +21,12 @@ type Server struct {
 	store      db.Store
 	tokenMaker token.Maker
 	router     *gin.Engine
+	// chainPublicKey verifies the signed chain roots, it is nil when they aren't signed
+	chainPublicKey ed25519.PublicKey
+	// screener runs the fraud rules over every transfer before it's made
+	screener *fraud.Engine
+	// notifier tells users about the changes to what they take part in
+	notifier notify.Notifier
 }
 
 // NewServer creates a new server and set up the routes
@@ -27,6 +37,24 @@ func NewServer(store db.Store, config util.Config) (*Server, error) {
 	}
 	server := &Server{config: config, store: store, tokenMaker: tokenMaker}
 
+	chainKey, err := config.ChainSigningKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load chain signing key: %w", err)
+	}
+	if chainKey != nil {
+		server.chainPublicKey = chainKey.Public().(ed25519.PublicKey)
+	}
+
+	var rules []fraud.Rule
+	if config.FraudRulesFile != "" {
+		rules, err = fraud.LoadRules(config.FraudRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load fraud rules: %w", err)
+		}
+	}
+	server.screener = fraud.NewEngine(store, rules, time.Now)
+	server.notifier = notify.New(config.NotificationWebhookURL)
+
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		err := v.RegisterValidation("currency", validateCurrency)
 		if err != nil {
@@ -40,6 +68,7 @@ func NewServer(store db.Store, config util.Config) (*Server, error) {
 
 func (server *Server) setupRouter() {
 	router := gin.Default()
+	router.Use(tenantMiddleware(server.store, server.config.TenantByHost))
 
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
@@ -48,7 +77,108 @@ func (server *Server) setupRouter() {
 	router.GET("/accounts/:id", server.getAccountById)
 	router.GET("/accounts", server.getAllAccounts)
 
-	router.POST("/transfers", server.createTransfer)
+	router.GET("/fees/quote", server.quoteFee)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+
+	authRoutes.PATCH("/accounts/:id", server.updateAccount)
+	authRoutes.DELETE("/accounts/:id", server.deleteAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/status-changes", server.listAccountStatusChanges)
+	authRoutes.POST("/accounts/:id/pots", server.createPot)
+	authRoutes.GET("/accounts/:id/pots", server.listPots)
+	authRoutes.POST("/accounts/:id/moves", server.movePotFunds)
+	authRoutes.GET("/accounts/:id/holders", server.listAccountHolders)
+	authRoutes.DELETE("/accounts/:id/holders/:username", server.removeAccountHolder)
+	authRoutes.POST("/accounts/:id/invitations", server.inviteAccountHolder)
+	authRoutes.GET("/accounts/:id/escrows", server.listEscrows)
+
+	authRoutes.GET("/invitations", server.listAccountInvitations)
+	authRoutes.POST("/invitations/:id/accept", server.acceptAccountInvitation)
+	authRoutes.POST("/invitations/:id/decline", server.declineAccountInvitation)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/by-reference", server.getTransferByReference)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+
+	authRoutes.GET("/pending-transfers", server.listPendingTransfers)
+	authRoutes.GET("/pending-transfers/:id", server.getPendingTransfer)
+	authRoutes.POST("/pending-transfers/:id/approve", server.approvePendingTransfer)
+	authRoutes.POST("/pending-transfers/:id/reject", server.rejectPendingTransfer)
+
+	authRoutes.GET("/limits", server.getLimits)
+
+	authRoutes.GET("/payees/preview", server.previewPayee)
+	authRoutes.PUT("/users/alias", server.setAlias)
+	authRoutes.DELETE("/users/alias", server.removeAlias)
+
+	authRoutes.POST("/beneficiaries", server.createBeneficiary)
+	authRoutes.GET("/beneficiaries", server.listBeneficiaries)
+	authRoutes.GET("/beneficiaries/:id", server.getBeneficiary)
+	authRoutes.PATCH("/beneficiaries/:id", server.updateBeneficiary)
+	authRoutes.DELETE("/beneficiaries/:id", server.deleteBeneficiary)
+
+	authRoutes.POST("/payment-requests", server.createPaymentRequest)
+	authRoutes.GET("/payment-requests", server.listPaymentRequests)
+	authRoutes.GET("/payment-requests/:id", server.getPaymentRequest)
+	authRoutes.POST("/payment-requests/:id/accept", server.acceptPaymentRequest)
+	authRoutes.POST("/payment-requests/:id/decline", server.declinePaymentRequest)
+
+	authRoutes.POST("/escrows", server.createEscrow)
+	authRoutes.GET("/escrows/:id", server.getEscrow)
+	authRoutes.POST("/escrows/:id/release", server.releaseEscrow)
+	authRoutes.POST("/escrows/:id/refund", server.refundEscrow)
+	authRoutes.POST("/escrows/:id/dispute", server.disputeEscrow)
+
+	authRoutes.POST("/holds", server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+
+	bankerRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), roleMiddleware(util.BankerRole))
+
+	bankerRoutes.GET("/users", server.listUsers)
+	bankerRoutes.POST("/users/:username/verify-email", server.verifyEmail)
+
+	bankerRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	bankerRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	bankerRoutes.POST("/accounts/:id/close", server.closeAccount)
+
+	bankerRoutes.GET("/fee-rules", server.listFeeRules)
+	bankerRoutes.POST("/fee-rules", server.upsertFeeRule)
+	bankerRoutes.DELETE("/fee-rules/:id", server.deleteFeeRule)
+
+	bankerRoutes.GET("/transfer-limits", server.listTransferLimits)
+	bankerRoutes.POST("/transfer-limits", server.upsertTransferLimit)
+	bankerRoutes.DELETE("/transfer-limits/:id", server.deleteTransferLimit)
+
+	bankerRoutes.GET("/interest-rates", server.listInterestRates)
+	bankerRoutes.POST("/interest-rates", server.upsertInterestRate)
+
+	bankerRoutes.GET("/fraud-rules", server.listFraudRules)
+	bankerRoutes.POST("/fraud-rules", server.upsertFraudRule)
+	bankerRoutes.DELETE("/fraud-rules/:id", server.deleteFraudRule)
+
+	bankerRoutes.GET("/fraud-decisions", server.listFraudDecisions)
+	bankerRoutes.GET("/fraud-decisions/:id", server.getFraudDecision)
+	bankerRoutes.POST("/fraud-decisions/:id/approve", server.approveFraudDecision)
+	bankerRoutes.POST("/fraud-decisions/:id/reject", server.rejectFraudDecision)
+
+	bankerRoutes.GET("/chain-roots", server.listChainRoots)
+
+	bankerRoutes.GET("/reconciliation-reports", server.listReconciliationReports)
+	bankerRoutes.GET("/reconciliation-reports/:id", server.getReconciliationReport)
+	bankerRoutes.POST("/reconciliation-reports/:id/repair", server.repairReconciliationReport)
+
+	platformRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), roleMiddleware(util.BankerRole), platformMiddleware())
+
+	platformRoutes.GET("/tenants", server.listTenants)
+	platformRoutes.POST("/tenants", server.createTenant)
+	platformRoutes.PATCH("/tenants/:id", server.updateTenant)
 
 	server.router = router
 }
@@ -0,0 +1,166 @@
+package api
+
+import (
+	db "code-with-go/db/sqlc"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// tenantLimitRequest is a currency limit the accounts of a new tenant start with
+type tenantLimitRequest struct {
+	Currency  string `json:"currency" binding:"required,currency"`
+	Period    string `json:"period" binding:"required,oneof=day week month"`
+	MaxAmount *int64 `json:"max_amount" binding:"omitempty,min=0"`
+	MaxCount  *int64 `json:"max_count" binding:"omitempty,min=0"`
+}
+
+type createTenantRequest struct {
+	Slug       string               `json:"slug" binding:"required,alphanum,lowercase,max=32"`
+	Name       string               `json:"name" binding:"required,max=100"`
+	Host       string               `json:"host" binding:"omitempty,hostname_rfc1123,max=253"`
+	Currencies []string             `json:"currencies" binding:"required,min=1,dive,currency"`
+	Limits     []tenantLimitRequest `json:"limits" binding:"dive"`
+}
+
+func (server *Server) listTenants(ctx *gin.Context) {
+	tenants, err := server.store.ListTenants(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tenants)
+}
+
+// createTenant registers a brand with its house accounts in its currencies and its currency limits.
+// Its bankers manage its other settings once they have signed up on its host.
+func (server *Server) createTenant(ctx *gin.Context) {
+	var req createTenantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateTenantTxParams{
+		Slug:       req.Slug,
+		Name:       req.Name,
+		Host:       strings.ToLower(req.Host),
+		Currencies: req.Currencies,
+	}
+	for _, limit := range req.Limits {
+		if limit.MaxAmount == nil && limit.MaxCount == nil {
+			err := errors.New("max_amount or max_count is required")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if !offersCurrency(req.Currencies, limit.Currency) {
+			err := fmt.Errorf("limit currency %s is not one of the tenant's currencies", limit.Currency)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		tenantLimit := db.TenantLimit{
+			Currency: limit.Currency,
+			Period:   limit.Period,
+		}
+		if limit.MaxAmount != nil {
+			tenantLimit.MaxAmount = sql.NullInt64{Int64: *limit.MaxAmount, Valid: true}
+		}
+		if limit.MaxCount != nil {
+			tenantLimit.MaxCount = sql.NullInt64{Int64: *limit.MaxCount, Valid: true}
+		}
+		arg.Limits = append(arg.Limits, tenantLimit)
+	}
+
+	result, err := server.store.CreateTenantTx(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type tenantUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// updateTenantRequest changes the fields that are given
+type updateTenantRequest struct {
+	Name       *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Host       *string  `json:"host" binding:"omitempty,hostname_rfc1123,max=253"`
+	Currencies []string `json:"currencies" binding:"omitempty,min=1,dive,currency"`
+}
+
+// updateTenant renames a tenant, moves it to another host or changes the currencies accounts can be opened in
+func (server *Server) updateTenant(ctx *gin.Context) {
+	var uri tenantUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateTenantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateTenantParams{
+		ID:         uri.ID,
+		Currencies: req.Currencies,
+	}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: *req.Name, Valid: true}
+	}
+	if req.Host != nil {
+		arg.Host = sql.NullString{String: strings.ToLower(*req.Host), Valid: true}
+	}
+
+	result, err := server.store.UpdateTenantTx(ctx, arg)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case isUniqueViolation(err):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// requireTenantCurrency checks the tenant of the request offers the currency, and writes the error response otherwise
+func (server *Server) requireTenantCurrency(ctx *gin.Context, currency string) bool {
+	tenant, err := server.store.GetTenant(ctx, db.TenantFromContext(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !offersCurrency(tenant.Currencies, currency) {
+		err := fmt.Errorf("accounts can't be opened in %s", currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+func offersCurrency(currencies []string, currency string) bool {
+	for _, offered := range currencies {
+		if offered == currency {
+			return true
+		}
+	}
+	return false
+}
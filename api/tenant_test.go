@@ -0,0 +1,508 @@
+package api
+
+import (
+	"bytes"
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApi_TenantMiddleware(t *testing.T) {
+	tenant := randomTenant()
+	host := sql.NullString{String: tenant.Host.String, Valid: true}
+
+	testCases := []struct {
+		name          string
+		byHost        bool
+		host          string
+		tokenTenantID int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "DefaultTenant",
+			byHost: true,
+			host:   "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, db.DefaultTenantID, recorder.Body)
+			},
+		},
+		{
+			name:   "UnknownHost",
+			byHost: true,
+			host:   "localhost:8080",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTenantByHost(gomock.Any(), gomock.Eq(sql.NullString{String: "localhost", Valid: true})).
+					Times(1).
+					Return(db.Tenant{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, db.DefaultTenantID, recorder.Body)
+			},
+		},
+		{
+			name:   "TenantHost",
+			byHost: true,
+			host:   tenant.Host.String + ":443",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Eq(host)).Times(1).Return(tenant, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:          "TokenOnTenantHost",
+			byHost:        true,
+			host:          tenant.Host.String,
+			tokenTenantID: tenant.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Eq(host)).Times(1).Return(tenant, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:          "TokenOfAnotherTenant",
+			byHost:        true,
+			host:          tenant.Host.String,
+			tokenTenantID: db.DefaultTenantID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Eq(host)).Times(1).Return(tenant, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:          "TokenOnUnknownHost",
+			byHost:        true,
+			host:          "localhost",
+			tokenTenantID: tenant.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Any()).Times(1).Return(db.Tenant{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:          "HostsTurnedOff",
+			byHost:        false,
+			host:          tenant.Host.String,
+			tokenTenantID: tenant.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesTenantID(t, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:   "InternalError",
+			byHost: true,
+			host:   tenant.Host.String,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Any()).Times(1).Return(db.Tenant{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store, util.Config{
+				TokenKey:      util.RandomString(32),
+				TokenDuration: time.Minute,
+				TenantByHost:  tc.byHost,
+			})
+			require.NoError(t, err)
+
+			tenantPath := "/tenant"
+			handlers := []gin.HandlerFunc{func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{"tenant_id": db.TenantFromContext(ctx)})
+			}}
+			if tc.tokenTenantID != 0 {
+				handlers = append([]gin.HandlerFunc{authMiddleware(server.tokenMaker)}, handlers...)
+			}
+			server.router.GET(tenantPath, handlers...)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tenantPath, nil)
+			require.NoError(t, err)
+			request.Host = tc.host
+
+			if tc.tokenTenantID != 0 {
+				accessToken, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.DepositorRole, tc.tokenTenantID, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApi_CreateTenant(t *testing.T) {
+	tenant := randomTenant()
+	maxAmount := int64(50000)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"host":       tenant.Host.String,
+				"currencies": tenant.Currencies,
+				"limits":     []gin.H{{"currency": util.USD, "period": db.LimitPeriodDay, "max_amount": maxAmount}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateTenantTxParams{
+					Slug:       tenant.Slug,
+					Name:       tenant.Name,
+					Host:       tenant.Host.String,
+					Currencies: tenant.Currencies,
+					Limits: []db.TenantLimit{{
+						Currency:  util.USD,
+						Period:    db.LimitPeriodDay,
+						MaxAmount: sql.NullInt64{Int64: maxAmount, Valid: true},
+					}},
+				}
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TenantTxResult{Tenant: tenant}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.TenantTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, tenant.ID, result.Tenant.ID)
+				require.Equal(t, tenant.Currencies, result.Tenant.Currencies)
+			},
+		},
+		{
+			name: "LimitInOtherCurrency",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": []string{util.USD},
+				"limits":     []gin.H{{"currency": util.EUR, "period": db.LimitPeriodDay, "max_amount": maxAmount}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LimitWithoutMaximum",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": []string{util.USD},
+				"limits":     []gin.H{{"currency": util.USD, "period": db.LimitPeriodDay}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": []string{"BRL"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SlugTaken",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": tenant.Currencies,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTenantTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TenantTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotBanker",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": tenant.Currencies,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "BankerOfAnotherTenant",
+			body: gin.H{
+				"slug":       tenant.Slug,
+				"name":       tenant.Name,
+				"currencies": tenant.Currencies,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, err := tokenMaker.CreateToken("banker", util.BankerRole, tenant.ID, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tenants", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApi_UpdateTenant(t *testing.T) {
+	tenant := randomTenant()
+
+	testCases := []struct {
+		name          string
+		tenantID      int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Currencies",
+			tenantID: tenant.ID,
+			body:     gin.H{"currencies": []string{util.USD, util.CAD}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateTenantParams{
+					ID:         tenant.ID,
+					Currencies: []string{util.USD, util.CAD},
+				}
+				store.EXPECT().
+					UpdateTenantTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TenantTxResult{Tenant: tenant}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Host",
+			tenantID: tenant.ID,
+			body:     gin.H{"name": "Renamed", "host": "Bank.Example.com"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateTenantParams{
+					ID:   tenant.ID,
+					Name: sql.NullString{String: "Renamed", Valid: true},
+					Host: sql.NullString{String: "bank.example.com", Valid: true},
+				}
+				store.EXPECT().
+					UpdateTenantTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TenantTxResult{Tenant: tenant}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidHost",
+			tenantID: tenant.ID,
+			body:     gin.H{"host": "bank example"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateTenantTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			tenantID: tenant.ID,
+			body:     gin.H{"name": "Renamed"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateTenantTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TenantTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "HostTaken",
+			tenantID: tenant.ID,
+			body:     gin.H{"host": "bank.example.com"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateTenantTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TenantTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/tenants/%d", tc.tenantID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// defaultTenant is the tenant requests are scoped to in the tests, offering every supported currency
+func defaultTenant() db.Tenant {
+	return db.Tenant{
+		ID:         db.DefaultTenantID,
+		Slug:       "default",
+		Name:       "Default",
+		Currencies: []string{util.USD, util.EUR, util.CAD},
+	}
+}
+
+func randomTenant() db.Tenant {
+	slug := util.RandomOwner()
+	return db.Tenant{
+		ID:         util.RandomInt(2, 1000),
+		Slug:       slug,
+		Name:       slug,
+		Host:       sql.NullString{String: slug + ".example.com", Valid: true},
+		Currencies: []string{util.USD, util.EUR},
+	}
+}
+
+func requireBodyMatchesTenantID(t *testing.T, expected int64, body *bytes.Buffer) {
+	var response struct {
+		TenantID int64 `json:"tenant_id"`
+	}
+	require.NoError(t, json.Unmarshal(body.Bytes(), &response))
+	require.Equal(t, expected, response.TenantID)
+}
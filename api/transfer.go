@@ -2,17 +2,52 @@ package api
 
 import (
 	db "code-with-go/db/sqlc"
+	"code-with-go/token"
+	"code-with-go/util"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"net/http"
 )
 
+// createTransferRequest names the recipient either by account id, by one of the caller's beneficiaries
+// or by exactly one of a username, a verified email or an alias, paid into their account in the currency
 type createTransferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without_all=BeneficiaryID ToUsername ToEmail ToAlias,min=0"`
+	BeneficiaryID     int64  `json:"beneficiary_id" binding:"omitempty,min=1"`
+	ToUsername        string `json:"to_username" binding:"omitempty,alphanum"`
+	ToEmail           string `json:"to_email" binding:"omitempty,email"`
+	ToAlias           string `json:"to_alias" binding:"omitempty,alphanum"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	Description       string `json:"description" binding:"max=255"`
+	ExternalReference string `json:"external_reference" binding:"max=64"`
+	// Metadata is a flat string map, at most 20 keys of 40 characters with values of 500 characters
+	Metadata map[string]string `json:"metadata" binding:"max=20,dive,keys,min=1,max=40,endkeys,max=500"`
+}
+
+func (req createTransferRequest) payee() payee {
+	return payee{Username: req.ToUsername, Email: req.ToEmail, Alias: req.ToAlias}
+}
+
+func (req createTransferRequest) txParams() db.TransferTxParams {
+	arg := db.TransferTxParams{
+		FromAccountID:     req.FromAccountID,
+		ToAccountID:       req.ToAccountID,
+		Amount:            req.Amount,
+		Currency:          req.Currency,
+		Description:       req.Description,
+		ExternalReference: req.ExternalReference,
+	}
+	if len(req.Metadata) > 0 {
+		// a map of strings always marshals
+		arg.Metadata, _ = json.Marshal(req.Metadata)
+	}
+	return arg
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -22,43 +57,402 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validateAccount(ctx, req.FromAccountID, req.Currency) {
+	fromAccount, valid := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
 		return
 	}
-	if !server.validateAccount(ctx, req.ToAccountID, req.Currency) {
+	if !server.requireHolder(ctx, fromAccount, accessTransact, "from account doesn't belong to the authenticated user") {
+		return
+	}
+	toAccount, status, err := server.getRecipientAccount(ctx, req)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
 		return
 	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	arg := req.txParams()
+	arg.ToAccountID = toAccount.ID
+	arg.Actor = ctx.MustGet(authorizationPayloadKey).(*token.Payload).Username
+
+	// approving a fraud review performs the transfer on its own, a transfer needing approval leaves it to the approver
+	needsApproval := server.needsApproval(arg)
+	screen := server.screenTransfer
+	if needsApproval {
+		screen = server.screenTransferForApproval
+	}
+	decision, err := screen(ctx, fromAccount, &arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if decision != nil {
+		writeFraudDecision(ctx, *decision)
+		return
+	}
+
+	if needsApproval {
+		server.requestTransferApproval(ctx, arg)
+		return
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeTransferError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string) bool {
-	account, err := server.store.GetAccount(ctx, accountID)
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+
+	batchItemSucceeded   = "succeeded"
+	batchItemFailed      = "failed"
+	batchItemNotExecuted = "not_executed"
+)
+
+type batchTransferRequest struct {
+	Mode  string                  `json:"mode" binding:"required,oneof=atomic best_effort"`
+	Items []createTransferRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+type batchTransferItemResult struct {
+	Index    int                  `json:"index"`
+	Status   string               `json:"status"`
+	Error    string               `json:"error,omitempty"`
+	Transfer *db.TransferTxResult `json:"transfer,omitempty"`
+}
+
+type batchTransferResponse struct {
+	Mode  string                    `json:"mode"`
+	Items []batchTransferItemResult `json:"items"`
+}
+
+// createBatchTransfer runs many transfers from the caller's accounts in one request.
+// In atomic mode they all go through a single transaction, in best-effort mode each one is tried on its own.
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req batchTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	response := batchTransferResponse{
+		Mode:  req.Mode,
+		Items: make([]batchTransferItemResult, len(req.Items)),
+	}
+
+	transfers := make([]db.TransferTxParams, len(req.Items))
+	valid := true
+	for i, item := range req.Items {
+		response.Items[i] = batchTransferItemResult{Index: i, Status: batchItemNotExecuted}
+		var err error
+		if transfers[i], err = server.validateBatchItem(ctx, item); err != nil {
+			response.Items[i].Status = batchItemFailed
+			response.Items[i].Error = err.Error()
+			valid = false
+		}
+	}
+
+	if req.Mode == batchModeAtomic {
+		if !valid {
+			ctx.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+
+		result, err := server.store.BatchTransferTx(ctx, db.BatchTransferTxParams{Transfers: transfers})
+		if err != nil {
+			writeTransferError(ctx, err)
+			return
+		}
+
+		for i := range result.Transfers {
+			response.Items[i].Status = batchItemSucceeded
+			response.Items[i].Transfer = &result.Transfers[i]
+		}
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	status := http.StatusOK
+	for i := range req.Items {
+		if response.Items[i].Status == batchItemFailed {
+			status = http.StatusMultiStatus
+			continue
+		}
+
+		result, err := server.store.TransferTx(ctx, transfers[i])
+		if err != nil {
+			response.Items[i].Status = batchItemFailed
+			response.Items[i].Error = err.Error()
+			status = http.StatusMultiStatus
+			continue
+		}
+
+		response.Items[i].Status = batchItemSucceeded
+		response.Items[i].Transfer = &result
+	}
+	ctx.JSON(status, response)
+}
+
+// validateBatchItem checks both accounts of a batch item, that the caller owns the source account
+// and that the item doesn't need approval, then screens the transfer.
+// An item the screening doesn't allow fails, a review included: approving it would perform the item outside its batch.
+func (server *Server) validateBatchItem(ctx *gin.Context, item createTransferRequest) (db.TransferTxParams, error) {
+	arg := item.txParams()
+	fromAccount, _, err := server.getAccountInCurrency(ctx, item.FromAccountID, item.Currency)
+	if err != nil {
+		return arg, err
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg.Actor = authPayload.Username
+	held, err := server.holdsAccount(ctx, fromAccount, authPayload.Username, accessTransact)
+	if err != nil {
+		return arg, err
+	}
+	if !held {
+		return arg, errors.New("from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, _, err := server.getRecipientAccount(ctx, item)
+	if err != nil {
+		return arg, err
+	}
+	arg.ToAccountID = toAccount.ID
+	if server.needsApproval(arg) {
+		return arg, approvalRequiredError(server.config.TransferApprovalThreshold)
+	}
+
+	decision, err := server.screenTransferNow(ctx, fromAccount, &arg)
+	if err != nil {
+		return arg, err
+	}
+	if decision != nil {
+		return arg, fraudDecisionError(*decision)
+	}
+	return arg, nil
+}
+
+type getTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransferResponse is a transfer, with its approval trail when it needed one
+type getTransferResponse struct {
+	db.Transfer
+	Approval *pendingTransferResponse `json:"approval,omitempty"`
+}
+
+// getTransfer returns a transfer to the owner of either of its accounts or to a banker
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var uri getTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role != util.BankerRole {
+		sender, err := server.store.GetAccount(ctx, transfer.FromAccountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		recipient, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		held, err := server.holdsAccount(ctx, sender, authPayload.Username, accessView)
+		if err == nil && !held {
+			held, err = server.holdsAccount(ctx, recipient, authPayload.Username, accessView)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !held {
+			err := errors.New("transfer doesn't involve an account of the authenticated user")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	response := getTransferResponse{Transfer: transfer}
+	pending, err := server.store.GetPendingTransferByTransfer(ctx, sql.NullInt64{Int64: transfer.ID, Valid: true})
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err == nil {
+		trail, err := server.store.ListPendingTransferEvents(ctx, pending.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		response.Approval = &pendingTransferResponse{PendingTransfer: pending, Trail: trail}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type reverseTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// reverseTransfer gives back the whole or part of a transfer to its sender.
+// Only a banker or the recipient of the original transfer may do it.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 		} else {
 			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		}
-		return false
+		return
+	}
+
+	recipient, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role != util.BankerRole &&
+		!server.requireHolder(ctx, recipient, accessTransact, "only a banker or the recipient can reverse a transfer") {
+		return
+	}
+
+	arg := db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotReversible) || errors.Is(err, db.ErrReversalExceedsTransfer) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		writeTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, status, err := server.getAccountInCurrency(ctx, accountID, currency)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return account, false
+	}
+	return account, true
+}
+
+// getAccountInCurrency fetches an account and makes sure it holds the given currency.
+// On failure, it also returns the http status matching the error.
+func (server *Server) getAccountInCurrency(ctx *gin.Context, accountID int64, currency string) (db.Account, int, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, http.StatusNotFound, err
+		}
+		return account, http.StatusInternalServerError, err
 	}
 
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		return account, http.StatusBadRequest, err
+	}
+	return account, http.StatusOK, nil
+}
+
+type transferByReferenceRequest struct {
+	FromAccountID     int64  `form:"from_account_id" binding:"required,min=1"`
+	ExternalReference string `form:"external_reference" binding:"required,max=64"`
+}
+
+// getTransferByReference finds a transfer by the reference its sender gave it.
+// Only the sender's holders or a banker may look it up.
+func (server *Server) getTransferByReference(ctx *gin.Context) {
+	var req transferByReferenceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return
+	}
+
+	if _, ok := server.loadHeldAccount(ctx, req.FromAccountID, accessView); !ok {
+		return
+	}
+
+	transfer, err := server.store.GetTransferByExternalReference(ctx, db.GetTransferByExternalReferenceParams{
+		FromAccountID:     req.FromAccountID,
+		ExternalReference: sql.NullString{String: req.ExternalReference, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
-	return true
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// writeTransferError maps the errors a transaction moving money can fail with.
+// Transfers over a limit come back with what is left of the allowance.
+func writeTransferError(ctx *gin.Context, err error) {
+	var limitErr *db.LimitExceededError
+	switch {
+	case errors.As(err, &limitErr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "limit": limitErr.Usage})
+	case isUniqueViolation(err),
+		errors.Is(err, db.ErrAccountFrozen),
+		errors.Is(err, db.ErrAccountClosed):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Name() == "unique_violation"
 }
@@ -4,6 +4,7 @@ import (
 	db "code-with-go/db/sqlc"
 	"code-with-go/util"
 	"database/sql"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"net/http"
@@ -15,6 +16,36 @@ type createUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	// Tenant is the slug of the tenant to sign up with, the tenant of the host is used when it's empty
+	Tenant string `json:"tenant" binding:"omitempty,alphanum,lowercase,max=32"`
+}
+
+var errHostTenantMismatch = errors.New("tenant isn't the one served on the host")
+
+// scopeToTenant scopes the request to the tenant with the slug, when one is given.
+// The tenant must be the one served on the host when the host serves one.
+func (server *Server) scopeToTenant(ctx *gin.Context, slug string) bool {
+	if slug == "" {
+		return true
+	}
+
+	tenant, err := server.store.GetTenantBySlug(ctx, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if hostTenantID, ok := ctx.Get(hostTenantKey); ok && hostTenantID.(int64) != tenant.ID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errHostTenantMismatch))
+		return false
+	}
+
+	ctx.Set(db.TenantKey, tenant.ID)
+	return true
 }
 
 type userResponse struct {
@@ -23,6 +54,8 @@ type userResponse struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Alias             string    `json:"alias,omitempty"`
+	EmailVerified     bool      `json:"email_verified"`
 }
 
 func newUserResponse(user db.User) userResponse {
@@ -32,6 +65,8 @@ func newUserResponse(user db.User) userResponse {
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
+		Alias:             user.Alias.String,
+		EmailVerified:     user.EmailVerifiedAt.Valid,
 	}
 }
 
@@ -42,6 +77,10 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.scopeToTenant(ctx, req.Tenant) {
+		return
+	}
+
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -77,6 +116,10 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.scopeToTenant(ctx, req.Tenant) {
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -93,7 +136,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	token, err := server.tokenMaker.CreateToken(user.Username, server.config.TokenDuration)
+	token, err := server.tokenMaker.CreateToken(user.Username, user.Role, user.TenantID, server.config.TokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -106,12 +149,80 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// loginUserRequest names the tenant of the user when neither the host nor the default tenant is theirs
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
+	Tenant   string `json:"tenant" binding:"omitempty,alphanum,lowercase,max=32"`
 }
 
 type loginUserResponse struct {
 	AccessToken string       `json:"access_token"`
 	User        userResponse `json:"user"`
 }
+
+// listUsersRequest pages with offsets when a page number is given and with cursors otherwise
+type listUsersRequest struct {
+	Page   int32  `form:"page" binding:"omitempty,min=1"`
+	Size   int32  `form:"size" binding:"required,min=5,max=20"`
+	Cursor string `form:"cursor"`
+}
+
+// listUsers lists every user for bankers, oldest first
+func (server *Server) listUsers(ctx *gin.Context) {
+	var req listUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	keyset, ok := keysetMode(ctx, req.Page, req.Cursor)
+	if !ok {
+		return
+	}
+
+	if !keyset {
+		users, err := server.store.ListUsers(ctx, db.ListUsersParams{
+			Limit:  req.Size,
+			Offset: (req.Page - 1) * req.Size,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, newUserResponses(users))
+		return
+	}
+
+	cursor, err := server.decodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := server.store.ListUsersAfter(ctx, db.ListUsersAfterParams{
+		CursorCreatedAt: cursor.createdAt(),
+		CursorUsername:  cursor.username(),
+		Limit:           req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var next *pageCursor
+	if len(users) > int(req.Size) {
+		users = users[:req.Size]
+		last := users[len(users)-1]
+		next = &pageCursor{CreatedAt: last.CreatedAt, Username: last.Username}
+	}
+	server.writeCursorPage(ctx, newUserResponses(users), next)
+}
+
+func newUserResponses(users []db.User) []userResponse {
+	response := make([]userResponse, len(users))
+	for i, user := range users {
+		response[i] = newUserResponse(user)
+	}
+	return response
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	mockdb "code-with-go/db/mock"
 	db "code-with-go/db/sqlc"
+	"code-with-go/token"
 	"code-with-go/util"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -16,6 +18,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type eqCreateUserParamsMatcher struct {
@@ -46,6 +49,7 @@ func EqCreateUserParams(arg db.CreateUserParams, password string) gomock.Matcher
 
 func TestApi_CreateUser(t *testing.T) {
 	user, password := randomUser(t)
+	tenant := randomTenant()
 	testCases := []struct {
 		name          string
 		body          gin.H
@@ -76,6 +80,47 @@ func TestApi_CreateUser(t *testing.T) {
 				requireBodyMatchesUser(t, user, recorder.Body)
 			},
 		},
+		{
+			name: "TenantSlug",
+			body: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+				"tenant":    tenant.Slug,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Eq(tenant.Slug)).Times(1).Return(tenant, nil)
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ db.CreateUserParams) (db.User, error) {
+						require.Equal(t, tenant.ID, db.TenantFromContext(ctx))
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchesUser(t, user, recorder.Body)
+			},
+		},
+		{
+			name: "UnknownTenant",
+			body: gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+				"tenant":    tenant.Slug,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Eq(tenant.Slug)).Times(1).Return(db.Tenant{}, sql.ErrNoRows)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name: "Bad Request",
 			body: gin.H{
@@ -141,6 +186,313 @@ func TestApi_CreateUser(t *testing.T) {
 	}
 }
 
+func TestApi_LoginUser(t *testing.T) {
+	user, password := randomUser(t)
+	user.TenantID = db.DefaultTenantID
+	tenant := randomTenant()
+	tenantUser := user
+	tenantUser.TenantID = tenant.ID
+	host := sql.NullString{String: tenant.Host.String, Valid: true}
+
+	testCases := []struct {
+		name          string
+		byHost        bool
+		host          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireTokenTenantID(t, tokenMaker, db.DefaultTenantID, recorder.Body)
+			},
+		},
+		{
+			name: "TenantSlug",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+				"tenant":   tenant.Slug,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Eq(tenant.Slug)).Times(1).Return(tenant, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ string) (db.User, error) {
+						require.Equal(t, tenant.ID, db.TenantFromContext(ctx))
+						return tenantUser, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireTokenTenantID(t, tokenMaker, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:   "TenantSlugOnTenantHost",
+			byHost: true,
+			host:   tenant.Host.String,
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+				"tenant":   tenant.Slug,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Eq(host)).Times(1).Return(tenant, nil)
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Eq(tenant.Slug)).Times(1).Return(tenant, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(tenantUser, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireTokenTenantID(t, tokenMaker, tenant.ID, recorder.Body)
+			},
+		},
+		{
+			name:   "TenantOfAnotherHost",
+			byHost: true,
+			host:   tenant.Host.String,
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+				"tenant":   "platform",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantByHost(gomock.Any(), gomock.Eq(host)).Times(1).Return(tenant, nil)
+				store.EXPECT().
+					GetTenantBySlug(gomock.Any(), gomock.Eq("platform")).
+					Times(1).
+					Return(db.Tenant{ID: db.DefaultTenantID, Slug: "platform"}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownTenant",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+				"tenant":   tenant.Slug,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Eq(tenant.Slug)).Times(1).Return(db.Tenant{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTenant",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+				"tenant":   "Not-A-Slug",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTenantBySlug(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": password + "x",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store, util.Config{
+				TokenKey:      util.RandomString(32),
+				TokenDuration: time.Minute,
+				TenantByHost:  tc.byHost,
+			})
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Host = tc.host
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker)
+		})
+	}
+}
+
+func TestApi_ListUsers(t *testing.T) {
+	users := make([]db.User, 6)
+	for i := range users {
+		users[i], _ = randomUser(t)
+		users[i].CreatedAt = time.Date(2022, 1, 1, 0, 0, i, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Offset",
+			query: "page=2&size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUsersParams{Limit: 5, Offset: 5}
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(users[5:], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []userResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 1)
+				require.Equal(t, users[5].Username, response[0].Username)
+			},
+		},
+		{
+			name:  "Keyset",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUsersAfterParams{Limit: 6}
+				store.EXPECT().ListUsersAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(users, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response struct {
+					Items      []userResponse `json:"items"`
+					NextCursor string         `json:"next_cursor"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Items, 5)
+
+				cursor, err := server.decodeCursor(response.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, users[4].Username, cursor.Username)
+				require.NotEmpty(t, recorder.Header().Get("Link"))
+			},
+		},
+		{
+			name:  "NotBanker",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "size=5&cursor=abc",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsersAfter(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
 func requireBodyMatchesUser(t *testing.T, expected db.User, body *bytes.Buffer) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
@@ -166,3 +518,13 @@ func randomUser(t *testing.T) (db.User, string) {
 		Email:          util.RandomEmail(),
 	}, password
 }
+
+func requireTokenTenantID(t *testing.T, tokenMaker token.Maker, expected int64, body *bytes.Buffer) {
+	var response loginUserResponse
+	err := json.Unmarshal(body.Bytes(), &response)
+	require.NoError(t, err)
+
+	payload, err := tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, expected, payload.TenantID)
+}
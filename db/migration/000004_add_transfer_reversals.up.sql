@@ -0,0 +1,17 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "original_transfer_id" bigint;
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("original_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries"
+    ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfers" ("original_transfer_id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "transfers"."original_transfer_id" IS 'set when this transfer reverses another one';
@@ -0,0 +1,35 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "holds"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "captured_amount" bigint      NOT NULL DEFAULT 0,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("to_account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of pending holds, not available for spending';
+
+COMMENT ON COLUMN "holds"."status" IS 'pending, captured, voided or expired';
@@ -0,0 +1,11 @@
+-- the housefees user and its accounts are kept since entries may reference them
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "percentage_fee";
+
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "flat_fee";
+
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "fee_rule_id";
+
+DROP TABLE IF EXISTS "fee_rules";
@@ -0,0 +1,39 @@
+CREATE TABLE "fee_rules"
+(
+    "id"             bigserial PRIMARY KEY,
+    "currency"       varchar     NOT NULL,
+    "min_amount"     bigint      NOT NULL DEFAULT 0,
+    "flat_fee"       bigint      NOT NULL DEFAULT 0,
+    "percentage_bps" bigint      NOT NULL DEFAULT 0,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fee_rules"
+    ADD CONSTRAINT "currency_min_amount_key" UNIQUE ("currency", "min_amount");
+
+ALTER TABLE "transfers"
+    ADD COLUMN "fee_rule_id" bigint;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "flat_fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "percentage_fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("fee_rule_id") REFERENCES "fee_rules" ("id");
+
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'lower bound of the tier, the highest one not above the amount applies';
+
+COMMENT ON COLUMN "fee_rules"."percentage_bps" IS 'percentage of the amount in basis points';
+
+-- house account collecting the fees, nobody can log in with an empty password hash
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('housefees', '', 'Fee revenue', 'fees@house.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('housefees', 0, 'USD'),
+       ('housefees', 0, 'EUR'),
+       ('housefees', 0, 'CAD')
+ON CONFLICT DO NOTHING;
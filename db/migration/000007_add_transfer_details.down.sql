@@ -0,0 +1,8 @@
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "external_reference";
+
+ALTER TABLE IF EXISTS "transfers"
+    DROP COLUMN IF EXISTS "description";
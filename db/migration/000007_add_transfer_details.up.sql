@@ -0,0 +1,15 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers"
+    ADD COLUMN "external_reference" varchar;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "transfers"
+    ADD CONSTRAINT "metadata_size_check" CHECK (octet_length("metadata"::text) <= 4096);
+
+CREATE UNIQUE INDEX ON "transfers" ("from_account_id", "external_reference");
+
+COMMENT ON COLUMN "transfers"."external_reference" IS 'client supplied, unique per sender account';
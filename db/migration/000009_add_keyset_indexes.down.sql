@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "accounts_created_at_id_idx";
+
+DROP INDEX IF EXISTS "users_created_at_username_idx";
@@ -0,0 +1,3 @@
+CREATE INDEX ON "accounts" ("created_at", "id");
+
+CREATE INDEX ON "users" ("created_at", "username");
@@ -0,0 +1,39 @@
+CREATE TABLE "reconciliation_reports"
+(
+    "id"             bigserial PRIMARY KEY,
+    "status"         varchar     NOT NULL,
+    "findings_count" bigint      NOT NULL DEFAULT 0,
+    "reviewed_by"    varchar,
+    "reviewed_at"    timestamptz,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "reconciliation_findings"
+(
+    "id"          bigserial PRIMARY KEY,
+    "report_id"   bigint      NOT NULL,
+    "kind"        varchar     NOT NULL,
+    "account_id"  bigint,
+    "entry_id"    bigint,
+    "transfer_id" bigint,
+    "expected"    bigint      NOT NULL,
+    "actual"      bigint      NOT NULL,
+    "repaired_at" timestamptz,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "reconciliation_reports"
+    ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "reconciliation_findings"
+    ADD FOREIGN KEY ("report_id") REFERENCES "reconciliation_reports" ("id");
+
+CREATE INDEX ON "reconciliation_reports" ("created_at");
+
+CREATE INDEX ON "reconciliation_findings" ("report_id");
+
+COMMENT ON COLUMN "reconciliation_reports"."status" IS 'clean, open or repaired';
+
+COMMENT ON COLUMN "reconciliation_findings"."kind" IS 'balance_drift, orphan_entry or unbalanced_transfer';
+
+COMMENT ON COLUMN "reconciliation_findings"."expected" IS 'entries total for a drift, zero for an orphan entry, amount plus fees for a transfer';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "postings";
+
+DROP TABLE IF EXISTS "journal_entries";
+
+DROP FUNCTION IF EXISTS "check_journal_entry_balanced"();
@@ -0,0 +1,86 @@
+CREATE TABLE "journal_entries"
+(
+    "id"          bigserial PRIMARY KEY,
+    "transfer_id" bigint UNIQUE,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "postings"
+(
+    "id"               bigserial PRIMARY KEY,
+    "journal_entry_id" bigint      NOT NULL,
+    "account_id"       bigint      NOT NULL,
+    "entry_id"         bigint UNIQUE,
+    "currency"         varchar     NOT NULL,
+    "amount"           bigint      NOT NULL,
+    "created_at"       timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "journal_entries"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "postings"
+    ADD FOREIGN KEY ("journal_entry_id") REFERENCES "journal_entries" ("id");
+
+ALTER TABLE "postings"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "postings"
+    ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+CREATE INDEX ON "postings" ("journal_entry_id");
+
+CREATE INDEX ON "postings" ("account_id");
+
+COMMENT ON COLUMN "postings"."amount" IS 'debits are negative, the postings of a journal entry sum to zero per currency';
+
+COMMENT ON COLUMN "postings"."entry_id" IS 'the account entry this posting shows up as in the history';
+
+-- checked at commit, so a journal entry can be written one posting at a time
+CREATE FUNCTION "check_journal_entry_balanced"() RETURNS trigger AS
+$$
+DECLARE
+    journal_id bigint;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        journal_id := OLD.journal_entry_id;
+    ELSE
+        journal_id := NEW.journal_entry_id;
+    END IF;
+
+    IF EXISTS (SELECT 1
+               FROM postings
+               WHERE journal_entry_id = journal_id
+               GROUP BY currency
+               HAVING SUM(amount) <> 0) THEN
+        RAISE EXCEPTION 'journal entry % does not balance', journal_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "postings_balanced"
+    AFTER INSERT OR UPDATE OR DELETE
+    ON "postings"
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE FUNCTION "check_journal_entry_balanced"();
+
+-- backfill every transfer whose entries already balance, the others are left for reconciliation
+INSERT INTO "journal_entries" ("transfer_id", "created_at")
+SELECT t.id, t.created_at
+FROM transfers t
+WHERE EXISTS (SELECT 1 FROM entries e WHERE e.transfer_id = t.id)
+  AND NOT EXISTS (SELECT 1
+                  FROM entries e
+                           JOIN accounts a ON a.id = e.account_id
+                  WHERE e.transfer_id = t.id
+                  GROUP BY a.currency
+                  HAVING SUM(e.amount) <> 0);
+
+INSERT INTO "postings" ("journal_entry_id", "account_id", "entry_id", "currency", "amount", "created_at")
+SELECT j.id, e.account_id, e.id, a.currency, e.amount, e.created_at
+FROM journal_entries j
+         JOIN entries e ON e.transfer_id = j.transfer_id
+         JOIN accounts a ON a.id = e.account_id;
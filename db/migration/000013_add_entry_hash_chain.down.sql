@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "chain_roots";
+
+ALTER TABLE IF EXISTS "entries"
+    DROP COLUMN IF EXISTS "hash";
+
+ALTER TABLE IF EXISTS "entries"
+    DROP COLUMN IF EXISTS "prev_hash";
@@ -0,0 +1,43 @@
+ALTER TABLE "entries"
+    ADD COLUMN "prev_hash" bytea;
+
+ALTER TABLE "entries"
+    ADD COLUMN "hash" bytea;
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 of the entry and prev_hash, the hash of the previous entry of the account';
+
+CREATE TABLE "chain_roots"
+(
+    "day"        date PRIMARY KEY,
+    "root_hash"  bytea       NOT NULL,
+    "signature"  bytea       NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "chain_roots"."root_hash" IS 'sha256 over the last entry hash of every account at the end of the day';
+
+-- seal the existing entries the same way the application does, see db.EntryHash
+DO
+$$
+    DECLARE
+        e    record;
+        prev bytea;
+        acc  bigint;
+        h    bytea;
+    BEGIN
+        FOR e IN SELECT * FROM entries ORDER BY account_id, id
+            LOOP
+                IF acc IS DISTINCT FROM e.account_id THEN
+                    acc := e.account_id;
+                    prev := NULL;
+                END IF;
+
+                h := sha256(convert_to(format('%s|%s|%s|%s|%s|', e.id, e.account_id, e.amount,
+                                              COALESCE(e.transfer_id, 0),
+                                              (extract(epoch FROM e.created_at) * 1000000)::bigint), 'UTF8')
+                    || COALESCE(prev, ''::bytea));
+                UPDATE entries SET prev_hash = prev, hash = h WHERE id = e.id;
+                prev := h;
+            END LOOP;
+    END
+$$;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "account_status_changes";
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,28 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "account_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+CREATE TABLE "account_status_changes"
+(
+    "id"          bigserial PRIMARY KEY,
+    "account_id"  bigint      NOT NULL,
+    "from_status" varchar     NOT NULL,
+    "to_status"   varchar     NOT NULL,
+    "actor"       varchar     NOT NULL,
+    "reason"      varchar     NOT NULL,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_status_changes"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes"
+    ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_status_changes" ("account_id", "created_at");
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, a closed account never reopens';
+
+COMMENT ON COLUMN "account_status_changes"."actor" IS 'the user who made the change';
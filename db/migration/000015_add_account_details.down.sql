@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "overdraft_limit";
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "nickname";
@@ -0,0 +1,15 @@
+-- the houseinterest user and its accounts are kept since entries may reference them
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "interest_rates";
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP CONSTRAINT IF EXISTS "owner_currency_type_key";
+
+ALTER TABLE IF EXISTS "accounts"
+    ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "type";
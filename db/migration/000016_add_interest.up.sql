@@ -0,0 +1,85 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "account_type_check" CHECK ("type" IN ('checking', 'savings'));
+
+-- an owner can keep a checking and a savings account in the same currency
+ALTER TABLE "accounts"
+    DROP CONSTRAINT "owner_currency_key";
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
+
+CREATE TABLE "interest_rates"
+(
+    "id"              bigserial PRIMARY KEY,
+    "currency"        varchar     NOT NULL,
+    "account_type"    varchar     NOT NULL,
+    "annual_rate_bps" bigint      NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_rates"
+    ADD CONSTRAINT "currency_account_type_key" UNIQUE ("currency", "account_type");
+
+ALTER TABLE "interest_rates"
+    ADD CONSTRAINT "annual_rate_bps_check" CHECK ("annual_rate_bps" >= 0);
+
+CREATE TABLE "interest_accruals"
+(
+    "id"              bigserial PRIMARY KEY,
+    "account_id"      bigint      NOT NULL,
+    "accrual_date"    date        NOT NULL,
+    "balance"         bigint      NOT NULL,
+    "annual_rate_bps" bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "carry"           bigint      NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals"
+    ADD CONSTRAINT "account_accrual_date_key" UNIQUE ("account_id", "accrual_date");
+
+CREATE TABLE "interest_postings"
+(
+    "id"          bigserial PRIMARY KEY,
+    "account_id"  bigint      NOT NULL,
+    "period"      date        NOT NULL,
+    "amount"      bigint      NOT NULL,
+    "transfer_id" bigint,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_postings"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "interest_postings"
+    ADD CONSTRAINT "account_period_key" UNIQUE ("account_id", "period");
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, the interest rate depends on it';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance at the end of the accrual date';
+
+COMMENT ON COLUMN "interest_accruals"."carry" IS 'fraction of a minor unit left over after rounding, in 1/3650000ths';
+
+COMMENT ON COLUMN "interest_postings"."period" IS 'first day of the month the interest was accrued in';
+
+COMMENT ON COLUMN "interest_postings"."transfer_id" IS 'empty when nothing was accrued';
+
+-- house account paying the interest, nobody can log in with an empty password hash
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('houseinterest', '', 'Interest expense', 'interest@house.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('houseinterest', 0, 'USD'),
+       ('houseinterest', 0, 'EUR'),
+       ('houseinterest', 0, 'CAD')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,56 @@
+CREATE TABLE "account_holders"
+(
+    "account_id" bigint      NOT NULL,
+    "username"   varchar     NOT NULL,
+    "role"       varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "username")
+);
+
+ALTER TABLE "account_holders"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_holders"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_holders"
+    ADD CONSTRAINT "account_holder_role_check" CHECK ("role" IN ('primary', 'co_owner', 'viewer'));
+
+CREATE INDEX ON "account_holders" ("username");
+
+CREATE TABLE "account_invitations"
+(
+    "id"           bigserial PRIMARY KEY,
+    "account_id"   bigint      NOT NULL,
+    "invitee"      varchar     NOT NULL,
+    "role"         varchar     NOT NULL,
+    "invited_by"   varchar     NOT NULL,
+    "status"       varchar     NOT NULL DEFAULT 'pending',
+    "responded_at" timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_invitations"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_invitations"
+    ADD FOREIGN KEY ("invitee") REFERENCES "users" ("username");
+
+ALTER TABLE "account_invitations"
+    ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+ALTER TABLE "account_invitations"
+    ADD CONSTRAINT "account_invitation_role_check" CHECK ("role" IN ('co_owner', 'viewer'));
+
+-- a user has at most one pending invitation per account
+CREATE UNIQUE INDEX ON "account_invitations" ("account_id", "invitee") WHERE "status" = 'pending';
+
+CREATE INDEX ON "account_invitations" ("invitee", "status");
+
+COMMENT ON COLUMN "account_holders"."role" IS 'primary, co_owner or viewer, the primary holder is the account owner';
+
+COMMENT ON COLUMN "account_invitations"."status" IS 'pending, accepted or declined';
+
+INSERT INTO "account_holders" ("account_id", "username", "role")
+SELECT "id", "owner", 'primary'
+FROM "accounts";
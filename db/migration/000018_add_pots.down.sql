@@ -0,0 +1,10 @@
+-- fails while pots exist, they would break the unique owner, currency and type
+DROP INDEX IF EXISTS "accounts_parent_id_nickname_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_type_idx";
+
+ALTER TABLE IF EXISTS "accounts"
+    ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP COLUMN IF EXISTS "parent_id";
@@ -0,0 +1,18 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "parent_id" bigint;
+
+ALTER TABLE "accounts"
+    ADD FOREIGN KEY ("parent_id") REFERENCES "accounts" ("id");
+
+COMMENT ON COLUMN "accounts"."parent_id" IS 'set on pots, the sub-accounts sharing the owner, currency and holders of their parent';
+
+-- pots share the owner and currency of their parent, so only top level accounts stay unique
+ALTER TABLE "accounts"
+    DROP CONSTRAINT "owner_currency_type_key";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_type_idx" ON "accounts" ("owner", "currency", "type")
+    WHERE "parent_id" IS NULL;
+
+-- the nickname of a pot is its name, unique under the parent
+CREATE UNIQUE INDEX "accounts_parent_id_nickname_idx" ON "accounts" ("parent_id", "nickname")
+    WHERE "parent_id" IS NOT NULL;
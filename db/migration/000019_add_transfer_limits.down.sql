@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_limit_counters";
+
+DROP TABLE IF EXISTS "transfer_limits";
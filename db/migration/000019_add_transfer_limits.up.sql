@@ -0,0 +1,43 @@
+CREATE TABLE "transfer_limits"
+(
+    "id"         bigserial PRIMARY KEY,
+    "scope"      varchar     NOT NULL,
+    "subject"    varchar     NOT NULL DEFAULT '',
+    "currency"   varchar     NOT NULL,
+    "period"     varchar     NOT NULL,
+    "max_amount" bigint,
+    "max_count"  bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_limits"
+    ADD CONSTRAINT "scope_subject_currency_period_key" UNIQUE ("scope", "subject", "currency", "period");
+
+ALTER TABLE "transfer_limits"
+    ADD CONSTRAINT "transfer_limit_scope_check" CHECK ("scope" IN ('currency', 'user', 'account'));
+
+ALTER TABLE "transfer_limits"
+    ADD CONSTRAINT "transfer_limit_period_check" CHECK ("period" IN ('day', 'week', 'month'));
+
+ALTER TABLE "transfer_limits"
+    ADD CONSTRAINT "transfer_limit_max_check" CHECK (("max_amount" IS NOT NULL OR "max_count" IS NOT NULL)
+        AND COALESCE("max_amount", 0) >= 0 AND COALESCE("max_count", 0) >= 0);
+
+COMMENT ON COLUMN "transfer_limits"."scope" IS 'currency limits apply to every account in the currency unless the account has its own';
+
+COMMENT ON COLUMN "transfer_limits"."subject" IS 'the account id or the username the limit applies to, empty for currency limits';
+
+-- outbound usage of an account or of a user in a currency, locked by every transfer counting against it
+CREATE TABLE "transfer_limit_counters"
+(
+    "scope"        varchar NOT NULL,
+    "subject"      varchar NOT NULL,
+    "currency"     varchar NOT NULL,
+    "period"       varchar NOT NULL,
+    "window_start" date    NOT NULL,
+    "amount"       bigint  NOT NULL DEFAULT 0,
+    "count"        bigint  NOT NULL DEFAULT 0,
+    PRIMARY KEY ("scope", "subject", "currency", "period", "window_start")
+);
+
+COMMENT ON COLUMN "transfer_limit_counters"."scope" IS 'account or user, currency limits count against the account';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "fraud_decisions";
+
+DROP TABLE IF EXISTS "fraud_rules";
@@ -0,0 +1,66 @@
+CREATE TABLE "fraud_rules"
+(
+    "id"             bigserial PRIMARY KEY,
+    "name"           varchar     NOT NULL,
+    "kind"           varchar     NOT NULL,
+    "action"         varchar     NOT NULL,
+    "score"          bigint      NOT NULL DEFAULT 0,
+    "currency"       varchar     NOT NULL DEFAULT '',
+    "min_amount"     bigint      NOT NULL DEFAULT 0,
+    "min_count"      bigint      NOT NULL DEFAULT 0,
+    "window_seconds" bigint      NOT NULL DEFAULT 0,
+    "round_to"       bigint      NOT NULL DEFAULT 0,
+    "enabled"        boolean     NOT NULL DEFAULT true,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fraud_rules"
+    ADD CONSTRAINT "fraud_rule_name_key" UNIQUE ("name");
+
+ALTER TABLE "fraud_rules"
+    ADD CONSTRAINT "fraud_rule_action_check" CHECK ("action" IN ('review', 'deny'));
+
+COMMENT ON COLUMN "fraud_rules"."name" IS 'a rule named like one of the rules file replaces it';
+
+COMMENT ON COLUMN "fraud_rules"."currency" IS 'the rule applies to every currency when empty';
+
+CREATE TABLE "fraud_decisions"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "decision"        varchar     NOT NULL,
+    "score"           bigint      NOT NULL,
+    "matched_rules"   jsonb       NOT NULL DEFAULT '[]',
+    "request"         jsonb       NOT NULL,
+    "review_status"   varchar,
+    "reviewed_by"     varchar,
+    "reviewed_at"     timestamptz,
+    "transfer_id"     bigint,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fraud_decisions"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions"
+    ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "fraud_decisions"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "fraud_decisions"
+    ADD CONSTRAINT "fraud_decision_check" CHECK ("decision" IN ('allow', 'review', 'deny'));
+
+CREATE INDEX ON "fraud_decisions" ("review_status", "id");
+
+CREATE INDEX ON "fraud_decisions" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "fraud_decisions"."request" IS 'the transfer as requested, performed when a review is approved';
+
+COMMENT ON COLUMN "fraud_decisions"."review_status" IS 'pending, approved or rejected, only set on reviews';
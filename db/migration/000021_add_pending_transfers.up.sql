@@ -0,0 +1,71 @@
+CREATE TABLE "pending_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "request"         jsonb       NOT NULL,
+    "screening"       jsonb       NOT NULL DEFAULT 'null',
+    "maker"           varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "hold_id"         bigint      NOT NULL,
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "updated_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("maker") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("hold_id") REFERENCES "holds" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD CONSTRAINT "pending_transfer_status_check" CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired'));
+
+CREATE INDEX ON "pending_transfers" ("from_account_id", "id");
+
+CREATE INDEX ON "pending_transfers" ("status", "expires_at");
+
+CREATE UNIQUE INDEX ON "pending_transfers" ("transfer_id");
+
+COMMENT ON COLUMN "pending_transfers"."request" IS 'the transfer as requested, performed once approved';
+
+COMMENT ON COLUMN "pending_transfers"."screening" IS 'the fraud assessment that allowed the transfer, null when it was not screened';
+
+COMMENT ON COLUMN "pending_transfers"."status" IS 'pending, approved, rejected or expired';
+
+COMMENT ON COLUMN "pending_transfers"."hold_id" IS 'reserves the amount on the sender while pending';
+
+CREATE TABLE "pending_transfer_events"
+(
+    "id"                  bigserial PRIMARY KEY,
+    "pending_transfer_id" bigint      NOT NULL,
+    "action"              varchar     NOT NULL,
+    "actor"               varchar,
+    "note"                varchar     NOT NULL DEFAULT '',
+    "created_at"          timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pending_transfer_events"
+    ADD FOREIGN KEY ("pending_transfer_id") REFERENCES "pending_transfers" ("id");
+
+ALTER TABLE "pending_transfer_events"
+    ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
+
+CREATE INDEX ON "pending_transfer_events" ("pending_transfer_id", "id");
+
+COMMENT ON COLUMN "pending_transfer_events"."action" IS 'requested, approved, rejected or expired';
+
+COMMENT ON COLUMN "pending_transfer_events"."actor" IS 'empty when the system expired the transfer';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "users_email_idx";
+
+DROP INDEX IF EXISTS "users_alias_idx";
+
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "email_verified_at";
+
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "alias";
@@ -0,0 +1,13 @@
+ALTER TABLE "users"
+    ADD COLUMN "alias" varchar;
+
+ALTER TABLE "users"
+    ADD COLUMN "email_verified_at" timestamptz;
+
+CREATE UNIQUE INDEX "users_alias_idx" ON "users" (lower("alias"));
+
+CREATE INDEX "users_email_idx" ON "users" (lower("email"));
+
+COMMENT ON COLUMN "users"."alias" IS 'chosen by the user to be paid by, unique regardless of case';
+
+COMMENT ON COLUMN "users"."email_verified_at" IS 'only a verified email can be paid to';
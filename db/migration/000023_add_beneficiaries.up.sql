@@ -0,0 +1,24 @@
+CREATE TABLE "beneficiaries"
+(
+    "id"                bigserial PRIMARY KEY,
+    "owner"             varchar     NOT NULL,
+    "nickname"          varchar     NOT NULL,
+    "account_id"        bigint      NOT NULL,
+    "currency"          varchar     NOT NULL,
+    "cooling_off_until" timestamptz NOT NULL DEFAULT (now()),
+    "created_at"        timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "beneficiaries"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "beneficiaries"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "nickname");
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "account_id");
+
+COMMENT ON COLUMN "beneficiaries"."currency" IS 'the currency of the account, transfers to the beneficiary must use it';
+
+COMMENT ON COLUMN "beneficiaries"."cooling_off_until" IS 'large amounts can only be sent to the beneficiary from then on';
@@ -0,0 +1,69 @@
+CREATE TABLE "payment_requests"
+(
+    "id"            bigserial PRIMARY KEY,
+    "requester"     varchar     NOT NULL,
+    "to_account_id" bigint      NOT NULL,
+    "amount"        bigint      NOT NULL,
+    "currency"      varchar     NOT NULL,
+    "description"   varchar     NOT NULL DEFAULT '',
+    "status"        varchar     NOT NULL DEFAULT 'pending',
+    "expires_at"    timestamptz NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now()),
+    "updated_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests"
+    ADD CONSTRAINT "payment_request_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "payment_requests"
+    ADD CONSTRAINT "payment_request_status_check" CHECK ("status" IN ('pending', 'settled', 'declined', 'expired'));
+
+CREATE INDEX ON "payment_requests" ("requester", "id");
+
+CREATE INDEX ON "payment_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "payment_requests"."amount" IS 'the total of the shares of the payers';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending until every payer answered: settled when one paid, declined when none did, or expired';
+
+CREATE TABLE "payment_request_payers"
+(
+    "id"                 bigserial PRIMARY KEY,
+    "payment_request_id" bigint      NOT NULL,
+    "payer"              varchar     NOT NULL,
+    "amount"             bigint      NOT NULL,
+    "status"             varchar     NOT NULL DEFAULT 'pending',
+    "transfer_id"        bigint,
+    "updated_at"         timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payment_request_payers"
+    ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id");
+
+ALTER TABLE "payment_request_payers"
+    ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_request_payers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payment_request_payers"
+    ADD CONSTRAINT "payment_request_payer_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "payment_request_payers"
+    ADD CONSTRAINT "payment_request_payer_status_check" CHECK ("status" IN ('pending', 'accepted', 'declined', 'expired'));
+
+CREATE UNIQUE INDEX ON "payment_request_payers" ("payment_request_id", "payer");
+
+CREATE INDEX ON "payment_request_payers" ("payer", "payment_request_id");
+
+CREATE UNIQUE INDEX ON "payment_request_payers" ("transfer_id");
+
+COMMENT ON COLUMN "payment_request_payers"."amount" IS 'the share of the request this payer is asked for';
+
+COMMENT ON COLUMN "payment_request_payers"."transfer_id" IS 'the transfer paying the share once accepted';
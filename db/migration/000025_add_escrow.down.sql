@@ -0,0 +1,15 @@
+-- the houseescrow user and its accounts are kept since entries may reference them,
+-- so they become checking accounts again
+DROP TABLE IF EXISTS "escrow_agreements";
+
+UPDATE "accounts"
+SET "type" = 'checking'
+WHERE "type" = 'escrow';
+
+ALTER TABLE IF EXISTS "accounts"
+    DROP CONSTRAINT IF EXISTS "account_type_check";
+
+ALTER TABLE IF EXISTS "accounts"
+    ADD CONSTRAINT "account_type_check" CHECK ("type" IN ('checking', 'savings'));
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, the interest rate depends on it';
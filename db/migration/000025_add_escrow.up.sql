@@ -0,0 +1,83 @@
+ALTER TABLE "accounts"
+    DROP CONSTRAINT "account_type_check";
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "account_type_check" CHECK ("type" IN ('checking', 'savings', 'escrow'));
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, the interest rate depends on it, or escrow for the house accounts holding escrowed funds';
+
+CREATE TABLE "escrow_agreements"
+(
+    "id"                     bigserial PRIMARY KEY,
+    "buyer"                  varchar     NOT NULL,
+    "buyer_account_id"       bigint      NOT NULL,
+    "seller_account_id"      bigint      NOT NULL,
+    "escrow_account_id"      bigint      NOT NULL,
+    "amount"                 bigint      NOT NULL,
+    "currency"               varchar     NOT NULL,
+    "condition"              varchar     NOT NULL,
+    "status"                 varchar     NOT NULL DEFAULT 'funded',
+    "deadline"               timestamptz NOT NULL,
+    "funding_transfer_id"    bigint,
+    "settlement_transfer_id" bigint,
+    "disputed_by"            varchar,
+    "dispute_reason"         varchar     NOT NULL DEFAULT '',
+    "resolved_by"            varchar,
+    "created_at"             timestamptz NOT NULL DEFAULT (now()),
+    "updated_at"             timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("buyer") REFERENCES "users" ("username");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("buyer_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("seller_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("escrow_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("funding_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("settlement_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("disputed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "escrow_agreements"
+    ADD FOREIGN KEY ("resolved_by") REFERENCES "users" ("username");
+
+ALTER TABLE "escrow_agreements"
+    ADD CONSTRAINT "escrow_agreement_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "escrow_agreements"
+    ADD CONSTRAINT "escrow_agreement_status_check" CHECK ("status" IN ('funded', 'disputed', 'released', 'refunded'));
+
+CREATE INDEX ON "escrow_agreements" ("buyer_account_id", "id");
+
+CREATE INDEX ON "escrow_agreements" ("seller_account_id", "id");
+
+CREATE INDEX ON "escrow_agreements" ("status", "deadline");
+
+COMMENT ON COLUMN "escrow_agreements"."condition" IS 'what the seller must do before the buyer releases the funds';
+
+COMMENT ON COLUMN "escrow_agreements"."status" IS 'funded until released to the seller or refunded to the buyer, disputed agreements wait for a banker';
+
+COMMENT ON COLUMN "escrow_agreements"."deadline" IS 'funds neither released nor disputed by then are refunded to the buyer';
+
+COMMENT ON COLUMN "escrow_agreements"."resolved_by" IS 'empty when the deadline refunded the buyer';
+
+-- house account holding the escrowed funds, nobody can log in with an empty password hash
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('houseescrow', '', 'Escrow', 'escrow@house.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type")
+VALUES ('houseescrow', 0, 'USD', 'escrow'),
+       ('houseescrow', 0, 'EUR', 'escrow'),
+       ('houseescrow', 0, 'CAD', 'escrow')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,94 @@
+-- the rows of the other tenants are dropped, the first tenant's data is kept
+DELETE FROM "escrow_agreements" WHERE "tenant_id" <> 1;
+DELETE FROM "payment_request_payers" WHERE "tenant_id" <> 1;
+DELETE FROM "payment_requests" WHERE "tenant_id" <> 1;
+DELETE FROM "beneficiaries" WHERE "tenant_id" <> 1;
+DELETE FROM "pending_transfer_events" WHERE "tenant_id" <> 1;
+DELETE FROM "pending_transfers" WHERE "tenant_id" <> 1;
+DELETE FROM "fraud_decisions" WHERE "tenant_id" <> 1;
+DELETE FROM "fraud_rules" WHERE "tenant_id" <> 1;
+DELETE FROM "transfer_limit_counters" WHERE "tenant_id" <> 1;
+DELETE FROM "transfer_limits" WHERE "tenant_id" <> 1;
+DELETE FROM "account_invitations" WHERE "tenant_id" <> 1;
+DELETE FROM "account_holders" WHERE "tenant_id" <> 1;
+DELETE FROM "interest_postings" WHERE "tenant_id" <> 1;
+DELETE FROM "interest_accruals" WHERE "tenant_id" <> 1;
+DELETE FROM "interest_rates" WHERE "tenant_id" <> 1;
+DELETE FROM "account_status_changes" WHERE "tenant_id" <> 1;
+DELETE FROM "chain_roots" WHERE "tenant_id" <> 1;
+DELETE FROM "reconciliation_findings" WHERE "tenant_id" <> 1;
+DELETE FROM "reconciliation_reports" WHERE "tenant_id" <> 1;
+DELETE FROM "balance_snapshots" WHERE "tenant_id" <> 1;
+DELETE FROM "holds" WHERE "tenant_id" <> 1;
+DELETE FROM "postings" WHERE "tenant_id" <> 1;
+DELETE FROM "journal_entries" WHERE "tenant_id" <> 1;
+DELETE FROM "entries" WHERE "tenant_id" <> 1;
+DELETE FROM "transfers" WHERE "tenant_id" <> 1;
+DELETE FROM "fee_rules" WHERE "tenant_id" <> 1;
+DELETE FROM "accounts" WHERE "tenant_id" <> 1;
+DELETE FROM "users" WHERE "tenant_id" <> 1;
+
+ALTER TABLE IF EXISTS "chain_roots"
+    DROP CONSTRAINT IF EXISTS "chain_roots_pkey";
+
+ALTER TABLE IF EXISTS "chain_roots"
+    ADD PRIMARY KEY ("day");
+
+ALTER TABLE IF EXISTS "fraud_rules"
+    DROP CONSTRAINT IF EXISTS "tenant_fraud_rule_name_key";
+
+ALTER TABLE IF EXISTS "fraud_rules"
+    ADD CONSTRAINT "fraud_rule_name_key" UNIQUE ("name");
+
+ALTER TABLE IF EXISTS "transfer_limits"
+    DROP CONSTRAINT IF EXISTS "tenant_scope_subject_currency_period_key";
+
+ALTER TABLE IF EXISTS "transfer_limits"
+    ADD CONSTRAINT "scope_subject_currency_period_key" UNIQUE ("scope", "subject", "currency", "period");
+
+ALTER TABLE IF EXISTS "interest_rates"
+    DROP CONSTRAINT IF EXISTS "tenant_currency_account_type_key";
+
+ALTER TABLE IF EXISTS "interest_rates"
+    ADD CONSTRAINT "currency_account_type_key" UNIQUE ("currency", "account_type");
+
+ALTER TABLE IF EXISTS "fee_rules"
+    DROP CONSTRAINT IF EXISTS "tenant_currency_min_amount_key";
+
+ALTER TABLE IF EXISTS "fee_rules"
+    ADD CONSTRAINT "currency_min_amount_key" UNIQUE ("currency", "min_amount");
+
+DROP INDEX IF EXISTS "accounts_tenant_id_owner_currency_type_idx";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_type_idx" ON "accounts" ("owner", "currency", "type")
+    WHERE "parent_id" IS NULL;
+
+DO
+$$
+    DECLARE
+        t text;
+    BEGIN
+        FOREACH t IN ARRAY ARRAY ['users', 'accounts', 'entries', 'transfers', 'holds', 'fee_rules',
+            'balance_snapshots', 'reconciliation_reports', 'reconciliation_findings', 'journal_entries',
+            'postings', 'chain_roots', 'account_status_changes', 'interest_rates', 'interest_accruals',
+            'interest_postings', 'account_holders', 'account_invitations', 'transfer_limits',
+            'transfer_limit_counters', 'fraud_rules', 'fraud_decisions', 'pending_transfers',
+            'pending_transfer_events', 'beneficiaries', 'payment_requests', 'payment_request_payers',
+            'escrow_agreements']
+            LOOP
+                EXECUTE format('DROP POLICY IF EXISTS "tenant_isolation" ON %I', t);
+                EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+                EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+                EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS "tenant_id"', t);
+            END LOOP;
+    END
+$$;
+
+-- revokes the grants and default privileges of the role before dropping it
+DROP OWNED BY "bank_tenant";
+
+DROP ROLE IF EXISTS "bank_tenant";
+
+DROP FUNCTION IF EXISTS "current_tenant_id"();
+
+DROP TABLE IF EXISTS "tenants";
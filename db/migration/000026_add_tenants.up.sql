@@ -0,0 +1,111 @@
+-- every brand the service runs for is a tenant, their rows are kept apart by row level security
+CREATE TABLE "tenants"
+(
+    "id"         bigserial PRIMARY KEY,
+    "slug"       varchar UNIQUE NOT NULL,
+    "name"       varchar        NOT NULL,
+    "host"       varchar UNIQUE,
+    "currencies" varchar[]      NOT NULL,
+    "created_at" timestamptz    NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "tenants"."host" IS 'the host name the brand is served on, empty when it is only reached with its tokens';
+
+COMMENT ON COLUMN "tenants"."currencies" IS 'the currencies accounts can be opened in';
+
+-- the existing data belongs to the first tenant
+INSERT INTO "tenants" ("id", "slug", "name", "currencies")
+VALUES (1, 'default', 'Default', '{USD,EUR,CAD}');
+
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), 1);
+
+-- the tenant of the transaction, set by the application with set_config('app.tenant_id', ..., true).
+-- Connections that never set it, like migrations, work on the first tenant.
+CREATE FUNCTION "current_tenant_id"() RETURNS bigint AS
+$$
+SELECT COALESCE(NULLIF(current_setting('app.tenant_id', true), ''), '1')::bigint
+$$ LANGUAGE sql STABLE;
+
+-- the application switches to this role in every transaction, superusers and table owners
+-- would otherwise skip the policies
+DO
+$$
+    BEGIN
+        IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'bank_tenant') THEN
+            CREATE ROLE "bank_tenant" NOLOGIN;
+        END IF;
+    END
+$$;
+
+GRANT "bank_tenant" TO CURRENT_USER;
+
+GRANT USAGE ON SCHEMA "public" TO "bank_tenant";
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA "public" TO "bank_tenant";
+
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA "public" TO "bank_tenant";
+
+ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO "bank_tenant";
+
+ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT USAGE, SELECT ON SEQUENCES TO "bank_tenant";
+
+-- new rows take the tenant of the transaction, and a transaction only sees and writes the rows of its tenant
+DO
+$$
+    DECLARE
+        t text;
+    BEGIN
+        FOREACH t IN ARRAY ARRAY ['users', 'accounts', 'entries', 'transfers', 'holds', 'fee_rules',
+            'balance_snapshots', 'reconciliation_reports', 'reconciliation_findings', 'journal_entries',
+            'postings', 'chain_roots', 'account_status_changes', 'interest_rates', 'interest_accruals',
+            'interest_postings', 'account_holders', 'account_invitations', 'transfer_limits',
+            'transfer_limit_counters', 'fraud_rules', 'fraud_decisions', 'pending_transfers',
+            'pending_transfer_events', 'beneficiaries', 'payment_requests', 'payment_request_payers',
+            'escrow_agreements']
+            LOOP
+                EXECUTE format('ALTER TABLE %I ADD COLUMN "tenant_id" bigint NOT NULL DEFAULT current_tenant_id()'
+                                   ' REFERENCES "tenants" ("id")', t);
+                EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+                EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+                EXECUTE format('CREATE POLICY "tenant_isolation" ON %I USING ("tenant_id" = current_tenant_id())'
+                                   ' WITH CHECK ("tenant_id" = current_tenant_id())', t);
+            END LOOP;
+    END
+$$;
+
+-- settings and house accounts are per tenant. Usernames, emails and aliases stay unique across tenants,
+-- the users are referenced by username everywhere.
+DROP INDEX "accounts_owner_currency_type_idx";
+
+CREATE UNIQUE INDEX "accounts_tenant_id_owner_currency_type_idx" ON "accounts" ("tenant_id", "owner", "currency", "type")
+    WHERE "parent_id" IS NULL;
+
+ALTER TABLE "fee_rules"
+    DROP CONSTRAINT "currency_min_amount_key";
+
+ALTER TABLE "fee_rules"
+    ADD CONSTRAINT "tenant_currency_min_amount_key" UNIQUE ("tenant_id", "currency", "min_amount");
+
+ALTER TABLE "interest_rates"
+    DROP CONSTRAINT "currency_account_type_key";
+
+ALTER TABLE "interest_rates"
+    ADD CONSTRAINT "tenant_currency_account_type_key" UNIQUE ("tenant_id", "currency", "account_type");
+
+ALTER TABLE "transfer_limits"
+    DROP CONSTRAINT "scope_subject_currency_period_key";
+
+ALTER TABLE "transfer_limits"
+    ADD CONSTRAINT "tenant_scope_subject_currency_period_key" UNIQUE ("tenant_id", "scope", "subject", "currency", "period");
+
+ALTER TABLE "fraud_rules"
+    DROP CONSTRAINT "fraud_rule_name_key";
+
+ALTER TABLE "fraud_rules"
+    ADD CONSTRAINT "tenant_fraud_rule_name_key" UNIQUE ("tenant_id", "name");
+
+ALTER TABLE "chain_roots"
+    DROP CONSTRAINT "chain_roots_pkey";
+
+ALTER TABLE "chain_roots"
+    ADD PRIMARY KEY ("tenant_id", "day");
@@ -0,0 +1,36 @@
+-- seal every chain again in the format of 000013, covering the entries alone,
+-- with created_at still in whole microseconds like time.UnixMicro
+DO
+$$
+    DECLARE
+        t    record;
+        e    record;
+        prev bytea;
+        acc  bigint;
+        h    bytea;
+    BEGIN
+        FOR t IN SELECT id FROM tenants ORDER BY id
+            LOOP
+                PERFORM set_config('app.tenant_id', t.id::text, true);
+                acc := NULL;
+
+                FOR e IN SELECT * FROM entries WHERE tenant_id = t.id ORDER BY account_id, id
+                    LOOP
+                        IF acc IS DISTINCT FROM e.account_id THEN
+                            acc := e.account_id;
+                            prev := NULL;
+                        END IF;
+
+                        h := sha256(convert_to(format('%s|%s|%s|%s|%s|', e.id, e.account_id, e.amount,
+                                                      COALESCE(e.transfer_id, 0),
+                                                      extract(epoch FROM date_trunc('second', e.created_at))::bigint * 1000000 +
+                                                      extract(microseconds FROM e.created_at)::bigint % 1000000), 'UTF8')
+                            || COALESCE(prev, ''::bytea));
+                        UPDATE entries SET prev_hash = prev, hash = h WHERE id = e.id;
+                        prev := h;
+                    END LOOP;
+            END LOOP;
+
+        PERFORM set_config('app.tenant_id', '', true);
+    END
+$$;
@@ -0,0 +1,60 @@
+-- entry hashes now cover the posting and the transfer behind each entry, see db.EntryHash.
+-- Every chain is sealed again in the new format, tenant by tenant as row level security only shows
+-- one tenant at a time. Chain roots signed before this migration cover the old hashes.
+-- created_at is taken in whole microseconds like time.UnixMicro, the float epoch 000013 sealed with rounds some of them.
+DO
+$$
+    DECLARE
+        t    record;
+        e    record;
+        prev bytea;
+        acc  bigint;
+        h    bytea;
+    BEGIN
+        FOR t IN SELECT id FROM tenants ORDER BY id
+            LOOP
+                PERFORM set_config('app.tenant_id', t.id::text, true);
+                acc := NULL;
+
+                FOR e IN SELECT en.id,
+                                en.account_id,
+                                en.amount,
+                                en.transfer_id,
+                                en.created_at,
+                                p.currency        AS posting_currency,
+                                p.account_id      AS posting_account_id,
+                                p.amount          AS posting_amount,
+                                tr.from_account_id,
+                                tr.to_account_id,
+                                tr.amount         AS transfer_amount
+                         FROM entries en
+                                  LEFT JOIN postings p ON p.entry_id = en.id
+                                  LEFT JOIN transfers tr ON tr.id = en.transfer_id
+                         WHERE en.tenant_id = t.id
+                         ORDER BY en.account_id, en.id
+                    LOOP
+                        IF acc IS DISTINCT FROM e.account_id THEN
+                            acc := e.account_id;
+                            prev := NULL;
+                        END IF;
+
+                        h := sha256(convert_to(format('%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|',
+                                                      e.id, e.account_id, e.amount,
+                                                      COALESCE(e.transfer_id, 0),
+                                                      extract(epoch FROM date_trunc('second', e.created_at))::bigint * 1000000 +
+                                                      extract(microseconds FROM e.created_at)::bigint % 1000000,
+                                                      COALESCE(e.posting_currency, ''),
+                                                      COALESCE(e.posting_account_id, 0),
+                                                      COALESCE(e.posting_amount, 0),
+                                                      COALESCE(e.from_account_id, 0),
+                                                      COALESCE(e.to_account_id, 0),
+                                                      COALESCE(e.transfer_amount, 0)), 'UTF8')
+                            || COALESCE(prev, ''::bytea));
+                        UPDATE entries SET prev_hash = prev, hash = h WHERE id = e.id;
+                        prev := h;
+                    END LOOP;
+            END LOOP;
+
+        PERFORM set_config('app.tenant_id', '', true);
+    END
+$$;
@@ -0,0 +1,4 @@
+ALTER TABLE "holds"
+    ADD COLUMN "screening" jsonb NOT NULL DEFAULT 'null';
+
+COMMENT ON COLUMN "holds"."screening" IS 'the fraud assessment that allowed the hold, recorded with the transfer capturing it';
@@ -0,0 +1,85 @@
+-- usernames go back to being unique across tenants. The house users of the other tenants are dropped,
+-- their house accounts belong to the first tenant's again; any other name taken in several tenants fails the migration.
+ALTER TABLE IF EXISTS "transfer_limit_counters"
+    DROP CONSTRAINT IF EXISTS "transfer_limit_counters_pkey";
+
+ALTER TABLE IF EXISTS "transfer_limit_counters"
+    ADD PRIMARY KEY ("scope", "subject", "currency", "period", "window_start");
+
+DROP INDEX IF EXISTS "beneficiaries_tenant_id_owner_account_id_idx";
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "account_id");
+
+DROP INDEX IF EXISTS "beneficiaries_tenant_id_owner_nickname_idx";
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "nickname");
+
+DO
+$$
+    DECLARE
+        ref text;
+    BEGIN
+        FOREACH ref IN ARRAY ARRAY ['accounts.owner', 'reconciliation_reports.reviewed_by',
+            'account_status_changes.actor', 'account_holders.username', 'account_invitations.invitee',
+            'account_invitations.invited_by', 'fraud_decisions.reviewed_by', 'pending_transfers.maker',
+            'pending_transfer_events.actor', 'beneficiaries.owner', 'payment_requests.requester',
+            'payment_request_payers.payer', 'escrow_agreements.buyer', 'escrow_agreements.disputed_by',
+            'escrow_agreements.resolved_by']
+            LOOP
+                EXECUTE format('ALTER TABLE %I DROP CONSTRAINT IF EXISTS %I', split_part(ref, '.', 1),
+                               split_part(ref, '.', 1) || '_tenant_id_' || split_part(ref, '.', 2) || '_fkey');
+            END LOOP;
+    END
+$$;
+
+DO
+$$
+    DECLARE
+        t record;
+    BEGIN
+        FOR t IN SELECT id FROM tenants WHERE id <> 1 ORDER BY id
+            LOOP
+                PERFORM set_config('app.tenant_id', t.id::text, true);
+
+                DELETE FROM "users" WHERE "username" IN ('housefees', 'houseinterest', 'houseescrow');
+            END LOOP;
+
+        PERFORM set_config('app.tenant_id', '', true);
+    END
+$$;
+
+DROP INDEX IF EXISTS "users_tenant_id_alias_idx";
+
+CREATE UNIQUE INDEX "users_alias_idx" ON "users" (lower("alias"));
+
+COMMENT ON COLUMN "users"."alias" IS 'chosen by the user to be paid by, unique regardless of case';
+
+ALTER TABLE IF EXISTS "users"
+    DROP CONSTRAINT IF EXISTS "users_tenant_id_email_key";
+
+ALTER TABLE IF EXISTS "users"
+    ADD CONSTRAINT "users_email_key" UNIQUE ("email");
+
+ALTER TABLE IF EXISTS "users"
+    DROP CONSTRAINT IF EXISTS "users_pkey";
+
+ALTER TABLE IF EXISTS "users"
+    ADD PRIMARY KEY ("username");
+
+DO
+$$
+    DECLARE
+        ref text;
+    BEGIN
+        FOREACH ref IN ARRAY ARRAY ['accounts.owner', 'reconciliation_reports.reviewed_by',
+            'account_status_changes.actor', 'account_holders.username', 'account_invitations.invitee',
+            'account_invitations.invited_by', 'fraud_decisions.reviewed_by', 'pending_transfers.maker',
+            'pending_transfer_events.actor', 'beneficiaries.owner', 'payment_requests.requester',
+            'payment_request_payers.payer', 'escrow_agreements.buyer', 'escrow_agreements.disputed_by',
+            'escrow_agreements.resolved_by']
+            LOOP
+                EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (%I) REFERENCES "users" ("username")',
+                               split_part(ref, '.', 1), split_part(ref, '.', 2));
+            END LOOP;
+    END
+$$;
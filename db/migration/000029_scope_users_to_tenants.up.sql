@@ -0,0 +1,92 @@
+-- usernames, emails and aliases are unique per tenant, so a name taken in one tenant says nothing to another.
+-- Users are still referenced by username, within the tenant of the referencing row.
+DO
+$$
+    DECLARE
+        ref text;
+    BEGIN
+        FOREACH ref IN ARRAY ARRAY ['accounts.owner', 'reconciliation_reports.reviewed_by',
+            'account_status_changes.actor', 'account_holders.username', 'account_invitations.invitee',
+            'account_invitations.invited_by', 'fraud_decisions.reviewed_by', 'pending_transfers.maker',
+            'pending_transfer_events.actor', 'beneficiaries.owner', 'payment_requests.requester',
+            'payment_request_payers.payer', 'escrow_agreements.buyer', 'escrow_agreements.disputed_by',
+            'escrow_agreements.resolved_by']
+            LOOP
+                EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', split_part(ref, '.', 1),
+                               split_part(ref, '.', 1) || '_' || split_part(ref, '.', 2) || '_fkey');
+            END LOOP;
+    END
+$$;
+
+ALTER TABLE "users"
+    DROP CONSTRAINT "users_pkey";
+
+ALTER TABLE "users"
+    ADD PRIMARY KEY ("tenant_id", "username");
+
+ALTER TABLE "users"
+    DROP CONSTRAINT "users_email_key";
+
+ALTER TABLE "users"
+    ADD CONSTRAINT "users_tenant_id_email_key" UNIQUE ("tenant_id", "email");
+
+DROP INDEX "users_alias_idx";
+
+CREATE UNIQUE INDEX "users_tenant_id_alias_idx" ON "users" ("tenant_id", lower("alias"));
+
+COMMENT ON COLUMN "users"."alias" IS 'chosen by the user to be paid by, unique in the tenant regardless of case';
+
+-- the house accounts of every tenant belong to house users of their own,
+-- inserted tenant by tenant as row level security only shows one tenant at a time
+DO
+$$
+    DECLARE
+        t record;
+    BEGIN
+        FOR t IN SELECT id FROM tenants WHERE id <> 1 ORDER BY id
+            LOOP
+                PERFORM set_config('app.tenant_id', t.id::text, true);
+
+                INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+                VALUES ('housefees', '', 'Fee revenue', 'fees@house.internal'),
+                       ('houseinterest', '', 'Interest expense', 'interest@house.internal'),
+                       ('houseescrow', '', 'Escrow', 'escrow@house.internal')
+                ON CONFLICT DO NOTHING;
+            END LOOP;
+
+        PERFORM set_config('app.tenant_id', '', true);
+    END
+$$;
+
+DO
+$$
+    DECLARE
+        ref text;
+    BEGIN
+        FOREACH ref IN ARRAY ARRAY ['accounts.owner', 'reconciliation_reports.reviewed_by',
+            'account_status_changes.actor', 'account_holders.username', 'account_invitations.invitee',
+            'account_invitations.invited_by', 'fraud_decisions.reviewed_by', 'pending_transfers.maker',
+            'pending_transfer_events.actor', 'beneficiaries.owner', 'payment_requests.requester',
+            'payment_request_payers.payer', 'escrow_agreements.buyer', 'escrow_agreements.disputed_by',
+            'escrow_agreements.resolved_by']
+            LOOP
+                EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY ("tenant_id", %I) REFERENCES "users" ("tenant_id", "username")',
+                               split_part(ref, '.', 1), split_part(ref, '.', 2));
+            END LOOP;
+    END
+$$;
+
+-- the rows keyed by username are keyed by tenant as well
+DROP INDEX "beneficiaries_owner_nickname_idx";
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("tenant_id", "owner", "nickname");
+
+DROP INDEX "beneficiaries_owner_account_id_idx";
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("tenant_id", "owner", "account_id");
+
+ALTER TABLE "transfer_limit_counters"
+    DROP CONSTRAINT "transfer_limit_counters_pkey";
+
+ALTER TABLE "transfer_limit_counters"
+    ADD PRIMARY KEY ("tenant_id", "scope", "subject", "currency", "period", "window_start");
@@ -0,0 +1,26 @@
+ALTER TABLE "holds"
+    ADD COLUMN "purpose" varchar NOT NULL DEFAULT 'payment';
+
+ALTER TABLE "holds"
+    ADD CONSTRAINT "hold_purpose_check" CHECK ("purpose" IN ('payment', 'approval'));
+
+COMMENT ON COLUMN "holds"."purpose" IS 'payment for holds captured by their recipient, approval for the ones reserving a transfer waiting for approval';
+
+-- marked tenant by tenant as row level security only shows one tenant at a time
+DO
+$$
+    DECLARE
+        t record;
+    BEGIN
+        FOR t IN SELECT id FROM tenants ORDER BY id
+            LOOP
+                PERFORM set_config('app.tenant_id', t.id::text, true);
+
+                UPDATE "holds"
+                SET "purpose" = 'approval'
+                WHERE "id" IN (SELECT "hold_id" FROM "pending_transfers");
+            END LOOP;
+
+        PERFORM set_config('app.tenant_id', '', true);
+    END
+$$;
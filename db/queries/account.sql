@@ -1,22 +1,80 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
-RETURNING *;
+-- the type defaults to checking, the owner becomes the primary holder
+WITH account AS (
+    INSERT INTO accounts (owner, balance, currency, type)
+        VALUES (sqlc.arg(owner), sqlc.arg(balance), sqlc.arg(currency), COALESCE(sqlc.narg(type), 'checking'))
+        RETURNING *),
+     holder AS (
+         INSERT INTO account_holders (account_id, username, role)
+             SELECT id, owner, 'primary' FROM account)
+SELECT *
+FROM account;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND parent_id IS NULL
+ORDER BY id LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
--- name: ListAccounts :many
+-- name: CreatePot :one
+-- the pot takes the owner and currency of its parent, and its type unless one is given
+INSERT INTO accounts (owner, balance, currency, type, nickname, parent_id)
+SELECT owner, 0, currency, COALESCE(sqlc.narg(type), type), sqlc.arg(name), id
+FROM accounts
+WHERE id = sqlc.arg(parent_id)
+RETURNING *;
+
+-- name: ListPots :many
+SELECT * FROM accounts
+WHERE parent_id = sqlc.arg(parent_id)::bigint
+ORDER BY id;
+
+-- name: ListPotsForUpdate :many
+-- pots are always created after their parent, so locking them after it keeps the ascending id order
 SELECT * FROM accounts
+WHERE parent_id = sqlc.arg(parent_id)::bigint
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+FOR NO KEY UPDATE;
+
+-- name: ListAccounts :many
+-- every filter is optional, sort is one of balance, created_at or id and ties are broken by id
+SELECT * FROM accounts
+WHERE (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(min_balance)::bigint IS NULL OR balance >= sqlc.narg(min_balance))
+  AND (sqlc.narg(max_balance)::bigint IS NULL OR balance <= sqlc.narg(max_balance))
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY CASE WHEN sqlc.arg(sort)::varchar = 'balance' AND NOT sqlc.arg(descending)::bool THEN balance END,
+         CASE WHEN sqlc.arg(sort) = 'balance' AND sqlc.arg(descending) THEN balance END DESC,
+         CASE WHEN sqlc.arg(sort) = 'created_at' AND NOT sqlc.arg(descending) THEN created_at END,
+         CASE WHEN sqlc.arg(sort) = 'created_at' AND sqlc.arg(descending) THEN created_at END DESC,
+         CASE WHEN sqlc.arg(descending) THEN id END DESC,
+         id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListAccountsAfter :many
+-- keyset page ordered by (created_at, id), starting from the beginning without a cursor
+SELECT * FROM accounts
+WHERE (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(min_balance)::bigint IS NULL OR balance >= sqlc.narg(min_balance))
+  AND (sqlc.narg(max_balance)::bigint IS NULL OR balance <= sqlc.narg(max_balance))
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -24,12 +82,45 @@ SET balance = $2
 WHERE id = $1
 RETURNING *;
 
-
 -- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1;
+-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ResetAccountBalanceToEntries :one
+-- lock the account first, so the sum sees every entry committed before the lock
+UPDATE accounts
+SET balance = (SELECT COALESCE(SUM(e.amount), 0) FROM entries e WHERE e.account_id = sqlc.arg(id))
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountDetails :one
+-- only the given attributes change
+UPDATE accounts
+SET nickname        = COALESCE(sqlc.narg(nickname), nickname),
+    overdraft_limit = COALESCE(sqlc.narg(overdraft_limit), overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetPayeeAccount :one
+-- the account payments to the owner land on: an active top level account in the currency, checking first
+SELECT * FROM accounts
+WHERE owner = $1
+  AND currency = $2
+  AND parent_id IS NULL
+  AND status = 'active'
+ORDER BY type = 'checking' DESC, id
+LIMIT 1;
@@ -0,0 +1,55 @@
+-- name: CreateAccountHolder :one
+INSERT INTO account_holders (account_id, username, role)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetAccountHolder :one
+SELECT *
+FROM account_holders
+WHERE account_id = $1
+  AND username = $2
+LIMIT 1;
+
+-- name: ListAccountHolders :many
+SELECT *
+FROM account_holders
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: DeleteAccountHolder :exec
+DELETE
+FROM account_holders
+WHERE account_id = $1
+  AND username = $2;
+
+-- name: CreateAccountInvitation :one
+INSERT INTO account_invitations (account_id, invitee, role, invited_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetAccountInvitation :one
+SELECT *
+FROM account_invitations
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetAccountInvitationForUpdate :one
+SELECT *
+FROM account_invitations
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingAccountInvitations :many
+SELECT *
+FROM account_invitations
+WHERE invitee = $1
+  AND status = 'pending'
+ORDER BY created_at;
+
+-- name: RespondAccountInvitation :one
+UPDATE account_invitations
+SET status       = $2,
+    responded_at = now()
+WHERE id = $1
+RETURNING *;
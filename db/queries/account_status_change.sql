@@ -0,0 +1,10 @@
+-- name: CreateAccountStatusChange :one
+INSERT INTO account_status_changes (account_id, from_status, to_status, actor, reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAccountStatusChanges :many
+SELECT *
+FROM account_status_changes
+WHERE account_id = $1
+ORDER BY id;
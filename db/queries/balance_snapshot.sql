@@ -0,0 +1,25 @@
+-- name: CreateBalanceSnapshots :execrows
+-- snapshots every account that existed at snapshot_at, running it again for the same instant is a no-op
+-- snapshot_at must be older than any open transaction, or entries dated before it could still commit after the snapshot
+INSERT INTO balance_snapshots (account_id, snapshot_at, balance)
+SELECT a.id, sqlc.arg(snapshot_at), a.balance - COALESCE(SUM(e.amount), 0)
+FROM accounts a
+         LEFT JOIN entries e ON e.account_id = a.id AND e.created_at > sqlc.arg(snapshot_at)
+WHERE a.created_at <= sqlc.arg(snapshot_at)
+GROUP BY a.id
+ON CONFLICT (account_id, snapshot_at) DO NOTHING;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT *
+FROM balance_snapshots
+WHERE account_id = sqlc.arg(account_id)
+  AND snapshot_at <= sqlc.arg(as_of)
+ORDER BY snapshot_at DESC
+LIMIT 1;
+
+-- name: SumEntriesBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at > sqlc.arg(after)
+  AND created_at <= sqlc.arg(until);
@@ -0,0 +1,36 @@
+-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (owner, nickname, account_id, currency, cooling_off_until)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetBeneficiary :one
+SELECT *
+FROM beneficiaries
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetBeneficiaryByAccount :one
+SELECT *
+FROM beneficiaries
+WHERE owner = $1
+  AND account_id = $2
+LIMIT 1;
+
+-- name: ListBeneficiaries :many
+SELECT *
+FROM beneficiaries
+WHERE owner = $1
+ORDER BY nickname
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateBeneficiary :one
+-- only the nickname changes, another account is another beneficiary with its own cooling-off
+UPDATE beneficiaries
+SET nickname = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBeneficiary :exec
+DELETE
+FROM beneficiaries
+WHERE id = $1;
@@ -0,0 +1,10 @@
+-- name: CreateChainRoot :execrows
+INSERT INTO chain_roots (day, root_hash, signature)
+VALUES ($1, $2, $3)
+ON CONFLICT (tenant_id, day) DO NOTHING;
+
+-- name: ListChainRoots :many
+SELECT *
+FROM chain_roots
+ORDER BY day DESC
+LIMIT $1 OFFSET $2;
@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id, amount)
-VALUES ($1, $2)
+INSERT INTO entries (account_id, amount, transfer_id)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -14,4 +14,88 @@ SELECT *
 FROM entries
 WHERE account_id = $1
 ORDER BY id
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- name: ListAccountEntries :many
+-- running_balance is the account balance right after the entry, counted back from the current balance.
+-- A transfer posts its fee on the sender after the principal, the fee's counterparty is the revenue account it credits.
+WITH history AS (
+    SELECT e.*,
+           a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount AS running_balance,
+           CASE
+               WHEN t.from_account_id = e.account_id AND ROW_NUMBER() OVER (PARTITION BY e.transfer_id ORDER BY e.id) > 1
+                   THEN (SELECT r.account_id
+                         FROM entries r
+                         WHERE r.transfer_id = t.id
+                           AND r.account_id NOT IN (t.from_account_id, t.to_account_id))
+               WHEN t.from_account_id = e.account_id THEN t.to_account_id
+               ELSE t.from_account_id END                                                 AS counterparty_account_id
+    FROM entries e
+             JOIN accounts a ON a.id = e.account_id
+             LEFT JOIN transfers t ON t.id = e.transfer_id
+    WHERE e.account_id = sqlc.arg(account_id)
+)
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id, running_balance::bigint, counterparty_account_id
+FROM history
+WHERE (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(direction)::varchar IS NULL
+    OR (sqlc.narg(direction) = 'in' AND amount > 0)
+    OR (sqlc.narg(direction) = 'out' AND amount < 0))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR counterparty_account_id = sqlc.narg(counterparty_account_id))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetAccountBalanceAsOf :one
+-- full scan: the current balance minus every entry created after as_of
+SELECT (a.balance - COALESCE((SELECT SUM(e.amount)
+                              FROM entries e
+                              WHERE e.account_id = a.id
+                                AND e.created_at > sqlc.arg(as_of)), 0))::bigint AS balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: GetPreviousEntryHash :one
+-- the account must be locked, so no other entry can be chained in between
+SELECT hash
+FROM entries
+WHERE account_id = $1
+  AND id < $2
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: SealEntry :one
+UPDATE entries
+SET prev_hash = $2,
+    hash      = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ListChainEntries :many
+-- walks the entries account by account, in the order they were chained,
+-- along with the posting and transfer fields their hash covers
+SELECT e.*,
+       COALESCE(p.currency, '')::varchar        AS posting_currency,
+       COALESCE(p.account_id, 0)::bigint        AS posting_account_id,
+       COALESCE(p.amount, 0)::bigint            AS posting_amount,
+       COALESCE(t.from_account_id, 0)::bigint   AS transfer_from_account_id,
+       COALESCE(t.to_account_id, 0)::bigint     AS transfer_to_account_id,
+       COALESCE(t.amount, 0)::bigint            AS transfer_amount
+FROM entries e
+         LEFT JOIN postings p ON p.entry_id = e.id
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE (sqlc.narg(account_id)::bigint IS NULL OR e.account_id = sqlc.narg(account_id))
+  AND (e.account_id, e.id) > (sqlc.arg(after_account_id)::bigint, sqlc.arg(after_id)::bigint)
+ORDER BY e.account_id, e.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListLastEntryHashes :many
+-- the chain head of every account at the given instant
+SELECT DISTINCT ON (account_id) account_id, hash
+FROM entries
+WHERE created_at < sqlc.arg(until)
+ORDER BY account_id, id DESC;
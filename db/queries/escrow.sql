@@ -0,0 +1,62 @@
+-- name: CreateEscrowAgreement :one
+INSERT INTO escrow_agreements (buyer, buyer_account_id, seller_account_id, escrow_account_id, amount, currency,
+                               condition, deadline)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetEscrowAgreement :one
+SELECT *
+FROM escrow_agreements
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetEscrowAgreementForUpdate :one
+SELECT *
+FROM escrow_agreements
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListEscrowAgreements :many
+-- agreements the account is the buyer or the seller of, the status filter is optional, newest first
+SELECT *
+FROM escrow_agreements
+WHERE (buyer_account_id = sqlc.arg(account_id) OR seller_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListOverdueEscrowAgreements :many
+SELECT *
+FROM escrow_agreements
+WHERE status = 'funded'
+  AND deadline <= now()
+ORDER BY deadline
+LIMIT $1;
+
+-- name: SetEscrowFundingTransfer :one
+UPDATE escrow_agreements
+SET funding_transfer_id = $2,
+    updated_at          = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DisputeEscrowAgreement :one
+UPDATE escrow_agreements
+SET status         = 'disputed',
+    disputed_by    = $2,
+    dispute_reason = $3,
+    updated_at     = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SettleEscrowAgreement :one
+-- status is released or refunded, resolved_by is empty when the deadline refunded the buyer
+UPDATE escrow_agreements
+SET status                 = sqlc.arg(status),
+    settlement_transfer_id = sqlc.arg(settlement_transfer_id),
+    resolved_by            = sqlc.narg(resolved_by),
+    updated_at             = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
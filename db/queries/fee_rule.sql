@@ -0,0 +1,23 @@
+-- name: UpsertFeeRule :one
+INSERT INTO fee_rules (currency, min_amount, flat_fee, percentage_bps)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tenant_id, currency, min_amount) DO UPDATE
+    SET flat_fee       = EXCLUDED.flat_fee,
+        percentage_bps = EXCLUDED.percentage_bps
+RETURNING *;
+
+-- name: GetFeeRule :one
+SELECT *
+FROM fee_rules
+WHERE currency = sqlc.arg(currency)
+  AND min_amount <= sqlc.arg(amount)
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: ListFeeRules :many
+SELECT *
+FROM fee_rules
+ORDER BY currency, min_amount;
+
+-- name: DeleteFeeRule :exec
+DELETE FROM fee_rules WHERE id = $1;
\ No newline at end of file
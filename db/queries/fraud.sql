@@ -0,0 +1,80 @@
+-- name: UpsertFraudRule :one
+INSERT INTO fraud_rules (name, kind, action, score, currency, min_amount, min_count, window_seconds, round_to, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (tenant_id, name) DO UPDATE
+    SET kind           = EXCLUDED.kind,
+        action         = EXCLUDED.action,
+        score          = EXCLUDED.score,
+        currency       = EXCLUDED.currency,
+        min_amount     = EXCLUDED.min_amount,
+        min_count      = EXCLUDED.min_count,
+        window_seconds = EXCLUDED.window_seconds,
+        round_to       = EXCLUDED.round_to,
+        enabled        = EXCLUDED.enabled
+RETURNING *;
+
+-- name: ListFraudRules :many
+SELECT *
+FROM fraud_rules
+ORDER BY name;
+
+-- name: DeleteFraudRule :exec
+DELETE FROM fraud_rules WHERE id = $1;
+
+-- name: CountTransfersBetween :one
+SELECT COUNT(*)
+FROM transfers
+WHERE from_account_id = $1
+  AND to_account_id = $2;
+
+-- name: CountTransfersSince :one
+SELECT COUNT(*)
+FROM transfers
+WHERE from_account_id = $1
+  AND created_at >= $2;
+
+-- name: CountRoundTransfersSince :one
+-- round transfers are multiples of round_to
+SELECT COUNT(*)
+FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND created_at >= sqlc.arg(since)
+  AND amount % sqlc.arg(round_to)::bigint = 0;
+
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (from_account_id, to_account_id, amount, currency, decision, score, matched_rules, request,
+                             review_status, transfer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetFraudDecision :one
+SELECT *
+FROM fraud_decisions
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetFraudDecisionForUpdate :one
+SELECT *
+FROM fraud_decisions
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListFraudDecisions :many
+-- both filters are optional, newest first
+SELECT *
+FROM fraud_decisions
+WHERE (sqlc.narg(decision)::varchar IS NULL OR decision = sqlc.narg(decision))
+  AND (sqlc.narg(review_status)::varchar IS NULL OR review_status = sqlc.narg(review_status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CompleteFraudReview :one
+UPDATE fraud_decisions
+SET review_status = sqlc.arg(review_status),
+    reviewed_by   = sqlc.arg(reviewed_by),
+    reviewed_at   = now(),
+    transfer_id   = sqlc.narg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,35 @@
+-- name: CreateHold :one
+INSERT INTO holds (from_account_id, to_account_id, amount, expires_at, screening, purpose)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListExpiredHolds :many
+SELECT *
+FROM holds
+WHERE status = 'pending'
+  AND purpose = 'payment'
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET status          = $2,
+    captured_amount = $3,
+    transfer_id     = $4,
+    updated_at      = now()
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,93 @@
+-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (currency, account_type, annual_rate_bps)
+VALUES ($1, $2, $3)
+ON CONFLICT (tenant_id, currency, account_type) DO UPDATE
+    SET annual_rate_bps = EXCLUDED.annual_rate_bps
+RETURNING *;
+
+-- name: GetInterestRate :one
+SELECT *
+FROM interest_rates
+WHERE currency = $1
+  AND account_type = $2
+LIMIT 1;
+
+-- name: ListInterestRates :many
+SELECT *
+FROM interest_rates
+ORDER BY currency, account_type;
+
+-- name: ListAccountsToAccrue :many
+-- open customer accounts earning interest with days left to accrue up to the given date, and the first of them:
+-- the day after their last accrual, and not before the UTC day the account was opened or the rate was set
+SELECT a.id AS account_id,
+       GREATEST(MAX(i.accrual_date) + 1,
+                (a.created_at AT TIME ZONE 'UTC')::date,
+                (r.created_at AT TIME ZONE 'UTC')::date)::date AS next_accrual_date
+FROM accounts a
+         JOIN interest_rates r ON r.currency = a.currency AND r.account_type = a.type
+         LEFT JOIN interest_accruals i ON i.account_id = a.id
+WHERE r.annual_rate_bps > 0
+  AND a.status <> 'closed'
+  AND a.owner NOT IN ('housefees', 'houseinterest')
+GROUP BY a.id, a.created_at, r.created_at
+HAVING GREATEST(MAX(i.accrual_date) + 1,
+                (a.created_at AT TIME ZONE 'UTC')::date,
+                (r.created_at AT TIME ZONE 'UTC')::date) <= sqlc.arg(accrual_date)::date
+ORDER BY a.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetInterestAccrual :one
+SELECT *
+FROM interest_accruals
+WHERE account_id = $1
+  AND accrual_date = $2
+LIMIT 1;
+
+-- name: GetPreviousInterestAccrual :one
+-- the accrual whose carry rolls into the given date
+SELECT *
+FROM interest_accruals
+WHERE account_id = $1
+  AND accrual_date < $2
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (account_id, accrual_date, balance, annual_rate_bps, amount, carry)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListUnpostedInterestPeriods :many
+-- months before the given date with accruals not credited yet, only active accounts can be credited
+SELECT i.account_id, date_trunc('month', i.accrual_date)::date AS period
+FROM interest_accruals i
+         JOIN accounts a ON a.id = i.account_id
+WHERE i.accrual_date < sqlc.arg(before)
+  AND a.status = 'active'
+  AND NOT EXISTS(SELECT 1
+                 FROM interest_postings p
+                 WHERE p.account_id = i.account_id
+                   AND p.period = date_trunc('month', i.accrual_date)::date)
+GROUP BY i.account_id, period
+ORDER BY period, i.account_id
+LIMIT sqlc.arg('limit');
+
+-- name: SumInterestAccruals :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date >= sqlc.arg(period_start)
+  AND accrual_date < sqlc.arg(period_end);
+
+-- name: GetInterestPosting :one
+SELECT *
+FROM interest_postings
+WHERE account_id = $1
+  AND period = $2
+LIMIT 1;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (account_id, period, amount, transfer_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
@@ -0,0 +1,21 @@
+-- name: CreateJournalEntry :one
+INSERT INTO journal_entries (transfer_id)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetJournalEntryByTransfer :one
+SELECT *
+FROM journal_entries
+WHERE transfer_id = $1
+LIMIT 1;
+
+-- name: CreatePosting :one
+INSERT INTO postings (journal_entry_id, account_id, entry_id, currency, amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListPostings :many
+SELECT *
+FROM postings
+WHERE journal_entry_id = $1
+ORDER BY id;
@@ -0,0 +1,87 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (requester, to_account_id, amount, currency, description, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT *
+FROM payment_requests
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT *
+FROM payment_requests
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPaymentRequestsByRequester :many
+-- requests the user made, the status filter is optional, newest first
+SELECT *
+FROM payment_requests
+WHERE requester = sqlc.arg(requester)
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListPaymentRequestsByPayer :many
+-- requests the user is asked to pay a share of, the status filter is optional, newest first
+SELECT *
+FROM payment_requests
+WHERE id IN (SELECT payment_request_id FROM payment_request_payers WHERE payer = sqlc.arg(payer))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListExpiredPaymentRequests :many
+SELECT *
+FROM payment_requests
+WHERE status = 'pending'
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: UpdatePaymentRequestStatus :one
+UPDATE payment_requests
+SET status     = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreatePaymentRequestPayer :one
+INSERT INTO payment_request_payers (payment_request_id, payer, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListPaymentRequestPayers :many
+SELECT *
+FROM payment_request_payers
+WHERE payment_request_id = $1
+ORDER BY id;
+
+-- name: GetPaymentRequestPayerForUpdate :one
+SELECT *
+FROM payment_request_payers
+WHERE payment_request_id = $1
+  AND payer = $2
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdatePaymentRequestPayer :one
+UPDATE payment_request_payers
+SET status      = sqlc.arg(status),
+    transfer_id = sqlc.narg(transfer_id),
+    updated_at  = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpirePaymentRequestPayers :exec
+-- the shares still unanswered expire along with their request
+UPDATE payment_request_payers
+SET status     = 'expired',
+    updated_at = now()
+WHERE payment_request_id = $1
+  AND status = 'pending';
@@ -0,0 +1,61 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (from_account_id, to_account_id, amount, currency, request, screening, maker, hold_id,
+                               expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetPendingTransferByTransfer :one
+SELECT *
+FROM pending_transfers
+WHERE transfer_id = $1
+LIMIT 1;
+
+-- name: ListPendingTransfers :many
+-- transfers from the account, the status filter is optional, newest first
+SELECT *
+FROM pending_transfers
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListExpiredPendingTransfers :many
+SELECT *
+FROM pending_transfers
+WHERE status = 'pending'
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: UpdatePendingTransferStatus :one
+UPDATE pending_transfers
+SET status      = sqlc.arg(status),
+    transfer_id = sqlc.narg(transfer_id),
+    updated_at  = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreatePendingTransferEvent :one
+INSERT INTO pending_transfer_events (pending_transfer_id, action, actor, note)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListPendingTransferEvents :many
+SELECT *
+FROM pending_transfer_events
+WHERE pending_transfer_id = $1
+ORDER BY id;
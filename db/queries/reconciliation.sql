@@ -0,0 +1,78 @@
+-- name: ListBalanceDrifts :many
+-- accounts whose balance is not the sum of their entries
+SELECT a.id AS account_id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+         LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListOrphanEntries :many
+-- entries that don't belong to any transfer
+SELECT e.*
+FROM entries e
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE t.id IS NULL
+ORDER BY e.id;
+
+-- name: ListUnbalancedTransfers :many
+-- transfers whose entries don't debit and credit exactly the amount plus fees
+SELECT t.id                                                           AS transfer_id,
+       (t.amount + t.flat_fee + t.percentage_fee)::bigint             AS expected,
+       COALESCE(SUM(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint AS credits,
+       COALESCE(-SUM(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint AS debits
+FROM transfers t
+         LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COALESCE(SUM(e.amount) FILTER (WHERE e.amount > 0), 0) <> t.amount + t.flat_fee + t.percentage_fee
+    OR COALESCE(-SUM(e.amount) FILTER (WHERE e.amount < 0), 0) <> t.amount + t.flat_fee + t.percentage_fee
+ORDER BY t.id;
+
+-- name: CreateReconciliationReport :one
+INSERT INTO reconciliation_reports (status, findings_count)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetReconciliationReport :one
+SELECT *
+FROM reconciliation_reports
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetReconciliationReportForUpdate :one
+SELECT *
+FROM reconciliation_reports
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListReconciliationReports :many
+SELECT *
+FROM reconciliation_reports
+ORDER BY id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ReviewReconciliationReport :one
+UPDATE reconciliation_reports
+SET status      = $2,
+    reviewed_by = $3,
+    reviewed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateReconciliationFinding :one
+INSERT INTO reconciliation_findings (report_id, kind, account_id, entry_id, transfer_id, expected, actual)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListReconciliationFindings :many
+SELECT *
+FROM reconciliation_findings
+WHERE report_id = $1
+ORDER BY id;
+
+-- name: MarkReconciliationFindingRepaired :one
+UPDATE reconciliation_findings
+SET repaired_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,35 @@
+-- name: CreateTenant :one
+INSERT INTO tenants (slug, name, host, currencies)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTenant :one
+SELECT *
+FROM tenants
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetTenantByHost :one
+SELECT *
+FROM tenants
+WHERE host = $1
+LIMIT 1;
+
+-- name: GetTenantBySlug :one
+SELECT *
+FROM tenants
+WHERE slug = $1
+LIMIT 1;
+
+-- name: ListTenants :many
+SELECT *
+FROM tenants
+ORDER BY id;
+
+-- name: UpdateTenant :one
+UPDATE tenants
+SET name       = COALESCE(sqlc.narg(name), name),
+    host       = COALESCE(sqlc.narg(host), host),
+    currencies = COALESCE(sqlc.narg(currencies), currencies)
+WHERE id = sqlc.arg(id)
+RETURNING *;
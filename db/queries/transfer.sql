@@ -1,6 +1,7 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
+INSERT INTO transfers (from_account_id, to_account_id, amount, original_transfer_id, fee_rule_id, flat_fee, percentage_fee,
+                       description, external_reference, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: GetTransfer :one
@@ -9,10 +10,62 @@ FROM transfers
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetTransferByExternalReference :one
+SELECT *
+FROM transfers
+WHERE from_account_id = $1
+  AND external_reference = $2
+LIMIT 1;
+
+-- name: GetTransferForUpdate :one
+SELECT *
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE original_transfer_id = $1;
+
 -- name: ListTransfers :many
 SELECT *
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
 ORDER BY id
-LIMIT $3 OFFSET $4;
\ No newline at end of file
+LIMIT $3 OFFSET $4;
+
+-- name: ListAccountTransfers :many
+-- running_balance is the account balance right after the transfer and its fee
+WITH history AS (
+    SELECT e.id,
+           e.transfer_id,
+           a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount AS running_balance
+    FROM entries e
+             JOIN accounts a ON a.id = e.account_id
+    WHERE e.account_id = sqlc.arg(account_id)
+)
+SELECT t.*,
+       (SELECT h.running_balance
+        FROM history h
+        WHERE h.transfer_id = t.id
+        ORDER BY h.id DESC
+        LIMIT 1)::bigint AS running_balance
+FROM transfers t
+WHERE (t.from_account_id = sqlc.arg(account_id) OR t.to_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(direction)::varchar IS NULL
+    OR (sqlc.narg(direction) = 'in' AND t.to_account_id = sqlc.arg(account_id))
+    OR (sqlc.narg(direction) = 'out' AND t.from_account_id = sqlc.arg(account_id)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL
+    OR (t.from_account_id = sqlc.narg(counterparty_account_id) AND t.to_account_id = sqlc.arg(account_id))
+    OR (t.to_account_id = sqlc.narg(counterparty_account_id) AND t.from_account_id = sqlc.arg(account_id)))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (t.created_at, t.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,45 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (scope, subject, currency, period, max_amount, max_count)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (tenant_id, scope, subject, currency, period) DO UPDATE
+    SET max_amount = EXCLUDED.max_amount,
+        max_count  = EXCLUDED.max_count
+RETURNING *;
+
+-- name: ListTransferLimits :many
+SELECT *
+FROM transfer_limits
+ORDER BY currency, scope, subject, period;
+
+-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits WHERE id = $1;
+
+-- name: ListApplicableTransferLimits :many
+-- the limits of the currency, of the account and of the user spending from it
+SELECT *
+FROM transfer_limits
+WHERE currency = sqlc.arg(currency)
+  AND (scope = 'currency'
+    OR (scope = 'account' AND subject = sqlc.arg(account_subject))
+    OR (scope = 'user' AND subject = sqlc.arg(username)))
+ORDER BY id;
+
+-- name: AddTransferLimitUsage :one
+-- the upsert locks the counter until the end of the transaction
+INSERT INTO transfer_limit_counters (scope, subject, currency, period, window_start, amount, count)
+VALUES (sqlc.arg(scope), sqlc.arg(subject), sqlc.arg(currency), sqlc.arg(period), sqlc.arg(window_start),
+        sqlc.arg(amount), sqlc.arg(count))
+ON CONFLICT (tenant_id, scope, subject, currency, period, window_start) DO UPDATE
+    SET amount = transfer_limit_counters.amount + EXCLUDED.amount,
+        count  = transfer_limit_counters.count + EXCLUDED.count
+RETURNING *;
+
+-- name: GetTransferLimitCounter :one
+SELECT *
+FROM transfer_limit_counters
+WHERE scope = $1
+  AND subject = $2
+  AND currency = $3
+  AND period = $4
+  AND window_start = $5
+LIMIT 1;
@@ -3,6 +3,49 @@ INSERT INTO users (username, hashed_password, full_name, email)
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
+-- name: CreateHouseUser :exec
+-- house users own the house accounts, nobody can log in with an empty password hash
+INSERT INTO users (username, hashed_password, full_name, email)
+VALUES ($1, '', $2, $3)
+ON CONFLICT DO NOTHING;
+
 -- name: GetUser :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY created_at, username
+LIMIT $1
+OFFSET $2;
+
+-- name: ListUsersAfter :many
+-- keyset page ordered by (created_at, username), starting from the beginning without a cursor
+SELECT * FROM users
+WHERE sqlc.narg(cursor_created_at)::timestamptz IS NULL
+   OR (created_at, username) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_username)::varchar)
+ORDER BY created_at, username
+LIMIT sqlc.arg('limit');
+
+-- name: GetUserByAlias :one
+SELECT * FROM users
+WHERE lower(alias) = lower($1) LIMIT 1;
+
+-- name: GetUserByVerifiedEmail :one
+SELECT * FROM users
+WHERE lower(email) = lower($1)
+  AND email_verified_at IS NOT NULL
+LIMIT 1;
+
+-- name: SetUserAlias :one
+-- a null alias removes it
+UPDATE users
+SET alias = sqlc.narg(alias)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now())
+WHERE username = $1
+RETURNING *;
@@ -5,13 +5,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
 `
 
 type AddAccountBalanceParams struct {
@@ -28,24 +29,76 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+WITH account AS (
+    INSERT INTO accounts (owner, balance, currency, type)
+        VALUES ($1, $2, $3, COALESCE($4, 'checking'))
+        RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id),
+     holder AS (
+         INSERT INTO account_holders (account_id, username, role)
+             SELECT id, owner, 'primary' FROM account)
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
+FROM account
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner    string         `json:"owner"`
+	Balance  int64          `json:"balance"`
+	Currency string         `json:"currency"`
+	Type     sql.NullString `json:"type"`
 }
 
+// the type defaults to checking, the owner becomes the primary holder
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -53,21 +106,54 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1
+const createPot = `-- name: CreatePot :one
+INSERT INTO accounts (owner, balance, currency, type, nickname, parent_id)
+SELECT owner, 0, currency, COALESCE($1, type), $2, id
+FROM accounts
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
-	return err
+type CreatePotParams struct {
+	Type     sql.NullString `json:"type"`
+	Name     string         `json:"name"`
+	ParentID int64          `json:"parent_id"`
+}
+
+// the pot takes the owner and currency of its parent, and its type unless one is given
+func (q *Queries) CreatePot(ctx context.Context, arg CreatePotParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createPot, arg.Type, arg.Name, arg.ParentID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -80,12 +166,50 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE owner = $1 AND currency = $2 AND parent_id IS NULL
+ORDER BY id LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -99,24 +223,213 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getPayeeAccount = `-- name: GetPayeeAccount :one
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE owner = $1
+  AND currency = $2
+  AND parent_id IS NULL
+  AND status = 'active'
+ORDER BY type = 'checking' DESC, id
+LIMIT 1
+`
+
+type GetPayeeAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+// the account payments to the owner land on: an active top level account in the currency, checking first
+func (q *Queries) GetPayeeAccount(ctx context.Context, arg GetPayeeAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getPayeeAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-ORDER BY id
-LIMIT $1
-OFFSET $2
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE ($1::varchar IS NULL OR currency = $1)
+  AND ($2::bigint IS NULL OR balance >= $2)
+  AND ($3::bigint IS NULL OR balance <= $3)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::varchar IS NULL OR status = $6)
+ORDER BY CASE WHEN $7::varchar = 'balance' AND NOT $8::bool THEN balance END,
+         CASE WHEN $7 = 'balance' AND $8 THEN balance END DESC,
+         CASE WHEN $7 = 'created_at' AND NOT $8 THEN created_at END,
+         CASE WHEN $7 = 'created_at' AND $8 THEN created_at END DESC,
+         CASE WHEN $8 THEN id END DESC,
+         id
+LIMIT $9
+OFFSET $10
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Currency      sql.NullString `json:"currency"`
+	MinBalance    sql.NullInt64  `json:"min_balance"`
+	MaxBalance    sql.NullInt64  `json:"max_balance"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	Status        sql.NullString `json:"status"`
+	Sort          string         `json:"sort"`
+	Descending    bool           `json:"descending"`
+	Limit         int32          `json:"limit"`
+	Offset        int32          `json:"offset"`
 }
 
+// every filter is optional, sort is one of balance, created_at or id and ties are broken by id
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.Currency,
+		arg.MinBalance,
+		arg.MaxBalance,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Status,
+		arg.Sort,
+		arg.Descending,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.Status,
+			&i.Nickname,
+			&i.OverdraftLimit,
+			&i.Type,
+			&i.ParentID,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsAfter = `-- name: ListAccountsAfter :many
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE ($1::varchar IS NULL OR currency = $1)
+  AND ($2::bigint IS NULL OR balance >= $2)
+  AND ($3::bigint IS NULL OR balance <= $3)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::varchar IS NULL OR status = $6)
+  AND ($7::timestamptz IS NULL
+    OR (created_at, id) > ($7, $8::bigint))
+ORDER BY created_at, id
+LIMIT $9
+`
+
+type ListAccountsAfterParams struct {
+	Currency        sql.NullString `json:"currency"`
+	MinBalance      sql.NullInt64  `json:"min_balance"`
+	MaxBalance      sql.NullInt64  `json:"max_balance"`
+	CreatedAfter    sql.NullTime   `json:"created_after"`
+	CreatedBefore   sql.NullTime   `json:"created_before"`
+	Status          sql.NullString `json:"status"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        sql.NullInt64  `json:"cursor_id"`
+	Limit           int32          `json:"limit"`
+}
+
+// keyset page ordered by (created_at, id), starting from the beginning without a cursor
+func (q *Queries) ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsAfter,
+		arg.Currency,
+		arg.MinBalance,
+		arg.MaxBalance,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Status,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.Status,
+			&i.Nickname,
+			&i.OverdraftLimit,
+			&i.Type,
+			&i.ParentID,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPots = `-- name: ListPots :many
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE parent_id = $1::bigint
+ORDER BY id
+`
+
+func (q *Queries) ListPots(ctx context.Context, parentID int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listPots, parentID)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +443,13 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.Status,
+			&i.Nickname,
+			&i.OverdraftLimit,
+			&i.Type,
+			&i.ParentID,
+			&i.TenantID,
 		); err != nil {
 			return nil, err
 		}
@@ -144,11 +464,83 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listPotsForUpdate = `-- name: ListPotsForUpdate :many
+SELECT id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id FROM accounts
+WHERE parent_id = $1::bigint
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+// pots are always created after their parent, so locking them after it keeps the ascending id order
+func (q *Queries) ListPotsForUpdate(ctx context.Context, parentID int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listPotsForUpdate, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.Status,
+			&i.Nickname,
+			&i.OverdraftLimit,
+			&i.Type,
+			&i.ParentID,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetAccountBalanceToEntries = `-- name: ResetAccountBalanceToEntries :one
+UPDATE accounts
+SET balance = (SELECT COALESCE(SUM(e.amount), 0) FROM entries e WHERE e.account_id = $1)
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
+`
+
+// lock the account first, so the sum sees every entry committed before the lock
+func (q *Queries) ResetAccountBalanceToEntries(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, resetAccountBalanceToEntries, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
 `
 
 type UpdateAccountParams struct {
@@ -165,6 +557,80 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const updateAccountDetails = `-- name: UpdateAccountDetails :one
+UPDATE accounts
+SET nickname        = COALESCE($1, nickname),
+    overdraft_limit = COALESCE($2, overdraft_limit)
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
+`
+
+type UpdateAccountDetailsParams struct {
+	Nickname       sql.NullString `json:"nickname"`
+	OverdraftLimit sql.NullInt64  `json:"overdraft_limit"`
+	ID             int64          `json:"id"`
+}
+
+// only the given attributes change
+func (q *Queries) UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountDetails, arg.Nickname, arg.OverdraftLimit, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, held_balance, status, nickname, overdraft_limit, type, parent_id, tenant_id
+`
+
+type UpdateAccountStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.ID, arg.Status)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.Status,
+		&i.Nickname,
+		&i.OverdraftLimit,
+		&i.Type,
+		&i.ParentID,
+		&i.TenantID,
 	)
 	return i, err
 }
@@ -3,8 +3,10 @@ package db
 import (
 	"code-with-go/util"
 	"context"
+	"database/sql"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestQueries_CreateAccount(t *testing.T) {
@@ -36,6 +38,153 @@ func TestQueries_ListAccounts(t *testing.T) {
 	}
 }
 
+func TestQueries_ListAccountsFiltered(t *testing.T) {
+	// only look at the accounts created by this test
+	start := sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true}
+	created := map[int64]Account{}
+	for _, currency := range []string{util.USD, util.EUR} {
+		for _, balance := range []int64{10, 20, 30} {
+			account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+				Owner:    createRandomUser(t).Username,
+				Balance:  balance,
+				Currency: currency,
+			})
+			require.NoError(t, err)
+			created[account.ID] = account
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		arg     ListAccountsParams
+		count   int
+		matches func(account Account) bool
+		less    func(a, b Account) bool
+	}{
+		{
+			name:    "Currency",
+			arg:     ListAccountsParams{Currency: sql.NullString{String: util.EUR, Valid: true}},
+			count:   3,
+			matches: func(account Account) bool { return account.Currency == util.EUR },
+		},
+		{
+			name:    "BalanceRange",
+			arg:     ListAccountsParams{MinBalance: sql.NullInt64{Int64: 15, Valid: true}, MaxBalance: sql.NullInt64{Int64: 25, Valid: true}},
+			count:   2,
+			matches: func(account Account) bool { return account.Balance == 20 },
+		},
+		{
+			name: "CurrencyAndMinBalance",
+			arg: ListAccountsParams{
+				Currency:   sql.NullString{String: util.USD, Valid: true},
+				MinBalance: sql.NullInt64{Int64: 20, Valid: true},
+			},
+			count:   2,
+			matches: func(account Account) bool { return account.Currency == util.USD && account.Balance >= 20 },
+		},
+		{
+			name:    "CreatedBefore",
+			arg:     ListAccountsParams{CreatedBefore: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}},
+			count:   6,
+			matches: func(account Account) bool { return true },
+		},
+		{
+			name:    "SortBalanceAsc",
+			arg:     ListAccountsParams{Sort: "balance"},
+			count:   6,
+			matches: func(account Account) bool { return true },
+			less:    func(a, b Account) bool { return a.Balance < b.Balance || a.Balance == b.Balance && a.ID < b.ID },
+		},
+		{
+			name:    "SortBalanceDesc",
+			arg:     ListAccountsParams{Sort: "balance", Descending: true},
+			count:   6,
+			matches: func(account Account) bool { return true },
+			less:    func(a, b Account) bool { return a.Balance > b.Balance || a.Balance == b.Balance && a.ID > b.ID },
+		},
+		{
+			name:    "SortCreatedAtDesc",
+			arg:     ListAccountsParams{Sort: "created_at", Descending: true},
+			count:   6,
+			matches: func(account Account) bool { return true },
+			less: func(a, b Account) bool {
+				return a.CreatedAt.After(b.CreatedAt) || a.CreatedAt.Equal(b.CreatedAt) && a.ID > b.ID
+			},
+		},
+		{
+			name:    "SortIDDesc",
+			arg:     ListAccountsParams{Sort: "id", Descending: true},
+			count:   6,
+			matches: func(account Account) bool { return true },
+			less:    func(a, b Account) bool { return a.ID > b.ID },
+		},
+		{
+			name: "FilterAndSort",
+			arg: ListAccountsParams{
+				Currency:   sql.NullString{String: util.USD, Valid: true},
+				MaxBalance: sql.NullInt64{Int64: 20, Valid: true},
+				Sort:       "balance",
+				Descending: true,
+			},
+			count:   2,
+			matches: func(account Account) bool { return account.Currency == util.USD && account.Balance <= 20 },
+			less:    func(a, b Account) bool { return a.Balance > b.Balance },
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			arg := tc.arg
+			arg.CreatedAfter = start
+			arg.Limit = 10
+
+			accounts, err := testQueries.ListAccounts(context.Background(), arg)
+			require.NoError(t, err)
+			require.Len(t, accounts, tc.count)
+
+			for i, account := range accounts {
+				require.Contains(t, created, account.ID)
+				require.True(t, tc.matches(account))
+				if tc.less != nil && i > 0 {
+					require.True(t, tc.less(accounts[i-1], account))
+				}
+			}
+		})
+	}
+}
+
+func TestQueries_ListAccountsAfter(t *testing.T) {
+	first := createRandomAccount(t)
+	for i := 0; i < 5; i++ {
+		createRandomAccount(t)
+	}
+
+	arg := ListAccountsAfterParams{
+		CursorCreatedAt: sql.NullTime{Time: first.CreatedAt, Valid: true},
+		CursorID:        sql.NullInt64{Int64: first.ID, Valid: true},
+		Limit:           5,
+	}
+	accounts, err := testQueries.ListAccountsAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 5)
+
+	previous := first
+	for _, account := range accounts {
+		require.True(t, account.CreatedAt.After(previous.CreatedAt) ||
+			account.CreatedAt.Equal(previous.CreatedAt) && account.ID > previous.ID)
+		previous = account
+	}
+
+	// the next page starts right after the last row of this one
+	arg.CursorCreatedAt.Time = accounts[1].CreatedAt
+	arg.CursorID.Int64 = accounts[1].ID
+	next, err := testQueries.ListAccountsAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, accounts[2].ID, next[0].ID)
+}
+
 func TestQueries_UpdateAccount(t *testing.T) {
 	account := createRandomAccount(t)
 	arg := UpdateAccountParams{
@@ -50,19 +199,66 @@ func TestQueries_UpdateAccount(t *testing.T) {
 	require.Equal(t, account.Owner, updatedAccount.Owner)
 }
 
-func TestQueries_DeleteAccount(t *testing.T) {
+func TestQueries_UpdateAccountStatus(t *testing.T) {
 	account := createRandomAccount(t)
+	require.Equal(t, AccountStatusActive, account.Status)
 
-	err := testQueries.DeleteAccount(context.Background(), account.ID)
+	updatedAccount, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account.ID,
+		Status: AccountStatusFrozen,
+	})
 	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, updatedAccount.Status)
+
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account.ID,
+		Status: "deleted",
+	})
+	require.Error(t, err)
+}
+
+func TestQueries_UpdateAccountDetails(t *testing.T) {
+	account := createRandomAccount(t)
+	require.Empty(t, account.Nickname)
+	require.Zero(t, account.OverdraftLimit)
+
+	updatedAccount, err := testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:       account.ID,
+		Nickname: sql.NullString{String: "savings", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "savings", updatedAccount.Nickname)
+	require.Zero(t, updatedAccount.OverdraftLimit)
+
+	// attributes left out keep their value
+	updatedAccount, err = testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:             account.ID,
+		OverdraftLimit: sql.NullInt64{Int64: 500, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "savings", updatedAccount.Nickname)
+	require.Equal(t, int64(500), updatedAccount.OverdraftLimit)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+
+	_, err = testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:             account.ID,
+		OverdraftLimit: sql.NullInt64{Int64: -1, Valid: true},
+	})
+	require.Error(t, err)
 }
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountInCurrency(t, util.RandomCurrency())
+}
+
+// createRandomAccountInCurrency is for tests moving money, the postings of a transfer must share a currency.
+// The balance covers the small transfers the tests make along with their fees.
+func createRandomAccountInCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Balance:  util.RandomInt(1000, 2000),
+		Currency: currency,
 	}
 	account, err := testQueries.CreateAccount(context.Background(), arg)
 	require.NoError(t, err)
@@ -74,3 +270,62 @@ func createRandomAccount(t *testing.T) Account {
 
 	return account
 }
+
+func TestQueries_CreatePot(t *testing.T) {
+	parent := createRandomAccount(t)
+
+	pot, err := testQueries.CreatePot(context.Background(), CreatePotParams{
+		Type:     sql.NullString{String: AccountTypeSavings, Valid: true},
+		Name:     "Rainy day",
+		ParentID: parent.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, parent.Owner, pot.Owner)
+	require.Equal(t, parent.Currency, pot.Currency)
+	require.Equal(t, AccountTypeSavings, pot.Type)
+	require.Equal(t, "Rainy day", pot.Nickname)
+	require.Zero(t, pot.Balance)
+	require.Equal(t, sql.NullInt64{Int64: parent.ID, Valid: true}, pot.ParentID)
+
+	// names are unique under the parent
+	_, err = testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Rainy day", ParentID: parent.ID})
+	require.Error(t, err)
+
+	other, err := testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Holidays", ParentID: parent.ID})
+	require.NoError(t, err)
+	require.Equal(t, parent.Type, other.Type)
+
+	pots, err := testQueries.ListPots(context.Background(), parent.ID)
+	require.NoError(t, err)
+	require.Equal(t, []Account{pot, other}, pots)
+
+	_, err = testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Nowhere", ParentID: parent.ID + 1000000})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_GetPayeeAccount(t *testing.T) {
+	user := createRandomUser(t)
+	savings, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: util.USD,
+		Type:     sql.NullString{String: AccountTypeSavings, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// a savings account is paid into when it is the only one in the currency
+	account, err := testQueries.GetPayeeAccount(context.Background(), GetPayeeAccountParams{Owner: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	require.Equal(t, savings.ID, account.ID)
+
+	checking, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{Owner: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	_, err = testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Rainy day", ParentID: checking.ID})
+	require.NoError(t, err)
+
+	account, err = testQueries.GetPayeeAccount(context.Background(), GetPayeeAccountParams{Owner: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+
+	_, err = testQueries.GetPayeeAccount(context.Background(), GetPayeeAccountParams{Owner: user.Username, Currency: util.EUR})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
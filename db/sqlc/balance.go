@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// GetBalanceAsOf returns the balance an account had at the given instant, counting entries created up to it.
+// It starts from the latest daily snapshot taken before that instant and only sums the entries since,
+// falling back to scanning every later entry when there is no snapshot yet.
+func (store *SQLStore) GetBalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (int64, error) {
+	var balance int64
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		balance, err = getBalanceAsOf(ctx, queries, accountID, asOf)
+		return err
+	})
+	return balance, err
+}
+
+func getBalanceAsOf(ctx context.Context, q *Queries, accountID int64, asOf time.Time) (int64, error) {
+	snapshot, err := q.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+		AccountID: accountID,
+		AsOf:      asOf,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return q.GetAccountBalanceAsOf(ctx, GetAccountBalanceAsOfParams{
+				AsOf:      asOf,
+				AccountID: accountID,
+			})
+		}
+		return 0, err
+	}
+
+	since, err := q.SumEntriesBetween(ctx, SumEntriesBetweenParams{
+		AccountID: accountID,
+		After:     snapshot.SnapshotAt,
+		Until:     asOf,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return snapshot.Balance + since, nil
+}
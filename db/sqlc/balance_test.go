@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_GetBalanceAsOf(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+
+	var moments []time.Time
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 4},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 7},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 2},
+	} {
+		result, err := store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		moments = append(moments, result.FromEntry.CreatedAt)
+	}
+
+	account1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	// without any snapshot, the balance comes from scanning the entries
+	balance, err := store.GetBalanceAsOf(context.Background(), account1.ID, moments[0])
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+10-4+7-2-10, balance)
+
+	_, err = testQueries.CreateBalanceSnapshots(context.Background(), moments[1])
+	require.NoError(t, err)
+	// taking the same snapshot again changes nothing
+	rows, err := testQueries.CreateBalanceSnapshots(context.Background(), moments[1])
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	for _, asOf := range append(moments, moments[1].Add(-time.Microsecond), time.Now().Add(time.Hour)) {
+		fullScan, err := testQueries.GetAccountBalanceAsOf(context.Background(), GetAccountBalanceAsOfParams{
+			AsOf:      asOf,
+			AccountID: account1.ID,
+		})
+		require.NoError(t, err)
+
+		balance, err := store.GetBalanceAsOf(context.Background(), account1.ID, asOf)
+		require.NoError(t, err)
+		require.Equal(t, fullScan, balance)
+	}
+
+	balance, err = store.GetBalanceAsOf(context.Background(), account1.ID, moments[3])
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, balance)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestQueries_Beneficiaries(t *testing.T) {
+	owner := createRandomUser(t)
+	landlord := createRandomAccount(t)
+	plumber := createRandomAccount(t)
+
+	arg := CreateBeneficiaryParams{
+		Owner:           owner.Username,
+		Nickname:        "Landlord",
+		AccountID:       landlord.ID,
+		Currency:        landlord.Currency,
+		CoolingOffUntil: time.Now().Add(time.Hour),
+	}
+	beneficiary, err := testQueries.CreateBeneficiary(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, beneficiary.AccountID)
+	require.WithinDuration(t, arg.CoolingOffUntil, beneficiary.CoolingOffUntil, time.Second)
+
+	// nicknames and accounts are saved once per owner
+	_, err = testQueries.CreateBeneficiary(context.Background(), CreateBeneficiaryParams{
+		Owner:     owner.Username,
+		Nickname:  "Landlord",
+		AccountID: plumber.ID,
+		Currency:  plumber.Currency,
+	})
+	require.Error(t, err)
+	_, err = testQueries.CreateBeneficiary(context.Background(), CreateBeneficiaryParams{
+		Owner:     owner.Username,
+		Nickname:  "Other landlord",
+		AccountID: landlord.ID,
+		Currency:  landlord.Currency,
+	})
+	require.Error(t, err)
+
+	other, err := testQueries.CreateBeneficiary(context.Background(), CreateBeneficiaryParams{
+		Owner:           owner.Username,
+		Nickname:        "Handyman",
+		AccountID:       plumber.ID,
+		Currency:        plumber.Currency,
+		CoolingOffUntil: time.Now(),
+	})
+	require.NoError(t, err)
+
+	renamed, err := testQueries.UpdateBeneficiary(context.Background(), UpdateBeneficiaryParams{ID: other.ID, Nickname: "Plumber"})
+	require.NoError(t, err)
+	require.Equal(t, "Plumber", renamed.Nickname)
+	require.Equal(t, other.AccountID, renamed.AccountID)
+
+	beneficiaries, err := testQueries.ListBeneficiaries(context.Background(), ListBeneficiariesParams{
+		Owner: owner.Username,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Beneficiary{beneficiary, renamed}, beneficiaries)
+
+	byAccount, err := testQueries.GetBeneficiaryByAccount(context.Background(), GetBeneficiaryByAccountParams{
+		Owner:     owner.Username,
+		AccountID: landlord.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, beneficiary, byAccount)
+	_, err = testQueries.GetBeneficiaryByAccount(context.Background(), GetBeneficiaryByAccountParams{
+		Owner:     landlord.Owner,
+		AccountID: landlord.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = testQueries.DeleteBeneficiary(context.Background(), beneficiary.ID)
+	require.NoError(t, err)
+	_, err = testQueries.GetBeneficiary(context.Background(), beneficiary.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -5,33 +5,62 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries (account_id, amount)
-VALUES ($1, $2)
-RETURNING id, account_id, amount, created_at
+INSERT INTO entries (account_id, amount, transfer_id)
+VALUES ($1, $2, $3)
+RETURNING id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.TenantID,
 	)
 	return i, err
 }
 
+const getAccountBalanceAsOf = `-- name: GetAccountBalanceAsOf :one
+SELECT (a.balance - COALESCE((SELECT SUM(e.amount)
+                              FROM entries e
+                              WHERE e.account_id = a.id
+                                AND e.created_at > $1), 0))::bigint AS balance
+FROM accounts a
+WHERE a.id = $2
+`
+
+type GetAccountBalanceAsOfParams struct {
+	AsOf      time.Time `json:"as_of"`
+	AccountID int64     `json:"account_id"`
+}
+
+// full scan: the current balance minus every entry created after as_of
+func (q *Queries) GetAccountBalanceAsOf(ctx context.Context, arg GetAccountBalanceAsOfParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountBalanceAsOf, arg.AsOf, arg.AccountID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id
 FROM entries
 WHERE id = $1
 LIMIT 1
@@ -45,12 +74,232 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.TenantID,
 	)
 	return i, err
 }
 
+const getPreviousEntryHash = `-- name: GetPreviousEntryHash :one
+SELECT hash
+FROM entries
+WHERE account_id = $1
+  AND id < $2
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetPreviousEntryHashParams struct {
+	AccountID int64 `json:"account_id"`
+	ID        int64 `json:"id"`
+}
+
+// the account must be locked, so no other entry can be chained in between
+func (q *Queries) GetPreviousEntryHash(ctx context.Context, arg GetPreviousEntryHashParams) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousEntryHash, arg.AccountID, arg.ID)
+	var hash []byte
+	err := row.Scan(&hash)
+	return hash, err
+}
+
+const listAccountEntries = `-- name: ListAccountEntries :many
+WITH history AS (
+    SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.prev_hash, e.hash, e.tenant_id,
+           a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount AS running_balance,
+           CASE
+               WHEN t.from_account_id = e.account_id AND ROW_NUMBER() OVER (PARTITION BY e.transfer_id ORDER BY e.id) > 1
+                   THEN (SELECT r.account_id
+                         FROM entries r
+                         WHERE r.transfer_id = t.id
+                           AND r.account_id NOT IN (t.from_account_id, t.to_account_id))
+               WHEN t.from_account_id = e.account_id THEN t.to_account_id
+               ELSE t.from_account_id END                                                 AS counterparty_account_id
+    FROM entries e
+             JOIN accounts a ON a.id = e.account_id
+             LEFT JOIN transfers t ON t.id = e.transfer_id
+    WHERE e.account_id = $1
+)
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id, running_balance::bigint, counterparty_account_id
+FROM history
+WHERE ($2::timestamptz IS NULL OR created_at >= $2)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+  AND ($4::varchar IS NULL
+    OR ($4 = 'in' AND amount > 0)
+    OR ($4 = 'out' AND amount < 0))
+  AND ($5::bigint IS NULL OR abs(amount) >= $5)
+  AND ($6::bigint IS NULL OR abs(amount) <= $6)
+  AND ($7::bigint IS NULL OR counterparty_account_id = $7)
+  AND ($8::timestamptz IS NULL
+    OR (created_at, id) < ($8, $9::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT $10 OFFSET $11
+`
+
+type ListAccountEntriesParams struct {
+	AccountID             int64          `json:"account_id"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Direction             sql.NullString `json:"direction"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	CursorCreatedAt       sql.NullTime   `json:"cursor_created_at"`
+	CursorID              sql.NullInt64  `json:"cursor_id"`
+	Limit                 int32          `json:"limit"`
+	Offset                int32          `json:"offset"`
+}
+
+type ListAccountEntriesRow struct {
+	ID                    int64         `json:"id"`
+	AccountID             int64         `json:"account_id"`
+	Amount                int64         `json:"amount"`
+	CreatedAt             time.Time     `json:"created_at"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	PrevHash              []byte        `json:"prev_hash"`
+	Hash                  []byte        `json:"hash"`
+	TenantID              int64         `json:"tenant_id"`
+	RunningBalance        int64         `json:"running_balance"`
+	CounterpartyAccountID sql.NullInt64 `json:"counterparty_account_id"`
+}
+
+// running_balance is the account balance right after the entry, counted back from the current balance.
+// A transfer posts its fee on the sender after the principal, the fee's counterparty is the revenue account it credits.
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CounterpartyAccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.TenantID,
+			&i.RunningBalance,
+			&i.CounterpartyAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChainEntries = `-- name: ListChainEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.prev_hash, e.hash, e.tenant_id,
+       COALESCE(p.currency, '')::varchar        AS posting_currency,
+       COALESCE(p.account_id, 0)::bigint        AS posting_account_id,
+       COALESCE(p.amount, 0)::bigint            AS posting_amount,
+       COALESCE(t.from_account_id, 0)::bigint   AS transfer_from_account_id,
+       COALESCE(t.to_account_id, 0)::bigint     AS transfer_to_account_id,
+       COALESCE(t.amount, 0)::bigint            AS transfer_amount
+FROM entries e
+         LEFT JOIN postings p ON p.entry_id = e.id
+         LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE ($1::bigint IS NULL OR e.account_id = $1)
+  AND (e.account_id, e.id) > ($2::bigint, $3::bigint)
+ORDER BY e.account_id, e.id
+LIMIT $4
+`
+
+type ListChainEntriesParams struct {
+	AccountID      sql.NullInt64 `json:"account_id"`
+	AfterAccountID int64         `json:"after_account_id"`
+	AfterID        int64         `json:"after_id"`
+	Limit          int32         `json:"limit"`
+}
+
+type ListChainEntriesRow struct {
+	ID                    int64         `json:"id"`
+	AccountID             int64         `json:"account_id"`
+	Amount                int64         `json:"amount"`
+	CreatedAt             time.Time     `json:"created_at"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	PrevHash              []byte        `json:"prev_hash"`
+	Hash                  []byte        `json:"hash"`
+	TenantID              int64         `json:"tenant_id"`
+	PostingCurrency       string        `json:"posting_currency"`
+	PostingAccountID      int64         `json:"posting_account_id"`
+	PostingAmount         int64         `json:"posting_amount"`
+	TransferFromAccountID int64         `json:"transfer_from_account_id"`
+	TransferToAccountID   int64         `json:"transfer_to_account_id"`
+	TransferAmount        int64         `json:"transfer_amount"`
+}
+
+// walks the entries account by account, in the order they were chained,
+// along with the posting and transfer fields their hash covers
+func (q *Queries) ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]ListChainEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChainEntries,
+		arg.AccountID,
+		arg.AfterAccountID,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListChainEntriesRow{}
+	for rows.Next() {
+		var i ListChainEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.TenantID,
+			&i.PostingCurrency,
+			&i.PostingAccountID,
+			&i.PostingAmount,
+			&i.TransferFromAccountID,
+			&i.TransferToAccountID,
+			&i.TransferAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id
 FROM entries
 WHERE account_id = $1
 ORDER BY id
@@ -77,6 +326,49 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLastEntryHashes = `-- name: ListLastEntryHashes :many
+SELECT DISTINCT ON (account_id) account_id, hash
+FROM entries
+WHERE created_at < $1
+ORDER BY account_id, id DESC
+`
+
+type ListLastEntryHashesRow struct {
+	AccountID int64  `json:"account_id"`
+	Hash      []byte `json:"hash"`
+}
+
+// the chain head of every account at the given instant
+func (q *Queries) ListLastEntryHashes(ctx context.Context, until time.Time) ([]ListLastEntryHashesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLastEntryHashes, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLastEntryHashesRow{}
+	for rows.Next() {
+		var i ListLastEntryHashesRow
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Hash,
 		); err != nil {
 			return nil, err
 		}
@@ -90,3 +382,33 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const sealEntry = `-- name: SealEntry :one
+UPDATE entries
+SET prev_hash = $2,
+    hash      = $3
+WHERE id = $1
+RETURNING id, account_id, amount, created_at, transfer_id, prev_hash, hash, tenant_id
+`
+
+type SealEntryParams struct {
+	ID       int64  `json:"id"`
+	PrevHash []byte `json:"prev_hash"`
+	Hash     []byte `json:"hash"`
+}
+
+func (q *Queries) SealEntry(ctx context.Context, arg SealEntryParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, sealEntry, arg.ID, arg.PrevHash, arg.Hash)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.TenantID,
+	)
+	return i, err
+}
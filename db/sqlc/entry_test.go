@@ -3,8 +3,10 @@ package db
 import (
 	"code-with-go/util"
 	"context"
+	"database/sql"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestQueries_CreateEntry(t *testing.T) {
@@ -46,6 +48,80 @@ func TestQueries_ListEntries(t *testing.T) {
 	}
 }
 
+func TestQueries_ListAccountEntries(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	account3 := createRandomAccountInCurrency(t, account1.Currency)
+
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 4},
+		{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 7},
+	} {
+		_, err := store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	account1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account1.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// newest first, each running balance is the previous one minus the newer entry
+	require.Equal(t, []int64{-7, 4, -10}, []int64{entries[0].Amount, entries[1].Amount, entries[2].Amount})
+	require.Equal(t, account1.Balance, entries[0].RunningBalance)
+	require.Equal(t, account1.Balance+7, entries[1].RunningBalance)
+	require.Equal(t, account1.Balance+3, entries[2].RunningBalance)
+	require.Equal(t, account3.ID, entries[0].CounterpartyAccountID.Int64)
+
+	incoming, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account1.ID,
+		Direction: sql.NullString{String: "in", Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	require.Equal(t, int64(4), incoming[0].Amount)
+	require.Equal(t, account1.Balance+7, incoming[0].RunningBalance)
+
+	filtered, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:             account1.ID,
+		MinAmount:             sql.NullInt64{Int64: 5, Valid: true},
+		MaxAmount:             sql.NullInt64{Int64: 10, Valid: true},
+		CounterpartyAccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		Limit:                 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, int64(-10), filtered[0].Amount)
+
+	later, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account1.ID,
+		StartTime: sql.NullTime{Time: entries[0].CreatedAt.Add(time.Second), Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, later)
+
+	// keyset pages continue after the cursor, newest first
+	older, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:       account1.ID,
+		CursorCreatedAt: sql.NullTime{Time: entries[0].CreatedAt, Valid: true},
+		CursorID:        sql.NullInt64{Int64: entries[0].ID, Valid: true},
+		Limit:           10,
+	})
+	require.NoError(t, err)
+	require.Len(t, older, 2)
+	require.Equal(t, entries[1].ID, older[0].ID)
+	require.Equal(t, entries[1].RunningBalance, older[0].RunningBalance)
+}
+
 func createRandomEntry(t *testing.T, account Account) Entry {
 	arg := CreateEntryParams{
 		AccountID: account.ID,
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// FeeRevenueOwner owns the house accounts collecting transfer fees, one per currency
+const FeeRevenueOwner = "housefees"
+
+// Fee is the breakdown of what is charged on top of a transfer amount
+type Fee struct {
+	RuleID           int64 `json:"rule_id,omitempty"`
+	RevenueAccountID int64 `json:"revenue_account_id,omitempty"`
+	Flat             int64 `json:"flat"`
+	Percentage       int64 `json:"percentage"`
+	Total            int64 `json:"total"`
+}
+
+// CalculateFee applies a fee rule to an amount, rounding the percentage part half up.
+// The whole units of 10000 are divided out first so large amounts can't overflow.
+func CalculateFee(rule FeeRule, amount int64) Fee {
+	percentage := amount/10000*rule.PercentageBps + (amount%10000*rule.PercentageBps+5000)/10000
+	return Fee{
+		RuleID:     rule.ID,
+		Flat:       rule.FlatFee,
+		Percentage: percentage,
+		Total:      rule.FlatFee + percentage,
+	}
+}
+
+// QuoteFee returns the fee a transfer of the amount in the currency would be charged right now.
+func (store *SQLStore) QuoteFee(ctx context.Context, currency string, amount int64) (Fee, error) {
+	var fee Fee
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		fee, err = quoteFee(ctx, queries, currency, amount)
+		return err
+	})
+	return fee, err
+}
+
+func quoteFee(ctx context.Context, q *Queries, currency string, amount int64) (Fee, error) {
+	rule, err := q.GetFeeRule(ctx, GetFeeRuleParams{
+		Currency: currency,
+		Amount:   amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Fee{}, nil
+		}
+		return Fee{}, err
+	}
+
+	fee := CalculateFee(rule, amount)
+	if fee.Total == 0 {
+		return fee, nil
+	}
+
+	revenueAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FeeRevenueOwner,
+		Currency: currency,
+	})
+	if err != nil {
+		return Fee{}, fmt.Errorf("cannot find fee revenue account for %s: %w", currency, err)
+	}
+	fee.RevenueAccountID = revenueAccount.ID
+	return fee, nil
+}
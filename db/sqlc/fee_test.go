@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+// testFeeCurrency is kept apart from real currencies so the rules don't leak into other tests
+const testFeeCurrency = "XTS"
+
+func TestCalculateFee(t *testing.T) {
+	rule := FeeRule{ID: 1, FlatFee: 25, PercentageBps: 150}
+
+	fee := CalculateFee(rule, 1000)
+	require.Equal(t, int64(1), fee.RuleID)
+	require.Equal(t, int64(25), fee.Flat)
+	require.Equal(t, int64(15), fee.Percentage)
+	require.Equal(t, int64(40), fee.Total)
+
+	// 1.5% of 33 is 0.495, rounded half up
+	require.Equal(t, int64(0), CalculateFee(rule, 33).Percentage)
+	// 1.5% of 34 is 0.51
+	require.Equal(t, int64(1), CalculateFee(rule, 34).Percentage)
+
+	// amount*bps alone would overflow int64
+	require.Equal(t, int64(138350580552821637), CalculateFee(rule, math.MaxInt64).Percentage)
+	require.Equal(t, int64(math.MaxInt64), CalculateFee(FeeRule{PercentageBps: 10000}, math.MaxInt64).Percentage)
+}
+
+func TestStore_QuoteFee(t *testing.T) {
+	store := NewStore(testDB)
+	revenueAccount := getFeeRevenueAccount(t)
+
+	lowTier, err := testQueries.UpsertFeeRule(context.Background(), UpsertFeeRuleParams{
+		Currency:      testFeeCurrency,
+		MinAmount:     0,
+		FlatFee:       5,
+		PercentageBps: 0,
+	})
+	require.NoError(t, err)
+
+	highTier, err := testQueries.UpsertFeeRule(context.Background(), UpsertFeeRuleParams{
+		Currency:      testFeeCurrency,
+		MinAmount:     1000,
+		FlatFee:       0,
+		PercentageBps: 100,
+	})
+	require.NoError(t, err)
+
+	fee, err := store.QuoteFee(context.Background(), testFeeCurrency, 999)
+	require.NoError(t, err)
+	require.Equal(t, lowTier.ID, fee.RuleID)
+	require.Equal(t, int64(5), fee.Total)
+	require.Equal(t, revenueAccount.ID, fee.RevenueAccountID)
+
+	fee, err = store.QuoteFee(context.Background(), testFeeCurrency, 2000)
+	require.NoError(t, err)
+	require.Equal(t, highTier.ID, fee.RuleID)
+	require.Equal(t, int64(20), fee.Total)
+
+	// no schedule for the currency means no fee
+	fee, err = store.QuoteFee(context.Background(), "XXX", 2000)
+	require.NoError(t, err)
+	require.Empty(t, fee)
+}
+
+func TestStore_TransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	revenueAccount := getFeeRevenueAccount(t)
+
+	rule, err := testQueries.UpsertFeeRule(context.Background(), UpsertFeeRuleParams{
+		Currency:      testFeeCurrency,
+		MinAmount:     0,
+		FlatFee:       5,
+		PercentageBps: 0,
+	})
+	require.NoError(t, err)
+
+	fromAccount := createRandomAccountInCurrency(t, testFeeCurrency)
+	toAccount := createRandomAccountInCurrency(t, testFeeCurrency)
+	amount := int64(10)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Currency:      testFeeCurrency,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, rule.ID, result.Transfer.FeeRuleID.Int64)
+	require.Equal(t, int64(5), result.Transfer.FlatFee)
+	require.Equal(t, int64(0), result.Transfer.PercentageFee)
+	require.Equal(t, int64(5), result.Fee.Total)
+
+	require.Equal(t, fromAccount.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-5), result.FeeEntry.Amount)
+	require.Equal(t, revenueAccount.ID, result.RevenueEntry.AccountID)
+	require.Equal(t, int64(5), result.RevenueEntry.Amount)
+
+	require.Equal(t, fromAccount.Balance-amount-5, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+amount, result.ToAccount.Balance)
+
+	// the principal goes to the recipient, the fee to the house
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: fromAccount.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, result.FeeEntry.ID, entries[0].ID)
+	require.Equal(t, revenueAccount.ID, entries[0].CounterpartyAccountID.Int64)
+	require.Equal(t, result.FromEntry.ID, entries[1].ID)
+	require.Equal(t, toAccount.ID, entries[1].CounterpartyAccountID.Int64)
+
+	revenue, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:             revenueAccount.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true},
+		Limit:                 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, revenue, 1)
+	require.Equal(t, result.RevenueEntry.ID, revenue[0].ID)
+}
+
+func getFeeRevenueAccount(t *testing.T) Account {
+	_, err := testQueries.GetUser(context.Background(), FeeRevenueOwner)
+	if err == sql.ErrNoRows {
+		_, err = testQueries.CreateUser(context.Background(), CreateUserParams{
+			Username: FeeRevenueOwner,
+			FullName: "Fee revenue",
+			Email:    "fees@house.internal",
+		})
+	}
+	require.NoError(t, err)
+
+	account, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    FeeRevenueOwner,
+		Currency: testFeeCurrency,
+	})
+	if err == sql.ErrNoRows {
+		account, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    FeeRevenueOwner,
+			Currency: testFeeCurrency,
+		})
+	}
+	require.NoError(t, err)
+	return account
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+)
+
+const chainBatchSize = 500
+
+// EntryLedger is what an entry hash covers besides the entry itself: the posting the entry is part of
+// and the transfer that moved the money. Its fields are zero for an entry with neither.
+type EntryLedger struct {
+	Currency         string `json:"currency"`
+	PostingAccountID int64  `json:"posting_account_id"`
+	PostingAmount    int64  `json:"posting_amount"`
+	FromAccountID    int64  `json:"from_account_id"`
+	ToAccountID      int64  `json:"to_account_id"`
+	TransferAmount   int64  `json:"transfer_amount"`
+}
+
+// EntryHash seals an entry: the sha256 of its fields and ledger rows followed by the hash of the account's previous entry.
+// Changing any entry, its posting or its transfer, or removing an entry, breaks the hash of every later entry of the account.
+// The migrations sealing the existing entries must produce the exact same bytes.
+func EntryHash(entry Entry, ledger EntryLedger, prevHash []byte) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%d|%d|%d|%d|", entry.ID, entry.AccountID, entry.Amount, entry.TransferID.Int64, entry.CreatedAt.UnixMicro())
+	fmt.Fprintf(h, "%s|%d|%d|%d|%d|%d|", ledger.Currency, ledger.PostingAccountID, ledger.PostingAmount,
+		ledger.FromAccountID, ledger.ToAccountID, ledger.TransferAmount)
+	h.Write(prevHash)
+	return h.Sum(nil)
+}
+
+// chainEntry seals a new entry, chaining it to the previous one of its account.
+// The account row must already be locked by the transaction.
+func chainEntry(ctx context.Context, q *Queries, entry Entry, ledger EntryLedger) (Entry, error) {
+	prevHash, err := q.GetPreviousEntryHash(ctx, GetPreviousEntryHashParams{
+		AccountID: entry.AccountID,
+		ID:        entry.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return entry, err
+	}
+
+	return q.SealEntry(ctx, SealEntryParams{
+		ID:       entry.ID,
+		PrevHash: prevHash,
+		Hash:     EntryHash(entry, ledger, prevHash),
+	})
+}
+
+// split separates the entry from the ledger fields its hash covers
+func (row ListChainEntriesRow) split() (Entry, EntryLedger) {
+	entry := Entry{
+		ID:         row.ID,
+		AccountID:  row.AccountID,
+		Amount:     row.Amount,
+		CreatedAt:  row.CreatedAt,
+		TransferID: row.TransferID,
+		PrevHash:   row.PrevHash,
+		Hash:       row.Hash,
+		TenantID:   row.TenantID,
+	}
+	ledger := EntryLedger{
+		Currency:         row.PostingCurrency,
+		PostingAccountID: row.PostingAccountID,
+		PostingAmount:    row.PostingAmount,
+		FromAccountID:    row.TransferFromAccountID,
+		ToAccountID:      row.TransferToAccountID,
+		TransferAmount:   row.TransferAmount,
+	}
+	return entry, ledger
+}
+
+// ChainBreak is the first entry whose hash doesn't follow from the ones before it
+type ChainBreak struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Reason    string `json:"reason"`
+}
+
+// VerifyEntryChain walks the entries of an account, or of every account when accountID is zero,
+// and returns the first broken link, or nil when every chain holds.
+func (store *SQLStore) VerifyEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error) {
+	arg := ListChainEntriesParams{
+		AccountID: sql.NullInt64{Int64: accountID, Valid: accountID != 0},
+		Limit:     chainBatchSize,
+	}
+	var prevHash []byte
+
+	for {
+		entries, err := store.ListChainEntries(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range entries {
+			entry, ledger := row.split()
+			if entry.AccountID != arg.AfterAccountID {
+				prevHash = nil
+			}
+			arg.AfterAccountID = entry.AccountID
+			arg.AfterID = entry.ID
+
+			switch {
+			case entry.Hash == nil:
+				return &ChainBreak{entry.AccountID, entry.ID, "entry is not sealed"}, nil
+			case !bytes.Equal(entry.PrevHash, prevHash):
+				return &ChainBreak{entry.AccountID, entry.ID, "previous hash doesn't match the previous entry"}, nil
+			case !bytes.Equal(entry.Hash, EntryHash(entry, ledger, prevHash)):
+				return &ChainBreak{entry.AccountID, entry.ID, "hash doesn't match the entry"}, nil
+			}
+			prevHash = entry.Hash
+		}
+
+		if len(entries) < chainBatchSize {
+			return nil, nil
+		}
+	}
+}
+
+// ChainRootHash is the sha256 over the chain heads of every account, in account order
+func ChainRootHash(heads []ListLastEntryHashesRow) []byte {
+	h := sha256.New()
+	for _, head := range heads {
+		fmt.Fprintf(h, "%d|", head.AccountID)
+		h.Write(head.Hash)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_VerifyEntryChain(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+
+	var entries []Entry
+	var ledgers []EntryLedger
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		entries = append(entries, result.FromEntry)
+		ledgers = append(ledgers, EntryLedger{
+			Currency:         result.Postings[0].Currency,
+			PostingAccountID: result.Postings[0].AccountID,
+			PostingAmount:    result.Postings[0].Amount,
+			FromAccountID:    result.Transfer.FromAccountID,
+			ToAccountID:      result.Transfer.ToAccountID,
+			TransferAmount:   result.Transfer.Amount,
+		})
+	}
+
+	require.Nil(t, entries[0].PrevHash)
+	for i, entry := range entries {
+		require.Len(t, entry.Hash, 32)
+		require.Equal(t, EntryHash(entry, ledgers[i], entry.PrevHash), entry.Hash)
+		if i > 0 {
+			require.Equal(t, entries[i-1].Hash, entry.PrevHash)
+		}
+	}
+
+	chainBreak, err := store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Nil(t, chainBreak)
+
+	// an edit made straight in the database breaks the chain at the edited entry
+	_, err = testDB.Exec("UPDATE entries SET amount = amount - 1 WHERE id = $1", entries[1].ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, account1.ID, chainBreak.AccountID)
+	require.Equal(t, entries[1].ID, chainBreak.EntryID)
+
+	// the other account is untouched
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Nil(t, chainBreak)
+
+	// until the transfer behind its entries is edited
+	_, err = testDB.Exec("UPDATE transfers SET to_account_id = $1 WHERE id = $2", account1.ID, entries[0].TransferID.Int64)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, account2.ID, chainBreak.AccountID)
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrUnbalancedJournal = errors.New("journal entry postings don't sum to zero")
+
+// JournalLine is one side of a journal entry: a debit when negative, a credit when positive
+type JournalLine struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type journalResult struct {
+	JournalEntry JournalEntry
+	Postings     []Posting
+	// Entries are the account history rows of the postings, in the order of the lines
+	Entries  []Entry
+	Accounts map[int64]Account
+}
+
+// CheckBalanced makes sure the postings sum to zero in every currency.
+// The database checks it again when the transaction commits.
+func CheckBalanced(postings []Posting) error {
+	sums := map[string]int64{}
+	for _, posting := range postings {
+		sums[posting.Currency] += posting.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedJournal
+		}
+	}
+	return nil
+}
+
+// postJournal writes a journal entry with one posting and one sealed account entry per line,
+// and applies the lines to the balances. The balances are updated first: it locks the accounts
+// and tells their status and currencies, which the postings need before they can be checked.
+func postJournal(ctx context.Context, q *Queries, transfer Transfer, lines []JournalLine) (result journalResult, err error) {
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	changes := map[int64]int64{}
+	for _, line := range lines {
+		changes[line.AccountID] += line.Amount
+	}
+	result.Accounts, err = addBalances(ctx, q, changes)
+	if err != nil {
+		return
+	}
+	for _, account := range result.Accounts {
+		if err = checkAccountActive(account); err != nil {
+			return
+		}
+	}
+
+	postings := make([]Posting, len(lines))
+	for i, line := range lines {
+		postings[i] = Posting{
+			AccountID: line.AccountID,
+			Currency:  result.Accounts[line.AccountID].Currency,
+			Amount:    line.Amount,
+		}
+	}
+	if err = CheckBalanced(postings); err != nil {
+		return
+	}
+
+	result.JournalEntry, err = q.CreateJournalEntry(ctx, transferID)
+	if err != nil {
+		return
+	}
+
+	result.Postings = make([]Posting, len(lines))
+	result.Entries = make([]Entry, len(lines))
+	for i, posting := range postings {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  posting.AccountID,
+			Amount:     posting.Amount,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return
+		}
+		result.Entries[i], err = chainEntry(ctx, q, result.Entries[i], EntryLedger{
+			Currency:         posting.Currency,
+			PostingAccountID: posting.AccountID,
+			PostingAmount:    posting.Amount,
+			FromAccountID:    transfer.FromAccountID,
+			ToAccountID:      transfer.ToAccountID,
+			TransferAmount:   transfer.Amount,
+		})
+		if err != nil {
+			return
+		}
+
+		result.Postings[i], err = q.CreatePosting(ctx, CreatePostingParams{
+			JournalEntryID: result.JournalEntry.ID,
+			AccountID:      posting.AccountID,
+			EntryID:        sql.NullInt64{Int64: result.Entries[i].ID, Valid: true},
+			Currency:       posting.Currency,
+			Amount:         posting.Amount,
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCheckBalanced(t *testing.T) {
+	require.NoError(t, CheckBalanced([]Posting{
+		{Currency: util.USD, Amount: -10},
+		{Currency: util.USD, Amount: 10},
+		{Currency: util.EUR, Amount: -3},
+		{Currency: util.EUR, Amount: 3},
+	}))
+
+	// the total is zero but each currency is off
+	require.ErrorIs(t, CheckBalanced([]Posting{
+		{Currency: util.USD, Amount: -10},
+		{Currency: util.EUR, Amount: 10},
+	}), ErrUnbalancedJournal)
+}
+
+func TestStore_TransferTxJournal(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, result.JournalEntry.TransferID.Int64)
+	require.Len(t, result.Postings, 2)
+
+	journal, err := testQueries.GetJournalEntryByTransfer(context.Background(), result.JournalEntry.TransferID)
+	require.NoError(t, err)
+	require.Equal(t, result.JournalEntry.ID, journal.ID)
+
+	postings, err := testQueries.ListPostings(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.Len(t, postings, 2)
+	require.NoError(t, CheckBalanced(postings))
+
+	require.Equal(t, account1.ID, postings[0].AccountID)
+	require.Equal(t, int64(-10), postings[0].Amount)
+	require.Equal(t, result.FromEntry.ID, postings[0].EntryID.Int64)
+	require.Equal(t, account2.ID, postings[1].AccountID)
+	require.Equal(t, int64(10), postings[1].Amount)
+	require.Equal(t, result.ToEntry.ID, postings[1].EntryID.Int64)
+	for _, posting := range postings {
+		require.Equal(t, account1.Currency, posting.Currency)
+	}
+}
+
+func TestStore_TransferTxAcrossCurrencies(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	// nothing was written
+	updated, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+}
+
+func TestQueries_UnbalancedJournalRejectedOnCommit(t *testing.T) {
+	account := createRandomAccount(t)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	q := New(tx)
+
+	journal, err := q.CreateJournalEntry(context.Background(), sql.NullInt64{})
+	require.NoError(t, err)
+
+	// the check is deferred, the lone posting is accepted until the commit
+	_, err = q.CreatePosting(context.Background(), CreatePostingParams{
+		JournalEntryID: journal.ID,
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		Amount:         10,
+	})
+	require.NoError(t, err)
+
+	err = tx.Commit()
+	require.Error(t, err)
+	require.Equal(t, "check_violation", err.(*pq.Error).Code.Name())
+}
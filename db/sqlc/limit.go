@@ -0,0 +1,297 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	LimitScopeCurrency = "currency"
+	LimitScopeUser     = "user"
+	LimitScopeAccount  = "account"
+
+	LimitPeriodDay   = "day"
+	LimitPeriodWeek  = "week"
+	LimitPeriodMonth = "month"
+)
+
+// LimitPeriods lists the periods transfers are counted over, shortest first
+var LimitPeriods = []string{LimitPeriodDay, LimitPeriodWeek, LimitPeriodMonth}
+
+var ErrLimitExceeded = errors.New("transfer limit exceeded")
+
+// LimitWindowStart returns the UTC start of the period containing t: midnight, Monday or the first of the month
+func LimitWindowStart(period string, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case LimitPeriodWeek:
+		// Monday opens the week
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case LimitPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// LimitUsage is what a counter used in the current window of a period, against the limit that applies to it if any
+type LimitUsage struct {
+	// Scope is account or user, the counter the usage is read from
+	Scope       string    `json:"scope"`
+	Currency    string    `json:"currency"`
+	Period      string    `json:"period"`
+	WindowStart time.Time `json:"window_start"`
+	UsedAmount  int64     `json:"used_amount"`
+	UsedCount   int64     `json:"used_count"`
+	// LimitID is zero and the maximums are left out when no limit applies
+	LimitID         int64  `json:"limit_id,omitempty"`
+	MaxAmount       *int64 `json:"max_amount,omitempty"`
+	MaxCount        *int64 `json:"max_count,omitempty"`
+	RemainingAmount *int64 `json:"remaining_amount,omitempty"`
+	RemainingCount  *int64 `json:"remaining_count,omitempty"`
+}
+
+func newLimitUsage(counter TransferLimitCounter, limit *TransferLimit) LimitUsage {
+	usage := LimitUsage{
+		Scope:       counter.Scope,
+		Currency:    counter.Currency,
+		Period:      counter.Period,
+		WindowStart: counter.WindowStart,
+		UsedAmount:  counter.Amount,
+		UsedCount:   counter.Count,
+	}
+	if limit == nil {
+		return usage
+	}
+
+	usage.LimitID = limit.ID
+	if limit.MaxAmount.Valid {
+		remaining := remainingAllowance(limit.MaxAmount.Int64, counter.Amount)
+		usage.MaxAmount = &limit.MaxAmount.Int64
+		usage.RemainingAmount = &remaining
+	}
+	if limit.MaxCount.Valid {
+		remaining := remainingAllowance(limit.MaxCount.Int64, counter.Count)
+		usage.MaxCount = &limit.MaxCount.Int64
+		usage.RemainingCount = &remaining
+	}
+	return usage
+}
+
+func remainingAllowance(max, used int64) int64 {
+	if used >= max {
+		return 0
+	}
+	return max - used
+}
+
+// exceeded tells whether the usage went over one of the maximums
+func (usage LimitUsage) exceeded() bool {
+	return (usage.MaxAmount != nil && usage.UsedAmount > *usage.MaxAmount) ||
+		(usage.MaxCount != nil && usage.UsedCount > *usage.MaxCount)
+}
+
+var periodAdjectives = map[string]string{
+	LimitPeriodDay:   "daily",
+	LimitPeriodWeek:  "weekly",
+	LimitPeriodMonth: "monthly",
+}
+
+// LimitExceededError rejects a transfer going over a limit, with what was left of the allowance before it
+type LimitExceededError struct {
+	Usage LimitUsage
+}
+
+func (e *LimitExceededError) Error() string {
+	msg := fmt.Sprintf("%s %s transfer limit exceeded", periodAdjectives[e.Usage.Period], e.Usage.Scope)
+	if e.Usage.RemainingAmount != nil {
+		msg += fmt.Sprintf(", %d %s remaining", *e.Usage.RemainingAmount, e.Usage.Currency)
+	}
+	if e.Usage.RemainingCount != nil {
+		msg += fmt.Sprintf(", %d transfers remaining", *e.Usage.RemainingCount)
+	}
+	return msg
+}
+
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// limitCounter identifies the usage counter of an account, or of a user in a currency
+type limitCounter struct {
+	Scope    string
+	Subject  string
+	Currency string
+}
+
+func accountLimitCounter(account Account) limitCounter {
+	return limitCounter{Scope: LimitScopeAccount, Subject: strconv.FormatInt(account.ID, 10), Currency: account.Currency}
+}
+
+// userLimitCounter counts what the user spends in the currency of the account, from any account they hold.
+// An empty username stands for the account owner.
+func userLimitCounter(account Account, username string) limitCounter {
+	if username == "" {
+		username = account.Owner
+	}
+	return limitCounter{Scope: LimitScopeUser, Subject: username, Currency: account.Currency}
+}
+
+// applicableLimits returns the limit of each period for the account counter and the counter of the user acting on it.
+// A limit set on the account replaces the currency one for the same period.
+func applicableLimits(ctx context.Context, q *Queries, account Account, username string) (map[limitCounter]map[string]*TransferLimit, error) {
+	userCounter := userLimitCounter(account, username)
+	limits, err := q.ListApplicableTransferLimits(ctx, ListApplicableTransferLimitsParams{
+		Currency:       account.Currency,
+		AccountSubject: strconv.FormatInt(account.ID, 10),
+		Username:       userCounter.Subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accountCounter := accountLimitCounter(account)
+	applicable := map[limitCounter]map[string]*TransferLimit{
+		accountCounter: {},
+		userCounter:    {},
+	}
+	for i := range limits {
+		limit := &limits[i]
+		switch limit.Scope {
+		case LimitScopeUser:
+			applicable[userCounter][limit.Period] = limit
+		case LimitScopeAccount:
+			applicable[accountCounter][limit.Period] = limit
+		case LimitScopeCurrency:
+			if _, ok := applicable[accountCounter][limit.Period]; !ok {
+				applicable[accountCounter][limit.Period] = limit
+			}
+		}
+	}
+	return applicable, nil
+}
+
+// applyTransferLimits counts the amounts leaving the accounts against their limits and the ones of the users making them.
+// Each counter row stays locked until the transaction ends, so concurrent transfers can't both use
+// the last of an allowance. Counters are locked in a fixed order, always after the accounts.
+func applyTransferLimits(ctx context.Context, q *Queries, transfers []TransferTxParams, accounts map[int64]Account, now time.Time) error {
+	type increment struct {
+		amount int64
+		count  int64
+		limits map[string]*TransferLimit
+	}
+	increments := make(map[limitCounter]*increment)
+
+	for _, t := range transfers {
+		if t.Internal {
+			continue
+		}
+		account := accounts[t.FromAccountID]
+
+		var limits map[limitCounter]map[string]*TransferLimit
+		for _, counter := range []limitCounter{accountLimitCounter(account), userLimitCounter(account, t.Actor)} {
+			inc, ok := increments[counter]
+			if !ok {
+				if limits == nil {
+					var err error
+					if limits, err = applicableLimits(ctx, q, account, t.Actor); err != nil {
+						return err
+					}
+				}
+				inc = &increment{limits: limits[counter]}
+				increments[counter] = inc
+			}
+			inc.amount += t.Amount
+			inc.count++
+		}
+	}
+
+	counters := make([]limitCounter, 0, len(increments))
+	for counter := range increments {
+		counters = append(counters, counter)
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		a, b := counters[i], counters[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		return a.Currency < b.Currency
+	})
+
+	for _, counter := range counters {
+		inc := increments[counter]
+		for _, period := range LimitPeriods {
+			updated, err := q.AddTransferLimitUsage(ctx, AddTransferLimitUsageParams{
+				Scope:       counter.Scope,
+				Subject:     counter.Subject,
+				Currency:    counter.Currency,
+				Period:      period,
+				WindowStart: LimitWindowStart(period, now),
+				Amount:      inc.amount,
+				Count:       inc.count,
+			})
+			if err != nil {
+				return err
+			}
+
+			usage := newLimitUsage(updated, inc.limits[period])
+			if usage.exceeded() {
+				// report the allowance as it was before these transfers
+				updated.Amount -= inc.amount
+				updated.Count -= inc.count
+				return &LimitExceededError{Usage: newLimitUsage(updated, inc.limits[period])}
+			}
+		}
+	}
+	return nil
+}
+
+// TransferLimitUsage returns the usage of the account and of the user in the account's currency,
+// in the current window of every period. An empty username stands for the account owner.
+func (store *SQLStore) TransferLimitUsage(ctx context.Context, account Account, username string, now time.Time) ([]LimitUsage, error) {
+	var usages []LimitUsage
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		limits, err := applicableLimits(ctx, queries, account, username)
+		if err != nil {
+			return err
+		}
+
+		usages = make([]LimitUsage, 0, 2*len(LimitPeriods))
+		for _, counter := range []limitCounter{accountLimitCounter(account), userLimitCounter(account, username)} {
+			for _, period := range LimitPeriods {
+				arg := GetTransferLimitCounterParams{
+					Scope:       counter.Scope,
+					Subject:     counter.Subject,
+					Currency:    counter.Currency,
+					Period:      period,
+					WindowStart: LimitWindowStart(period, now),
+				}
+				current, err := queries.GetTransferLimitCounter(ctx, arg)
+				if err != nil {
+					if err != sql.ErrNoRows {
+						return err
+					}
+					current = TransferLimitCounter{
+						Scope:       arg.Scope,
+						Subject:     arg.Subject,
+						Currency:    arg.Currency,
+						Period:      arg.Period,
+						WindowStart: arg.WindowStart,
+					}
+				}
+				usages = append(usages, newLimitUsage(current, limits[counter][period]))
+			}
+		}
+		return nil
+	})
+	return usages, err
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLimitWindowStart(t *testing.T) {
+	// a Thursday
+	now := time.Date(2024, time.February, 29, 17, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	require.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodDay, now))
+	require.Equal(t, time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodWeek, now))
+	require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodMonth, now))
+
+	// late in the evening it is already the next day, and month, in UTC
+	now = time.Date(2024, time.February, 29, 21, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodDay, now))
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodMonth, now))
+
+	// Sunday still belongs to the week opened on Monday
+	sunday := time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC), LimitWindowStart(LimitPeriodWeek, sunday))
+}
+
+func TestLimitExceededError(t *testing.T) {
+	amount, count := int64(250), int64(0)
+	err := error(&LimitExceededError{Usage: LimitUsage{
+		Scope:           LimitScopeUser,
+		Currency:        "EUR",
+		Period:          LimitPeriodWeek,
+		RemainingAmount: &amount,
+		RemainingCount:  &count,
+	}})
+
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.EqualError(t, err, "weekly user transfer limit exceeded, 250 EUR remaining, 0 transfers remaining")
+}
+
+func TestStore_TransferLimits(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	subject := strconv.FormatInt(account1.ID, 10)
+
+	// the account limit replaces the currency one, whatever is set for other accounts
+	_, err := testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Scope:    LimitScopeAccount,
+		Subject:  subject,
+		Currency: account1.Currency,
+		Period:   LimitPeriodDay,
+		MaxCount: sql.NullInt64{Int64: 2, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Scope:     LimitScopeUser,
+		Subject:   account1.Owner,
+		Currency:  account1.Currency,
+		Period:    LimitPeriodMonth,
+		MaxAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60, Currency: account1.Currency}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the monthly allowance of the user has 40 left
+	_, err = store.TransferTx(context.Background(), arg)
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitScopeUser, limitErr.Usage.Scope)
+	require.Equal(t, LimitPeriodMonth, limitErr.Usage.Period)
+	require.Equal(t, int64(40), *limitErr.Usage.RemainingAmount)
+
+	arg.Amount = 40
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the daily count of the account is used up
+	arg.Amount = 1
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitScopeAccount, limitErr.Usage.Scope)
+	require.Equal(t, int64(0), *limitErr.Usage.RemainingCount)
+
+	// internal moves don't count
+	pot, err := testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Holidays", ParentID: account1.ID})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: pot.ID, Amount: 1, Internal: true})
+	require.NoError(t, err)
+
+	usages, err := store.TransferLimitUsage(context.Background(), account1, "", time.Now())
+	require.NoError(t, err)
+	require.Len(t, usages, 6)
+	for _, usage := range usages {
+		require.Equal(t, int64(100), usage.UsedAmount)
+		require.Equal(t, int64(2), usage.UsedCount)
+	}
+	require.Equal(t, int64(0), *usages[0].RemainingCount)
+	require.Nil(t, usages[0].MaxAmount)
+	require.Equal(t, int64(0), *usages[5].RemainingAmount)
+}
+
+func TestStore_TransferLimitsOfTheActor(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	coHolder := util.RandomOwner()
+
+	_, err := testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Scope:     LimitScopeUser,
+		Subject:   coHolder,
+		Currency:  account1.Currency,
+		Period:    LimitPeriodDay,
+		MaxAmount: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30, Currency: account1.Currency, Actor: coHolder}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the co-holder has 20 left, whoever owns the account
+	_, err = store.TransferTx(context.Background(), arg)
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitScopeUser, limitErr.Usage.Scope)
+	require.Equal(t, int64(20), *limitErr.Usage.RemainingAmount)
+
+	// the owner spends from their own allowance
+	arg.Actor = account1.Owner
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	usages, err := store.TransferLimitUsage(context.Background(), account1, coHolder, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, LimitScopeUser, usages[3].Scope)
+	require.Equal(t, int64(30), usages[3].UsedAmount)
+	require.Equal(t, int64(20), *usages[3].RemainingAmount)
+
+	usages, err = store.TransferLimitUsage(context.Background(), account1, "", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, int64(60), usages[0].UsedAmount)
+	require.Equal(t, int64(30), usages[3].UsedAmount)
+}
@@ -3,23 +3,383 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type AccountHolder struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// primary, co_owner or viewer, the primary holder is the account owner
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
+type AccountInvitation struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	Invitee   string `json:"invitee"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+	// pending, accepted or declined
+	Status      string       `json:"status"`
+	RespondedAt sql.NullTime `json:"responded_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	TenantID    int64        `json:"tenant_id"`
+}
+
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	// the user who made the change
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
 type Account struct {
 	ID        int64     `json:"id"`
 	Owner     string    `json:"owner"`
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// sum of pending holds, not available for spending
+	HeldBalance int64 `json:"held_balance"`
+	// active, frozen or closed, a closed account never reopens
+	Status   string `json:"status"`
+	Nickname string `json:"nickname"`
+	// how far below zero holds may take the available balance, set by bankers
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// checking or savings, the interest rate depends on it, or escrow for the house accounts holding escrowed funds
+	Type string `json:"type"`
+	// set on pots, the sub-accounts sharing the owner, currency and holders of their parent
+	ParentID sql.NullInt64 `json:"parent_id"`
+	TenantID int64         `json:"tenant_id"`
+}
+
+type BalanceSnapshot struct {
+	AccountID  int64     `json:"account_id"`
+	SnapshotAt time.Time `json:"snapshot_at"`
+	// balance including every entry created up to snapshot_at
+	Balance   int64     `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
+type Beneficiary struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	// the currency of the account, transfers to the beneficiary must use it
+	Currency string `json:"currency"`
+	// large amounts can only be sent to the beneficiary from then on
+	CoolingOffUntil time.Time `json:"cooling_off_until"`
+	CreatedAt       time.Time `json:"created_at"`
+	TenantID        int64     `json:"tenant_id"`
+}
+
+type ChainRoot struct {
+	Day time.Time `json:"day"`
+	// sha256 over the last entry hash of every account at the end of the day
+	RootHash  []byte    `json:"root_hash"`
+	Signature []byte    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or positive
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	PrevHash   []byte        `json:"prev_hash"`
+	// sha256 of the entry and prev_hash, the hash of the previous entry of the account
+	Hash     []byte `json:"hash"`
+	TenantID int64  `json:"tenant_id"`
+}
+
+type EscrowAgreement struct {
+	ID              int64  `json:"id"`
+	Buyer           string `json:"buyer"`
+	BuyerAccountID  int64  `json:"buyer_account_id"`
+	SellerAccountID int64  `json:"seller_account_id"`
+	EscrowAccountID int64  `json:"escrow_account_id"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	// what the seller must do before the buyer releases the funds
+	Condition string `json:"condition"`
+	// funded until released to the seller or refunded to the buyer, disputed agreements wait for a banker
+	Status string `json:"status"`
+	// funds neither released nor disputed by then are refunded to the buyer
+	Deadline             time.Time      `json:"deadline"`
+	FundingTransferID    sql.NullInt64  `json:"funding_transfer_id"`
+	SettlementTransferID sql.NullInt64  `json:"settlement_transfer_id"`
+	DisputedBy           sql.NullString `json:"disputed_by"`
+	DisputeReason        string         `json:"dispute_reason"`
+	// empty when the deadline refunded the buyer
+	ResolvedBy sql.NullString `json:"resolved_by"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	TenantID   int64          `json:"tenant_id"`
+}
+
+type FeeRule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// lower bound of the tier, the highest one not above the amount applies
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// percentage of the amount in basis points
+	PercentageBps int64     `json:"percentage_bps"`
+	CreatedAt     time.Time `json:"created_at"`
+	TenantID      int64     `json:"tenant_id"`
+}
+
+type FraudDecision struct {
+	ID            int64           `json:"id"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Currency      string          `json:"currency"`
+	Decision      string          `json:"decision"`
+	Score         int64           `json:"score"`
+	MatchedRules  json.RawMessage `json:"matched_rules"`
+	// the transfer as requested, performed when a review is approved
+	Request json.RawMessage `json:"request"`
+	// pending, approved or rejected, only set on reviews
+	ReviewStatus sql.NullString `json:"review_status"`
+	ReviewedBy   sql.NullString `json:"reviewed_by"`
+	ReviewedAt   sql.NullTime   `json:"reviewed_at"`
+	TransferID   sql.NullInt64  `json:"transfer_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	TenantID     int64          `json:"tenant_id"`
+}
+
+type FraudRule struct {
+	ID int64 `json:"id"`
+	// a rule named like one of the rules file replaces it
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	Score  int64  `json:"score"`
+	// the rule applies to every currency when empty
+	Currency      string    `json:"currency"`
+	MinAmount     int64     `json:"min_amount"`
+	MinCount      int64     `json:"min_count"`
+	WindowSeconds int64     `json:"window_seconds"`
+	RoundTo       int64     `json:"round_to"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	TenantID      int64     `json:"tenant_id"`
+}
+
+type Hold struct {
+	ID             int64 `json:"id"`
+	FromAccountID  int64 `json:"from_account_id"`
+	ToAccountID    int64 `json:"to_account_id"`
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// pending, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	TenantID   int64         `json:"tenant_id"`
+	// the fraud assessment that allowed the hold, recorded with the transfer capturing it
+	Screening json.RawMessage `json:"screening"`
+	// payment for holds captured by their recipient, approval for the ones reserving a transfer waiting for approval
+	Purpose string `json:"purpose"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// balance at the end of the accrual date
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int64 `json:"annual_rate_bps"`
+	Amount        int64 `json:"amount"`
+	// fraction of a minor unit left over after rounding, in 1/3650000ths
+	Carry     int64     `json:"carry"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the month the interest was accrued in
+	Period time.Time `json:"period"`
+	Amount int64     `json:"amount"`
+	// empty when nothing was accrued
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TenantID   int64         `json:"tenant_id"`
+}
+
+type InterestRate struct {
+	ID            int64     `json:"id"`
+	Currency      string    `json:"currency"`
+	AccountType   string    `json:"account_type"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	CreatedAt     time.Time `json:"created_at"`
+	TenantID      int64     `json:"tenant_id"`
+}
+
+type JournalEntry struct {
+	ID         int64         `json:"id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TenantID   int64         `json:"tenant_id"`
+}
+
+type PaymentRequestPayer struct {
+	ID               int64  `json:"id"`
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Payer            string `json:"payer"`
+	// the share of the request this payer is asked for
+	Amount int64  `json:"amount"`
+	Status string `json:"status"`
+	// the transfer paying the share once accepted
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	TenantID   int64         `json:"tenant_id"`
+}
+
+type PaymentRequest struct {
+	ID          int64  `json:"id"`
+	Requester   string `json:"requester"`
+	ToAccountID int64  `json:"to_account_id"`
+	// the total of the shares of the payers
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+	// pending until every payer answered: settled when one paid, declined when none did, or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
+type PendingTransferEvent struct {
+	ID                int64 `json:"id"`
+	PendingTransferID int64 `json:"pending_transfer_id"`
+	// requested, approved, rejected or expired
+	Action string `json:"action"`
+	// empty when the system expired the transfer
+	Actor     sql.NullString `json:"actor"`
+	Note      string         `json:"note"`
+	CreatedAt time.Time      `json:"created_at"`
+	TenantID  int64          `json:"tenant_id"`
+}
+
+type PendingTransfer struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// the transfer as requested, performed once approved
+	Request json.RawMessage `json:"request"`
+	// the fraud assessment that allowed the transfer, null when it was not screened
+	Screening json.RawMessage `json:"screening"`
+	Maker     string          `json:"maker"`
+	// pending, approved, rejected or expired
+	Status string `json:"status"`
+	// reserves the amount on the sender while pending
+	HoldID     int64         `json:"hold_id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	TenantID   int64         `json:"tenant_id"`
+}
+
+type Posting struct {
+	ID             int64 `json:"id"`
+	JournalEntryID int64 `json:"journal_entry_id"`
+	AccountID      int64 `json:"account_id"`
+	// the account entry this posting shows up as in the history
+	EntryID  sql.NullInt64 `json:"entry_id"`
+	Currency string        `json:"currency"`
+	// debits are negative, the postings of a journal entry sum to zero per currency
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"tenant_id"`
+}
+
+type ReconciliationFinding struct {
+	ID       int64 `json:"id"`
+	ReportID int64 `json:"report_id"`
+	// balance_drift, orphan_entry or unbalanced_transfer
+	Kind       string        `json:"kind"`
+	AccountID  sql.NullInt64 `json:"account_id"`
+	EntryID    sql.NullInt64 `json:"entry_id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// entries total for a drift, zero for an orphan entry, amount plus fees for a transfer
+	Expected   int64        `json:"expected"`
+	Actual     int64        `json:"actual"`
+	RepairedAt sql.NullTime `json:"repaired_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	TenantID   int64        `json:"tenant_id"`
+}
+
+type ReconciliationReport struct {
+	ID int64 `json:"id"`
+	// clean, open or repaired
+	Status        string         `json:"status"`
+	FindingsCount int64          `json:"findings_count"`
+	ReviewedBy    sql.NullString `json:"reviewed_by"`
+	ReviewedAt    sql.NullTime   `json:"reviewed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	TenantID      int64          `json:"tenant_id"`
+}
+
+type Tenant struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// the host name the brand is served on, empty when it is only reached with its tokens
+	Host sql.NullString `json:"host"`
+	// the currencies accounts can be opened in
+	Currencies []string  `json:"currencies"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type TransferLimitCounter struct {
+	// account or user, currency limits count against the account
+	Scope       string    `json:"scope"`
+	Subject     string    `json:"subject"`
+	Currency    string    `json:"currency"`
+	Period      string    `json:"period"`
+	WindowStart time.Time `json:"window_start"`
+	Amount      int64     `json:"amount"`
+	Count       int64     `json:"count"`
+	TenantID    int64     `json:"tenant_id"`
+}
+
+type TransferLimit struct {
+	ID int64 `json:"id"`
+	// currency limits apply to every account in the currency unless the account has its own
+	Scope string `json:"scope"`
+	// the account id or the username the limit applies to, empty for currency limits
+	Subject   string        `json:"subject"`
+	Currency  string        `json:"currency"`
+	Period    string        `json:"period"`
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	MaxCount  sql.NullInt64 `json:"max_count"`
+	CreatedAt time.Time     `json:"created_at"`
+	TenantID  int64         `json:"tenant_id"`
 }
 
 type Transfer struct {
@@ -29,6 +389,16 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// set when this transfer reverses another one
+	OriginalTransferID sql.NullInt64 `json:"original_transfer_id"`
+	FeeRuleID          sql.NullInt64 `json:"fee_rule_id"`
+	FlatFee            int64         `json:"flat_fee"`
+	PercentageFee      int64         `json:"percentage_fee"`
+	Description        string        `json:"description"`
+	// client supplied, unique per sender account
+	ExternalReference sql.NullString  `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	TenantID          int64           `json:"tenant_id"`
 }
 
 type User struct {
@@ -38,4 +408,10 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	// chosen by the user to be paid by, unique regardless of case
+	Alias sql.NullString `json:"alias"`
+	// only a verified email can be paid to
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+	TenantID        int64        `json:"tenant_id"`
 }
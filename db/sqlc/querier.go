@@ -4,24 +4,161 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	AddTransferLimitUsage(ctx context.Context, arg AddTransferLimitUsageParams) (TransferLimitCounter, error)
+	CompleteFraudReview(ctx context.Context, arg CompleteFraudReviewParams) (FraudDecision, error)
+	CountRoundTransfersSince(ctx context.Context, arg CountRoundTransfersSinceParams) (int64, error)
+	CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error)
+	CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountHolder(ctx context.Context, arg CreateAccountHolderParams) (AccountHolder, error)
+	CreateAccountInvitation(ctx context.Context, arg CreateAccountInvitationParams) (AccountInvitation, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
+	CreateBalanceSnapshots(ctx context.Context, snapshotAt time.Time) (int64, error)
+	CreateBeneficiary(ctx context.Context, arg CreateBeneficiaryParams) (Beneficiary, error)
+	CreateChainRoot(ctx context.Context, arg CreateChainRootParams) (int64, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEscrowAgreement(ctx context.Context, arg CreateEscrowAgreementParams) (EscrowAgreement, error)
+	CreateFraudDecision(ctx context.Context, arg CreateFraudDecisionParams) (FraudDecision, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateHouseUser(ctx context.Context, arg CreateHouseUserParams) error
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateJournalEntry(ctx context.Context, transferID sql.NullInt64) (JournalEntry, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePaymentRequestPayer(ctx context.Context, arg CreatePaymentRequestPayerParams) (PaymentRequestPayer, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreatePendingTransferEvent(ctx context.Context, arg CreatePendingTransferEventParams) (PendingTransferEvent, error)
+	CreatePosting(ctx context.Context, arg CreatePostingParams) (Posting, error)
+	CreatePot(ctx context.Context, arg CreatePotParams) (Account, error)
+	CreateReconciliationFinding(ctx context.Context, arg CreateReconciliationFindingParams) (ReconciliationFinding, error)
+	CreateReconciliationReport(ctx context.Context, arg CreateReconciliationReportParams) (ReconciliationReport, error)
+	CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountHolder(ctx context.Context, arg DeleteAccountHolderParams) error
+	DeleteBeneficiary(ctx context.Context, id int64) error
+	DeleteFeeRule(ctx context.Context, id int64) error
+	DeleteFraudRule(ctx context.Context, id int64) error
+	DeleteTransferLimit(ctx context.Context, id int64) error
+	DisputeEscrowAgreement(ctx context.Context, arg DisputeEscrowAgreementParams) (EscrowAgreement, error)
+	ExpirePaymentRequestPayers(ctx context.Context, paymentRequestID int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAsOf(ctx context.Context, arg GetAccountBalanceAsOfParams) (int64, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountHolder(ctx context.Context, arg GetAccountHolderParams) (AccountHolder, error)
+	GetAccountInvitation(ctx context.Context, id int64) (AccountInvitation, error)
+	GetAccountInvitationForUpdate(ctx context.Context, id int64) (AccountInvitation, error)
+	GetBeneficiary(ctx context.Context, id int64) (Beneficiary, error)
+	GetBeneficiaryByAccount(ctx context.Context, arg GetBeneficiaryByAccountParams) (Beneficiary, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEscrowAgreement(ctx context.Context, id int64) (EscrowAgreement, error)
+	GetEscrowAgreementForUpdate(ctx context.Context, id int64) (EscrowAgreement, error)
+	GetFeeRule(ctx context.Context, arg GetFeeRuleParams) (FeeRule, error)
+	GetFraudDecision(ctx context.Context, id int64) (FraudDecision, error)
+	GetFraudDecisionForUpdate(ctx context.Context, id int64) (FraudDecision, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetInterestAccrual(ctx context.Context, arg GetInterestAccrualParams) (InterestAccrual, error)
+	GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error)
+	GetInterestRate(ctx context.Context, arg GetInterestRateParams) (InterestRate, error)
+	GetJournalEntryByTransfer(ctx context.Context, transferID sql.NullInt64) (JournalEntry, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (BalanceSnapshot, error)
+	GetPayeeAccount(ctx context.Context, arg GetPayeeAccountParams) (Account, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestPayerForUpdate(ctx context.Context, arg GetPaymentRequestPayerForUpdateParams) (PaymentRequestPayer, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferByTransfer(ctx context.Context, transferID sql.NullInt64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPreviousEntryHash(ctx context.Context, arg GetPreviousEntryHashParams) ([]byte, error)
+	GetPreviousInterestAccrual(ctx context.Context, arg GetPreviousInterestAccrualParams) (InterestAccrual, error)
+	GetReconciliationReport(ctx context.Context, id int64) (ReconciliationReport, error)
+	GetReconciliationReportForUpdate(ctx context.Context, id int64) (ReconciliationReport, error)
+	GetReversedAmount(ctx context.Context, originalTransferID sql.NullInt64) (int64, error)
+	GetTenant(ctx context.Context, id int64) (Tenant, error)
+	GetTenantByHost(ctx context.Context, host sql.NullString) (Tenant, error)
+	GetTenantBySlug(ctx context.Context, slug string) (Tenant, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferByExternalReference(ctx context.Context, arg GetTransferByExternalReferenceParams) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimitCounter(ctx context.Context, arg GetTransferLimitCounterParams) (TransferLimitCounter, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByAlias(ctx context.Context, lower string) (User, error)
+	GetUserByVerifiedEmail(ctx context.Context, lower string) (User, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	ListAccountHolders(ctx context.Context, accountID int64) ([]AccountHolder, error)
+	ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error)
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]ListAccountTransfersRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAccountsToAccrue(ctx context.Context, arg ListAccountsToAccrueParams) ([]ListAccountsToAccrueRow, error)
+	ListApplicableTransferLimits(ctx context.Context, arg ListApplicableTransferLimitsParams) ([]TransferLimit, error)
+	ListBalanceDrifts(ctx context.Context) ([]ListBalanceDriftsRow, error)
+	ListBeneficiaries(ctx context.Context, arg ListBeneficiariesParams) ([]Beneficiary, error)
+	ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]ListChainEntriesRow, error)
+	ListChainRoots(ctx context.Context, arg ListChainRootsParams) ([]ChainRoot, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEscrowAgreements(ctx context.Context, arg ListEscrowAgreementsParams) ([]EscrowAgreement, error)
+	ListExpiredHolds(ctx context.Context, limit int32) ([]Hold, error)
+	ListExpiredPaymentRequests(ctx context.Context, limit int32) ([]PaymentRequest, error)
+	ListExpiredPendingTransfers(ctx context.Context, limit int32) ([]PendingTransfer, error)
+	ListFeeRules(ctx context.Context) ([]FeeRule, error)
+	ListFraudDecisions(ctx context.Context, arg ListFraudDecisionsParams) ([]FraudDecision, error)
+	ListFraudRules(ctx context.Context) ([]FraudRule, error)
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	ListLastEntryHashes(ctx context.Context, until time.Time) ([]ListLastEntryHashesRow, error)
+	ListOrphanEntries(ctx context.Context) ([]Entry, error)
+	ListOverdueEscrowAgreements(ctx context.Context, limit int32) ([]EscrowAgreement, error)
+	ListPaymentRequestPayers(ctx context.Context, paymentRequestID int64) ([]PaymentRequestPayer, error)
+	ListPaymentRequestsByPayer(ctx context.Context, arg ListPaymentRequestsByPayerParams) ([]PaymentRequest, error)
+	ListPaymentRequestsByRequester(ctx context.Context, arg ListPaymentRequestsByRequesterParams) ([]PaymentRequest, error)
+	ListPendingAccountInvitations(ctx context.Context, invitee string) ([]AccountInvitation, error)
+	ListPendingTransferEvents(ctx context.Context, pendingTransferID int64) ([]PendingTransferEvent, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]PendingTransfer, error)
+	ListPostings(ctx context.Context, journalEntryID int64) ([]Posting, error)
+	ListPots(ctx context.Context, parentID int64) ([]Account, error)
+	ListPotsForUpdate(ctx context.Context, parentID int64) ([]Account, error)
+	ListReconciliationFindings(ctx context.Context, reportID int64) ([]ReconciliationFinding, error)
+	ListReconciliationReports(ctx context.Context, arg ListReconciliationReportsParams) ([]ReconciliationReport, error)
+	ListTenants(ctx context.Context) ([]Tenant, error)
+	ListTransferLimits(ctx context.Context) ([]TransferLimit, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	ListUnpostedInterestPeriods(ctx context.Context, arg ListUnpostedInterestPeriodsParams) ([]ListUnpostedInterestPeriodsRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error)
+	MarkReconciliationFindingRepaired(ctx context.Context, id int64) (ReconciliationFinding, error)
+	ResetAccountBalanceToEntries(ctx context.Context, id int64) (Account, error)
+	RespondAccountInvitation(ctx context.Context, arg RespondAccountInvitationParams) (AccountInvitation, error)
+	ReviewReconciliationReport(ctx context.Context, arg ReviewReconciliationReportParams) (ReconciliationReport, error)
+	SealEntry(ctx context.Context, arg SealEntryParams) (Entry, error)
+	SetEscrowFundingTransfer(ctx context.Context, arg SetEscrowFundingTransferParams) (EscrowAgreement, error)
+	SetUserAlias(ctx context.Context, arg SetUserAliasParams) (User, error)
+	SettleEscrowAgreement(ctx context.Context, arg SettleEscrowAgreementParams) (EscrowAgreement, error)
+	SumEntriesBetween(ctx context.Context, arg SumEntriesBetweenParams) (int64, error)
+	SumInterestAccruals(ctx context.Context, arg SumInterestAccrualsParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateBeneficiary(ctx context.Context, arg UpdateBeneficiaryParams) (Beneficiary, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdatePaymentRequestPayer(ctx context.Context, arg UpdatePaymentRequestPayerParams) (PaymentRequestPayer, error)
+	UpdatePaymentRequestStatus(ctx context.Context, arg UpdatePaymentRequestStatusParams) (PaymentRequest, error)
+	UpdatePendingTransferStatus(ctx context.Context, arg UpdatePendingTransferStatusParams) (PendingTransfer, error)
+	UpdateTenant(ctx context.Context, arg UpdateTenantParams) (Tenant, error)
+	UpsertFeeRule(ctx context.Context, arg UpsertFeeRuleParams) (FeeRule, error)
+	UpsertFraudRule(ctx context.Context, arg UpsertFraudRuleParams) (FraudRule, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	VerifyUserEmail(ctx context.Context, username string) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -3,16 +3,54 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 )
 
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	QuoteFee(ctx context.Context, currency string, amount int64) (Fee, error)
+	GetBalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (int64, error)
+	VerifyEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error)
+	TransferLimitUsage(ctx context.Context, account Account, username string, now time.Time) ([]LimitUsage, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (PendingTransferTxResult, error)
+	ReviewPendingTransferTx(ctx context.Context, arg ReviewPendingTransferTxParams) (PendingTransferTxResult, error)
+	ExpirePendingTransferTx(ctx context.Context, pendingTransferID int64) (PendingTransferTxResult, error)
+	CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestTxParams) (PaymentRequestTxResult, error)
+	AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (PaymentRequestTxResult, error)
+	DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequestTxResult, error)
+	ExpirePaymentRequestTx(ctx context.Context, paymentRequestID int64) (PaymentRequestTxResult, error)
+	CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (EscrowTxResult, error)
+	ReleaseEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error)
+	RefundEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error)
+	DisputeEscrowTx(ctx context.Context, arg DisputeEscrowTxParams) (EscrowTxResult, error)
+	ExpireEscrowTx(ctx context.Context, agreementID int64) (EscrowTxResult, error)
+	RecordFraudDecision(ctx context.Context, arg TransferTxParams, assessment FraudAssessment) (FraudDecision, error)
+	ReviewFraudDecisionTx(ctx context.Context, arg ReviewFraudDecisionTxParams) (ReviewFraudDecisionTxResult, error)
+	RespondAccountInvitationTx(ctx context.Context, arg RespondAccountInvitationTxParams) (RespondAccountInvitationTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	ReconcileTx(ctx context.Context) (ReconciliationResult, error)
+	RepairReconciliationTx(ctx context.Context, arg RepairReconciliationTxParams) (ReconciliationResult, error)
+	CreateTenantTx(ctx context.Context, arg CreateTenantTxParams) (TenantTxResult, error)
+	UpdateTenantTx(ctx context.Context, arg UpdateTenantParams) (TenantTxResult, error)
 }
 
-// SQLStore provides all functions to execute SQL queries and transactions
+//go:generate go run ../storegen
+
+// SQLStore provides all functions to execute SQL queries and transactions.
+// Every query runs in a transaction scoped to the tenant of its context, see store_querier.go.
 type SQLStore struct {
 	*Queries
 	db *sql.DB
@@ -26,9 +64,36 @@ func NewStore(db *sql.DB) Store {
 }
 
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Description   string `json:"description"`
+	// ExternalReference is optional, when set it must be unique among the sender's transfers
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	// Internal marks a move between an account and its pots, which is free
+	Internal bool `json:"internal"`
+	// Actor is the holder making the transfer, whose user limits it counts against.
+	// Transfers nobody makes on the spot, like captures and the ones the bank makes, count against the account owner.
+	Actor string `json:"actor"`
+	// Screening is the fraud assessment that allowed the transfer, recorded along with it when set
+	Screening *FraudAssessment `json:"-"`
+}
+
+// createTransferParams maps the transfer request to the insert params, without fees
+func (arg TransferTxParams) createTransferParams() CreateTransferParams {
+	return CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Description:   arg.Description,
+		ExternalReference: sql.NullString{
+			String: arg.ExternalReference,
+			Valid:  arg.ExternalReference != "",
+		},
+		Metadata: arg.Metadata,
+	}
 }
 
 type TransferTxResult struct {
@@ -37,90 +102,149 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	Fee         Fee      `json:"fee"`
+	// FeeEntry debits the sender and RevenueEntry credits the house, both are empty without a fee
+	FeeEntry     Entry        `json:"fee_entry"`
+	RevenueEntry Entry        `json:"revenue_entry"`
+	JournalEntry JournalEntry `json:"journal_entry"`
+	Postings     []Posting    `json:"postings"`
+	// FraudDecision is set when the transfer went through fraud screening
+	FraudDecision *FraudDecision `json:"fraud_decision,omitempty"`
 }
 
 // TransferTx performs a money transfer from one account to the other.
-// It creates a transfer record and account entries, and update the accounts' balance.
+// It creates a transfer record and its balanced journal entry, charges the fee of the currency's schedule
+// to the sender unless the transfer is internal, and update the accounts' balance.
+// Transfers other than internal ones count against the transfer limits of the sender.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(queries *Queries) error {
 		var err error
+		result, err = performTransfer(ctx, queries, arg)
+		return err
+	})
+	return result, err
+}
 
-		result.Transfer, err = queries.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+// performTransfer does the work of TransferTx with the given queries,
+// and records the fraud screening that allowed the transfer when there is one
+func performTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	return settleTransfer(ctx, q, arg, nil)
+}
 
-		result.FromEntry, err = queries.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+// settleTransfer is performTransfer for a transfer whose amount may be reserved by a pending hold on the sender.
+// The hold's amount is released before the sender's funds are checked, so the transfer doesn't count it twice;
+// marking the hold as captured is left to the caller.
+func settleTransfer(ctx context.Context, q *Queries, arg TransferTxParams, hold *Hold) (TransferTxResult, error) {
+	var fee Fee
+	var err error
+	if !arg.Internal {
+		fee, err = quoteFee(ctx, q, arg.Currency, arg.Amount)
 		if err != nil {
-			return err
+			return TransferTxResult{}, err
 		}
+	}
 
-		result.ToEntry, err = queries.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	// move the money before releasing the hold so both accounts are locked in id order
+	result, err := transfer(ctx, q, arg.createTransferParams(), fee)
+	if err != nil {
+		return result, err
+	}
+	if hold != nil {
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -hold.Amount,
+			ID:     hold.FromAccountID,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
+	}
+	if err = checkFunds(result.FromAccount); err != nil {
+		return result, err
+	}
 
-		result.FromAccount, result.ToAccount, err = transferMoney(ctx, queries, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	accounts := map[int64]Account{arg.FromAccountID: result.FromAccount}
+	err = applyTransferLimits(ctx, q, []TransferTxParams{arg}, accounts, time.Now())
+	if err != nil {
+		return result, err
+	}
 
-		return nil
-	})
+	err = recordScreening(ctx, q, arg, &result)
 	return result, err
 }
 
-func transferMoney(
-	ctx context.Context,
-	q *Queries,
-	fromAccountID int64,
-	toAccountID int64,
-	amount int64,
-) (fromAccount Account, toAccount Account, err error) {
-	if toAccountID > fromAccountID {
-		fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: -amount,
-			ID:     fromAccountID,
-		})
-		if err != nil {
-			return
-		}
+// transfer writes the transfer record and posts its journal entry
+// using the given queries, so it can be reused by any transaction moving money.
+// A non-zero fee adds a debit on the sender and a credit on the fee revenue account.
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams, fee Fee) (result TransferTxResult, err error) {
+	if fee.Total > 0 {
+		arg.FeeRuleID = sql.NullInt64{Int64: fee.RuleID, Valid: true}
+		arg.FlatFee = fee.Flat
+		arg.PercentageFee = fee.Percentage
+	}
+	if arg.Metadata == nil {
+		arg.Metadata = json.RawMessage(`{}`)
+	}
+	result.Fee = fee
 
-		toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: amount,
-			ID:     toAccountID,
-		})
-		if err != nil {
-			return
-		}
-	} else {
-		toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: amount,
-			ID:     toAccountID,
-		})
-		if err != nil {
-			return
-		}
-		fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: -amount,
-			ID:     fromAccountID,
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return
+	}
+
+	lines := []JournalLine{
+		{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+		{AccountID: arg.ToAccountID, Amount: arg.Amount},
+	}
+	if fee.Total > 0 {
+		lines = append(lines,
+			JournalLine{AccountID: arg.FromAccountID, Amount: -fee.Total},
+			JournalLine{AccountID: fee.RevenueAccountID, Amount: fee.Total},
+		)
+	}
+
+	journal, err := postJournal(ctx, q, result.Transfer, lines)
+	if err != nil {
+		return
+	}
+	result.JournalEntry = journal.JournalEntry
+	result.Postings = journal.Postings
+	result.FromEntry = journal.Entries[0]
+	result.ToEntry = journal.Entries[1]
+	if fee.Total > 0 {
+		result.FeeEntry = journal.Entries[2]
+		result.RevenueEntry = journal.Entries[3]
+	}
+	result.FromAccount = journal.Accounts[arg.FromAccountID]
+	result.ToAccount = journal.Accounts[arg.ToAccountID]
+	return
+}
+
+// addBalances applies the balance changes in ascending account id order,
+// so concurrent transactions always lock the rows in the same order and can't deadlock.
+func addBalances(ctx context.Context, q *Queries, changes map[int64]int64) (map[int64]Account, error) {
+	ids := make([]int64, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			Amount: changes[id],
+			ID:     id,
 		})
 		if err != nil {
-			return
+			return nil, err
 		}
+		accounts[id] = account
 	}
-	return
+	return accounts, nil
 }
 
+// execTx runs fn in a transaction scoped to the tenant of the context
 func (store *SQLStore) execTx(ctx context.Context, fn func(queries *Queries) error) error {
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -128,7 +252,10 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(queries *Queries) err
 	}
 
 	transactionQueries := New(tx)
-	err = fn(transactionQueries)
+	err = setTenant(ctx, tx, TenantFromContext(ctx))
+	if err == nil {
+		err = fn(transactionQueries)
+	}
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("transaction error: %v, rollback error: %v", err, rbErr)
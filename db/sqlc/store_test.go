@@ -11,7 +11,7 @@ func TestStore_TransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
 	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
 	amount := int64(10)
 	fmt.Println(">> Before:", "fromAccount:", fromAccount.Balance, "toAccount:", toAccount.Balance)
 
@@ -110,7 +110,7 @@ func TestStore_TransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
 	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
 	amount := int64(10)
 	fmt.Println(">> Before:", "account1:", account1.Balance, "account2:", account2.Balance)
 
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"strconv"
+)
+
+// DefaultTenantID is the tenant the data from before multi-tenancy belongs to,
+// used when the context carries no tenant
+const DefaultTenantID int64 = 1
+
+// TenantKey is the key http handlers store the tenant id under in the request context
+const TenantKey = "tenant_id"
+
+// tenantRole is the role every transaction switches to, so the row level security policies apply
+// even when the connection logs in as a superuser or as the owner of the tables
+const tenantRole = "bank_tenant"
+
+type tenantContextKey struct{}
+
+// WithTenant scopes the context to a tenant, the store only sees and writes the rows of that tenant with it
+func WithTenant(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set with WithTenant or stored under TenantKey, or the default tenant
+func TenantFromContext(ctx context.Context) int64 {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(int64); ok {
+		return tenantID
+	}
+	if tenantID, ok := ctx.Value(TenantKey).(int64); ok {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// setTenant scopes the rest of the transaction to the tenant, both settings end with it
+func setTenant(ctx context.Context, tx DBTX, tenantID int64) error {
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE "+tenantRole); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "SELECT set_config('app.tenant_id', $1, true)", strconv.FormatInt(tenantID, 10))
+	return err
+}
@@ -0,0 +1,325 @@
+package db
+
+import (
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var querierType = reflect.TypeOf((*Querier)(nil)).Elem()
+
+func createRandomTenant(t *testing.T) TenantTxResult {
+	store := NewStore(testDB)
+
+	slug := util.RandomOwner()
+	result, err := store.CreateTenantTx(context.Background(), CreateTenantTxParams{
+		Slug:       slug,
+		Name:       slug,
+		Host:       slug + ".example.com",
+		Currencies: []string{util.USD, util.EUR},
+		Limits: []TenantLimit{{
+			Currency:  util.USD,
+			Period:    LimitPeriodDay,
+			MaxAmount: sql.NullInt64{Int64: 5000, Valid: true},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, slug, result.Tenant.Slug)
+	require.Equal(t, []string{util.USD, util.EUR}, result.Tenant.Currencies)
+	return result
+}
+
+func TestStore_QuerierRunsInTenantTx(t *testing.T) {
+	storeType := reflect.TypeOf(&SQLStore{})
+
+	// a method promoted from the embedded Queries would skip execTx and with it the tenant policies
+	for i := 0; i < querierType.NumMethod(); i++ {
+		name := querierType.Method(i).Name
+		method, ok := storeType.MethodByName(name)
+		require.True(t, ok, name)
+
+		fn := runtime.FuncForPC(method.Func.Pointer())
+		file, _ := fn.FileLine(fn.Entry())
+		require.Equal(t, "store_querier.go", filepath.Base(file), "%s is not wrapped in a transaction", name)
+	}
+}
+
+func TestQueries_TenantTablesHaveRowLevelSecurity(t *testing.T) {
+	rows, err := testDB.QueryContext(context.Background(), `
+SELECT c.relname
+FROM pg_class c
+WHERE c.relkind = 'r'
+  AND c.relnamespace = 'public'::regnamespace
+  AND c.relname NOT IN ('tenants', 'schema_migrations')
+  AND (NOT c.relrowsecurity
+    OR NOT c.relforcerowsecurity
+    OR NOT EXISTS (SELECT 1 FROM pg_attribute a WHERE a.attrelid = c.oid AND a.attname = 'tenant_id')
+    OR NOT EXISTS (SELECT 1 FROM pg_policy p WHERE p.polrelid = c.oid AND p.polname = 'tenant_isolation'))`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var unprotected []string
+	for rows.Next() {
+		var table string
+		require.NoError(t, rows.Scan(&table))
+		unprotected = append(unprotected, table)
+	}
+	require.NoError(t, rows.Err())
+	require.Empty(t, unprotected)
+}
+
+// tenantFixtures are rows of the default tenant the isolation test points every query at
+type tenantFixtures struct {
+	user     User
+	account  Account
+	transfer Transfer
+	entry    Entry
+}
+
+// TestQueries_TenantIsolation calls every Querier method from another tenant with the ids and names of
+// default tenant rows. Nothing may come back from the default tenant, and nothing in it may change.
+func TestQueries_TenantIsolation(t *testing.T) {
+	ctx := context.Background()
+
+	account := createRandomAccount(t)
+	other := createRandomAccountInCurrency(t, account.Currency)
+	user, err := testQueries.GetUser(ctx, account.Owner)
+	require.NoError(t, err)
+	fixtures := tenantFixtures{
+		user:     user,
+		account:  account,
+		transfer: createRandomTransfer(t, account, other),
+		entry:    createRandomEntry(t, account),
+	}
+
+	tenant := createRandomTenant(t).Tenant
+
+	tx, err := testDB.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, setTenant(ctx, tx, tenant.ID))
+	queries := reflect.ValueOf(New(tx))
+
+	for i := 0; i < querierType.NumMethod(); i++ {
+		method := querierType.Method(i)
+		if tenantTable(method.Type) {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, "SAVEPOINT isolation")
+		require.NoError(t, err)
+
+		args := []reflect.Value{reflect.ValueOf(ctx)}
+		for j := 1; j < method.Type.NumIn(); j++ {
+			args = append(args, isolationArg(method.Type.In(j), "", method.Type, fixtures))
+		}
+		out := queries.MethodByName(method.Name).Call(args)
+
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			// a query failing on the other tenant's ids doesn't leak anything
+			_, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT isolation")
+			require.NoError(t, err)
+			continue
+		}
+		if len(out) == 2 {
+			requireTenantResult(t, method.Name, out[0], tenant.ID, fixtures)
+		}
+	}
+
+	// the default tenant's rows are untouched by whatever the other tenant wrote
+	require.NoError(t, setTenant(ctx, tx, DefaultTenantID))
+	after := New(tx)
+
+	gotAccount, err := after.GetAccount(ctx, fixtures.account.ID)
+	require.NoError(t, err)
+	require.Equal(t, fixtures.account, gotAccount)
+
+	gotUser, err := after.GetUser(ctx, fixtures.user.Username)
+	require.NoError(t, err)
+	require.Equal(t, fixtures.user.TenantID, gotUser.TenantID)
+	require.Equal(t, fixtures.user.Alias, gotUser.Alias)
+
+	gotTransfer, err := after.GetTransfer(ctx, fixtures.transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, fixtures.transfer.Amount, gotTransfer.Amount)
+
+	gotEntry, err := after.GetEntry(ctx, fixtures.entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, fixtures.entry.Hash, gotEntry.Hash)
+}
+
+// tenantTable reports whether the method works on the tenants themselves, which every tenant reads
+func tenantTable(method reflect.Type) bool {
+	tenant := reflect.TypeOf(Tenant{})
+	result := method.Out(0)
+	return result == tenant || (result.Kind() == reflect.Slice && result.Elem() == tenant)
+}
+
+// isolationArg builds an argument pointing at the fixtures, going by the field name and the type the method returns
+func isolationArg(typ reflect.Type, name string, method reflect.Type, fixtures tenantFixtures) reflect.Value {
+	value := reflect.New(typ).Elem()
+
+	switch typ {
+	case reflect.TypeOf(time.Time{}):
+		if strings.Contains(name, "Since") || strings.Contains(name, "From") || strings.Contains(name, "Start") {
+			return reflect.ValueOf(time.Now().Add(-24 * time.Hour))
+		}
+		return reflect.ValueOf(time.Now().Add(time.Hour))
+	case reflect.TypeOf(sql.NullInt64{}):
+		id := isolationID(name, method, fixtures)
+		return reflect.ValueOf(sql.NullInt64{Int64: id, Valid: true})
+	}
+
+	switch typ.Kind() {
+	case reflect.Int64:
+		value.SetInt(isolationID(name, method, fixtures))
+	case reflect.Int32:
+		if name == "Limit" || name == "" {
+			value.SetInt(100)
+		}
+	case reflect.String:
+		if name == "Currency" {
+			value.SetString(fixtures.account.Currency)
+		} else {
+			value.SetString(fixtures.user.Username)
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			value.Field(i).Set(isolationArg(field.Type, field.Name, method, fixtures))
+		}
+	}
+	return value
+}
+
+func isolationID(name string, method reflect.Type, fixtures tenantFixtures) int64 {
+	switch {
+	case strings.Contains(name, "Account") || name == "ParentID":
+		return fixtures.account.ID
+	case strings.Contains(name, "Transfer"):
+		return fixtures.transfer.ID
+	}
+
+	switch method.Out(0) {
+	case reflect.TypeOf(Transfer{}):
+		return fixtures.transfer.ID
+	case reflect.TypeOf(Entry{}):
+		return fixtures.entry.ID
+	}
+	return fixtures.account.ID
+}
+
+// requireTenantResult fails when a result holds a row of another tenant, or a total counting one
+func requireTenantResult(t *testing.T, method string, result reflect.Value, tenantID int64, fixtures tenantFixtures) {
+	switch result.Kind() {
+	case reflect.Struct:
+		if field := result.FieldByName("TenantID"); field.IsValid() {
+			require.Equal(t, tenantID, field.Int(), "%s returned a row of another tenant", method)
+		}
+		// rows put together from several tables have no tenant of their own
+		if field := result.FieldByName("AccountID"); field.IsValid() && field.Kind() == reflect.Int64 {
+			require.NotEqual(t, fixtures.account.ID, field.Int(), "%s returned an account of another tenant", method)
+		}
+	case reflect.Slice:
+		if result.Type().Elem().Kind() == reflect.Uint8 {
+			require.Zero(t, result.Len(), "%s returned the hash of another tenant", method)
+			return
+		}
+		for i := 0; i < result.Len(); i++ {
+			requireTenantResult(t, method, result.Index(i), tenantID, fixtures)
+		}
+	case reflect.Int64:
+		switch {
+		case strings.HasPrefix(method, "Create"):
+			// the number of rows the other tenant wrote for itself
+		case strings.HasPrefix(method, "List"):
+			require.NotEqual(t, fixtures.account.ID, result.Int(), "%s returned an account of another tenant", method)
+		default:
+			require.Zero(t, result.Int(), "%s counted rows of another tenant", method)
+		}
+	}
+}
+
+func TestStore_TenantScopedContext(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account := createRandomAccountInCurrency(t, util.USD)
+	tenant := createRandomTenant(t)
+	tenantCtx := WithTenant(ctx, tenant.Tenant.ID)
+
+	require.Len(t, tenant.HouseAccounts, len(houseAccounts)*len(tenant.Tenant.Currencies))
+	for _, houseAccount := range tenant.HouseAccounts {
+		require.Equal(t, tenant.Tenant.ID, houseAccount.TenantID)
+	}
+	require.Len(t, tenant.Limits, 1)
+	require.Equal(t, tenant.Tenant.ID, tenant.Limits[0].TenantID)
+
+	// the tenant has its own house accounts and doesn't see the default tenant's
+	feeAccount, err := store.GetAccountByOwnerAndCurrency(tenantCtx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FeeRevenueOwner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tenant.Tenant.ID, feeAccount.TenantID)
+
+	_, err = store.GetAccount(tenantCtx, account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetUser(tenantCtx, account.Owner)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	houseUser, err := store.GetUser(tenantCtx, FeeRevenueOwner)
+	require.NoError(t, err)
+	require.Equal(t, tenant.Tenant.ID, houseUser.TenantID)
+
+	// usernames are unique per tenant, the tenant can have its own user with the name
+	user, err := store.CreateUser(tenantCtx, CreateUserParams{
+		Username:       account.Owner,
+		HashedPassword: util.RandomString(32),
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, tenant.Tenant.ID, user.TenantID)
+
+	accounts, err := store.ListAccounts(tenantCtx, ListAccountsParams{Sort: "id", Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, accounts, len(tenant.HouseAccounts))
+	for _, listed := range accounts {
+		require.Equal(t, tenant.Tenant.ID, listed.TenantID)
+	}
+
+	// money doesn't move across tenants
+	_, err = store.TransferTx(tenantCtx, TransferTxParams{
+		FromAccountID: feeAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	})
+	require.Error(t, err)
+
+	// the default tenant still sees its own rows
+	found, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, found.Balance)
+	require.Equal(t, DefaultTenantID, found.TenantID)
+
+	_, err = store.GetAccount(ctx, feeAccount.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTenantFromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, DefaultTenantID, TenantFromContext(ctx))
+	require.Equal(t, int64(7), TenantFromContext(WithTenant(ctx, 7)))
+	require.Equal(t, int64(8), TenantFromContext(context.WithValue(ctx, TenantKey, int64(8))))
+}
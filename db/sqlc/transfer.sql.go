@@ -5,22 +5,44 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers (from_account_id, to_account_id, amount, original_transfer_id, fee_rule_id, flat_fee, percentage_fee,
+                       description, external_reference, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, from_account_id, to_account_id, amount, created_at, original_transfer_id, fee_rule_id, flat_fee, percentage_fee, description, external_reference, metadata, tenant_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID      int64           `json:"from_account_id"`
+	ToAccountID        int64           `json:"to_account_id"`
+	Amount             int64           `json:"amount"`
+	OriginalTransferID sql.NullInt64   `json:"original_transfer_id"`
+	FeeRuleID          sql.NullInt64   `json:"fee_rule_id"`
+	FlatFee            int64           `json:"flat_fee"`
+	PercentageFee      int64           `json:"percentage_fee"`
+	Description        string          `json:"description"`
+	ExternalReference  sql.NullString  `json:"external_reference"`
+	Metadata           json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.OriginalTransferID,
+		arg.FeeRuleID,
+		arg.FlatFee,
+		arg.PercentageFee,
+		arg.Description,
+		arg.ExternalReference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -28,12 +50,33 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.OriginalTransferID,
+		&i.FeeRuleID,
+		&i.FlatFee,
+		&i.PercentageFee,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.TenantID,
 	)
 	return i, err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE original_transfer_id = $1
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, originalTransferID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, originalTransferID)
+	var reversed_amount int64
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, original_transfer_id, fee_rule_id, flat_fee, percentage_fee, description, external_reference, metadata, tenant_id
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -48,12 +91,198 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.OriginalTransferID,
+		&i.FeeRuleID,
+		&i.FlatFee,
+		&i.PercentageFee,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getTransferByExternalReference = `-- name: GetTransferByExternalReference :one
+SELECT id, from_account_id, to_account_id, amount, created_at, original_transfer_id, fee_rule_id, flat_fee, percentage_fee, description, external_reference, metadata, tenant_id
+FROM transfers
+WHERE from_account_id = $1
+  AND external_reference = $2
+LIMIT 1
+`
+
+type GetTransferByExternalReferenceParams struct {
+	FromAccountID     int64          `json:"from_account_id"`
+	ExternalReference sql.NullString `json:"external_reference"`
+}
+
+func (q *Queries) GetTransferByExternalReference(ctx context.Context, arg GetTransferByExternalReferenceParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferByExternalReference, arg.FromAccountID, arg.ExternalReference)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.OriginalTransferID,
+		&i.FeeRuleID,
+		&i.FlatFee,
+		&i.PercentageFee,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.TenantID,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, original_transfer_id, fee_rule_id, flat_fee, percentage_fee, description, external_reference, metadata, tenant_id
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.OriginalTransferID,
+		&i.FeeRuleID,
+		&i.FlatFee,
+		&i.PercentageFee,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+WITH history AS (
+    SELECT e.id,
+           e.transfer_id,
+           a.balance - SUM(e.amount) OVER (ORDER BY e.created_at DESC, e.id DESC) + e.amount AS running_balance
+    FROM entries e
+             JOIN accounts a ON a.id = e.account_id
+    WHERE e.account_id = $1
+)
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.original_transfer_id, t.fee_rule_id, t.flat_fee, t.percentage_fee, t.description, t.external_reference, t.metadata, t.tenant_id,
+       (SELECT h.running_balance
+        FROM history h
+        WHERE h.transfer_id = t.id
+        ORDER BY h.id DESC
+        LIMIT 1)::bigint AS running_balance
+FROM transfers t
+WHERE (t.from_account_id = $1 OR t.to_account_id = $1)
+  AND ($2::timestamptz IS NULL OR t.created_at >= $2)
+  AND ($3::timestamptz IS NULL OR t.created_at < $3)
+  AND ($4::varchar IS NULL
+    OR ($4 = 'in' AND t.to_account_id = $1)
+    OR ($4 = 'out' AND t.from_account_id = $1))
+  AND ($5::bigint IS NULL OR t.amount >= $5)
+  AND ($6::bigint IS NULL OR t.amount <= $6)
+  AND ($7::bigint IS NULL
+    OR (t.from_account_id = $7 AND t.to_account_id = $1)
+    OR (t.to_account_id = $7 AND t.from_account_id = $1))
+  AND ($8::timestamptz IS NULL
+    OR (t.created_at, t.id) < ($8, $9::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $10 OFFSET $11
+`
+
+type ListAccountTransfersParams struct {
+	AccountID             int64          `json:"account_id"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Direction             sql.NullString `json:"direction"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	CursorCreatedAt       sql.NullTime   `json:"cursor_created_at"`
+	CursorID              sql.NullInt64  `json:"cursor_id"`
+	Limit                 int32          `json:"limit"`
+	Offset                int32          `json:"offset"`
+}
+
+type ListAccountTransfersRow struct {
+	ID                 int64           `json:"id"`
+	FromAccountID      int64           `json:"from_account_id"`
+	ToAccountID        int64           `json:"to_account_id"`
+	Amount             int64           `json:"amount"`
+	CreatedAt          time.Time       `json:"created_at"`
+	OriginalTransferID sql.NullInt64   `json:"original_transfer_id"`
+	FeeRuleID          sql.NullInt64   `json:"fee_rule_id"`
+	FlatFee            int64           `json:"flat_fee"`
+	PercentageFee      int64           `json:"percentage_fee"`
+	Description        string          `json:"description"`
+	ExternalReference  sql.NullString  `json:"external_reference"`
+	Metadata           json.RawMessage `json:"metadata"`
+	TenantID           int64           `json:"tenant_id"`
+	RunningBalance     sql.NullInt64   `json:"running_balance"`
+}
+
+// running_balance is the account balance right after the transfer and its fee
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]ListAccountTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Direction,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CounterpartyAccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountTransfersRow{}
+	for rows.Next() {
+		var i ListAccountTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.OriginalTransferID,
+			&i.FeeRuleID,
+			&i.FlatFee,
+			&i.PercentageFee,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
+			&i.TenantID,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, original_transfer_id, fee_rule_id, flat_fee, percentage_fee, description, external_reference, metadata, tenant_id
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
@@ -88,6 +317,14 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.OriginalTransferID,
+			&i.FeeRuleID,
+			&i.FlatFee,
+			&i.PercentageFee,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
+			&i.TenantID,
 		); err != nil {
 			return nil, err
 		}
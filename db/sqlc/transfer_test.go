@@ -3,6 +3,9 @@ package db
 import (
 	"code-with-go/util"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
@@ -30,6 +33,92 @@ func TestGetTransfer(t *testing.T) {
 	require.WithinDuration(t, transfer1.CreatedAt, transfer2.CreatedAt, time.Second)
 }
 
+func TestGetTransferByExternalReference(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+
+	arg := TransferTxParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		Amount:            10,
+		Description:       "invoice payment",
+		ExternalReference: "INV-" + util.RandomString(8),
+		Metadata:          json.RawMessage(`{"invoice": "42"}`),
+	}
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	transfer, err := testQueries.GetTransferByExternalReference(context.Background(), GetTransferByExternalReferenceParams{
+		FromAccountID:     account1.ID,
+		ExternalReference: result.Transfer.ExternalReference,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, transfer.ID)
+	require.Equal(t, arg.Description, transfer.Description)
+	require.Equal(t, arg.ExternalReference, transfer.ExternalReference.String)
+	require.JSONEq(t, string(arg.Metadata), string(transfer.Metadata))
+
+	// the reference can't be reused by the same sender
+	_, err = store.TransferTx(context.Background(), arg)
+	require.Error(t, err)
+	require.Equal(t, "unique_violation", err.(*pq.Error).Code.Name())
+
+	// but another sender can use it
+	account3 := createRandomAccountInCurrency(t, account1.Currency)
+	arg.FromAccountID = account3.ID
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestListAccountTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	account3 := createRandomAccountInCurrency(t, account1.Currency)
+
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 4},
+		{FromAccountID: account3.ID, ToAccountID: account1.ID, Amount: 7},
+	} {
+		_, err := store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	account1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID: account1.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	require.Equal(t, account1.Balance, transfers[0].RunningBalance.Int64)
+	require.Equal(t, account1.Balance-7, transfers[1].RunningBalance.Int64)
+	require.Equal(t, account1.Balance-11, transfers[2].RunningBalance.Int64)
+
+	outgoing, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID: account1.ID,
+		Direction: sql.NullString{String: "out", Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, outgoing, 1)
+	require.Equal(t, account2.ID, outgoing[0].ToAccountID)
+
+	fromAccount2, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID:             account1.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		MaxAmount:             sql.NullInt64{Int64: 5, Valid: true},
+		Limit:                 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, fromAccount2, 1)
+	require.Equal(t, int64(4), fromAccount2[0].Amount)
+}
+
 func TestListTransfer(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
@@ -61,6 +150,7 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        util.RandomMoney(),
+		Metadata:      json.RawMessage(`{}`),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
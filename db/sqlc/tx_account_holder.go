@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	AccountHolderPrimary = "primary"
+	AccountHolderCoOwner = "co_owner"
+	AccountHolderViewer  = "viewer"
+
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+var ErrInvitationNotPending = errors.New("invitation was already answered")
+
+type RespondAccountInvitationTxParams struct {
+	InvitationID int64 `json:"invitation_id"`
+	Accept       bool  `json:"accept"`
+}
+
+type RespondAccountInvitationTxResult struct {
+	Invitation AccountInvitation `json:"invitation"`
+	// Holder is empty when the invitation is declined
+	Holder AccountHolder `json:"holder"`
+}
+
+// RespondAccountInvitationTx accepts or declines a pending invitation.
+// Accepting adds the invitee as a holder of the account with the invited role, unless the account is closed.
+func (store *SQLStore) RespondAccountInvitationTx(ctx context.Context, arg RespondAccountInvitationTxParams) (RespondAccountInvitationTxResult, error) {
+	var result RespondAccountInvitationTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		invitation, err := queries.GetAccountInvitationForUpdate(ctx, arg.InvitationID)
+		if err != nil {
+			return err
+		}
+		if invitation.Status != InvitationStatusPending {
+			return ErrInvitationNotPending
+		}
+
+		status := InvitationStatusDeclined
+		if arg.Accept {
+			status = InvitationStatusAccepted
+
+			account, err := queries.GetAccount(ctx, invitation.AccountID)
+			if err != nil {
+				return err
+			}
+			if account.Status == AccountStatusClosed {
+				return ErrAccountClosed
+			}
+
+			result.Holder, err = queries.CreateAccountHolder(ctx, CreateAccountHolderParams{
+				AccountID: invitation.AccountID,
+				Username:  invitation.Invitee,
+				Role:      invitation.Role,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Invitation, err = queries.RespondAccountInvitation(ctx, RespondAccountInvitationParams{
+			ID:     invitation.ID,
+			Status: status,
+		})
+		return err
+	})
+	return result, err
+}
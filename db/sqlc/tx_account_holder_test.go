@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_RespondAccountInvitationTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	partner := createRandomUser(t)
+	viewer := createRandomUser(t)
+
+	// the owner becomes the primary holder with the account
+	primary, err := testQueries.GetAccountHolder(context.Background(), GetAccountHolderParams{AccountID: account.ID, Username: account.Owner})
+	require.NoError(t, err)
+	require.Equal(t, AccountHolderPrimary, primary.Role)
+
+	invitation, err := testQueries.CreateAccountInvitation(context.Background(), CreateAccountInvitationParams{
+		AccountID: account.ID,
+		Invitee:   partner.Username,
+		Role:      AccountHolderCoOwner,
+		InvitedBy: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, InvitationStatusPending, invitation.Status)
+
+	result, err := store.RespondAccountInvitationTx(context.Background(), RespondAccountInvitationTxParams{InvitationID: invitation.ID, Accept: true})
+	require.NoError(t, err)
+	require.Equal(t, InvitationStatusAccepted, result.Invitation.Status)
+	require.True(t, result.Invitation.RespondedAt.Valid)
+	require.Equal(t, partner.Username, result.Holder.Username)
+	require.Equal(t, AccountHolderCoOwner, result.Holder.Role)
+
+	holder, err := testQueries.GetAccountHolder(context.Background(), GetAccountHolderParams{AccountID: account.ID, Username: partner.Username})
+	require.NoError(t, err)
+	require.Equal(t, result.Holder, holder)
+
+	_, err = store.RespondAccountInvitationTx(context.Background(), RespondAccountInvitationTxParams{InvitationID: invitation.ID, Accept: false})
+	require.ErrorIs(t, err, ErrInvitationNotPending)
+
+	invitation, err = testQueries.CreateAccountInvitation(context.Background(), CreateAccountInvitationParams{
+		AccountID: account.ID,
+		Invitee:   viewer.Username,
+		Role:      AccountHolderViewer,
+		InvitedBy: account.Owner,
+	})
+	require.NoError(t, err)
+
+	result, err = store.RespondAccountInvitationTx(context.Background(), RespondAccountInvitationTxParams{InvitationID: invitation.ID, Accept: false})
+	require.NoError(t, err)
+	require.Equal(t, InvitationStatusDeclined, result.Invitation.Status)
+	require.Empty(t, result.Holder.Username)
+
+	holders, err := testQueries.ListAccountHolders(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, holders, 2)
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	ErrAccountFrozen           = errors.New("account is frozen")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrInvalidStatusTransition = errors.New("account status transition is not allowed")
+	ErrAccountNotEmpty         = errors.New("account balance must be zero to close it")
+	ErrAccountHasHolds         = errors.New("account has pending holds")
+	ErrPotsNotEmpty            = errors.New("pots must be empty to close their parent account")
+)
+
+// accountTransitions lists the statuses each status can move to, closed is final.
+// Only bankers close a frozen account, see ChangeAccountStatusTxParams.Banker.
+var accountTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive, AccountStatusClosed},
+}
+
+// CanTransition tells whether an account can move from one status to the other
+func CanTransition(from, to string) bool {
+	for _, status := range accountTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccountActive rejects money movements on frozen and closed accounts
+func checkAccountActive(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Actor     string `json:"actor"`
+	Reason    string `json:"reason"`
+	// Banker is set when a banker makes the change, a customer closing a frozen account would lift the freeze
+	Banker bool `json:"banker"`
+}
+
+type ChangeAccountStatusTxResult struct {
+	Account Account             `json:"account"`
+	Change  AccountStatusChange `json:"change"`
+}
+
+// ChangeAccountStatusTx moves an account to a new status and records who did it and why.
+// Closing requires a zero balance and no pending holds, on the account and on each of its pots,
+// and closes the pots along with it.
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		account, err := queries.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if !CanTransition(account.Status, arg.Status) {
+			return ErrInvalidStatusTransition
+		}
+		if account.Status == AccountStatusFrozen && arg.Status == AccountStatusClosed && !arg.Banker {
+			return ErrAccountFrozen
+		}
+		if arg.Status == AccountStatusClosed {
+			if account.HeldBalance != 0 {
+				return ErrAccountHasHolds
+			}
+			if account.Balance != 0 {
+				return ErrAccountNotEmpty
+			}
+			if err := closePots(ctx, queries, account.ID, arg.Actor); err != nil {
+				return err
+			}
+		}
+
+		result.Account, err = queries.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Change, err = queries.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   arg.Status,
+			Actor:      arg.Actor,
+			Reason:     arg.Reason,
+		})
+		return err
+	})
+	return result, err
+}
+
+// closePots closes the open pots of an account, all of them must be empty
+func closePots(ctx context.Context, q *Queries, parentID int64, actor string) error {
+	pots, err := q.ListPotsForUpdate(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	for _, pot := range pots {
+		if pot.Status == AccountStatusClosed {
+			continue
+		}
+		if pot.Balance != 0 || pot.HeldBalance != 0 {
+			return ErrPotsNotEmpty
+		}
+
+		_, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     pot.ID,
+			Status: AccountStatusClosed,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  pot.ID,
+			FromStatus: pot.Status,
+			ToStatus:   AccountStatusClosed,
+			Actor:      actor,
+			Reason:     "parent account closed",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
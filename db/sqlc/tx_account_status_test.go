@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	require.True(t, CanTransition(AccountStatusActive, AccountStatusFrozen))
+	require.True(t, CanTransition(AccountStatusFrozen, AccountStatusActive))
+	require.True(t, CanTransition(AccountStatusActive, AccountStatusClosed))
+	require.True(t, CanTransition(AccountStatusFrozen, AccountStatusClosed))
+	require.False(t, CanTransition(AccountStatusActive, AccountStatusActive))
+	require.False(t, CanTransition(AccountStatusClosed, AccountStatusActive))
+	require.False(t, CanTransition(AccountStatusClosed, AccountStatusFrozen))
+}
+
+func TestStore_ChangeAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+	banker := createRandomUser(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account1.ID, Balance: 100})
+	require.NoError(t, err)
+
+	result, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusFrozen,
+		Actor:     banker.Username,
+		Reason:    "suspicious activity",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Equal(t, AccountStatusActive, result.Change.FromStatus)
+	require.Equal(t, AccountStatusFrozen, result.Change.ToStatus)
+	require.Equal(t, banker.Username, result.Change.Actor)
+
+	// neither debits nor credits go through
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// the holder can't close the account to lift the freeze
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+		Actor:     account1.Owner,
+		Reason:    "closed through the API",
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusActive,
+		Actor:     banker.Username,
+		Reason:    "cleared",
+		Banker:    true,
+	})
+	require.NoError(t, err)
+
+	// closing needs an empty account without pending holds
+	hold, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+		Actor:     banker.Username,
+		Reason:    "customer request",
+	})
+	require.ErrorIs(t, err, ErrAccountHasHolds)
+	_, err = store.VoidHoldTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+		Actor:     banker.Username,
+		Reason:    "customer request",
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	account1, err = testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: account1.Balance})
+	require.NoError(t, err)
+
+	result, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+		Actor:     banker.Username,
+		Reason:    "customer request",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusActive,
+		Actor:     banker.Username,
+		Reason:    "reopen",
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	changes, err := testQueries.ListAccountStatusChanges(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, AccountStatusClosed, changes[2].ToStatus)
+	require.Equal(t, "customer request", changes[2].Reason)
+}
+
+func TestStore_ClosePotsWithParent(t *testing.T) {
+	store := NewStore(testDB)
+	banker := createRandomUser(t)
+	parent := createRandomAccount(t)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: parent.ID, Balance: 10})
+	require.NoError(t, err)
+
+	pot, err := testQueries.CreatePot(context.Background(), CreatePotParams{Name: "Holidays", ParentID: parent.ID})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: parent.ID,
+		ToAccountID:   pot.ID,
+		Amount:        10,
+		Currency:      parent.Currency,
+		Internal:      true,
+	})
+	require.NoError(t, err)
+
+	// the parent is empty but its pot isn't
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: parent.ID,
+		Status:    AccountStatusClosed,
+		Actor:     banker.Username,
+		Reason:    "customer request",
+	})
+	require.ErrorIs(t, err, ErrPotsNotEmpty)
+
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: pot.ID, Balance: 0})
+	require.NoError(t, err)
+
+	result, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: parent.ID,
+		Status:    AccountStatusClosed,
+		Actor:     banker.Username,
+		Reason:    "customer request",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+
+	pot, err = testQueries.GetAccount(context.Background(), pot.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, pot.Status)
+
+	changes, err := testQueries.ListAccountStatusChanges(context.Background(), pot.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "parent account closed", changes[0].Reason)
+}
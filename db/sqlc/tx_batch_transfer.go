@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type BatchTransferTxParams struct {
+	Transfers []TransferTxParams `json:"transfers"`
+}
+
+type BatchTransferTxResult struct {
+	Transfers []TransferTxResult `json:"transfers"`
+}
+
+// BatchTransferTx performs several transfers in a single transaction: either all of them are applied or none.
+// Every involved account is locked up front in ascending id order, so concurrent batches sharing
+// accounts wait for each other instead of deadlocking. The whole batch counts against the transfer limits.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		accounts := make(map[int64]Account)
+		for _, accountID := range involvedAccountIDs(arg.Transfers) {
+			account, err := queries.GetAccountForUpdate(ctx, accountID)
+			if err != nil {
+				return err
+			}
+			accounts[accountID] = account
+		}
+
+		result.Transfers = make([]TransferTxResult, 0, len(arg.Transfers))
+		for _, item := range arg.Transfers {
+			fee, err := quoteFee(ctx, queries, item.Currency, item.Amount)
+			if err != nil {
+				return err
+			}
+
+			transferResult, err := transfer(ctx, queries, item.createTransferParams(), fee)
+			if err != nil {
+				return err
+			}
+			if err := checkFunds(transferResult.FromAccount); err != nil {
+				return err
+			}
+			if err := recordScreening(ctx, queries, item, &transferResult); err != nil {
+				return err
+			}
+			result.Transfers = append(result.Transfers, transferResult)
+		}
+		return applyTransferLimits(ctx, queries, arg.Transfers, accounts, time.Now())
+	})
+	return result, err
+}
+
+// involvedAccountIDs returns the distinct accounts touched by the transfers, sorted ascending
+func involvedAccountIDs(transfers []TransferTxParams) []int64 {
+	seen := make(map[int64]bool)
+	ids := make([]int64, 0, len(transfers)*2)
+	for _, t := range transfers {
+		for _, id := range []int64{t.FromAccountID, t.ToAccountID} {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_BatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	employees := []Account{
+		createRandomAccountInCurrency(t, payer.Currency),
+		createRandomAccountInCurrency(t, payer.Currency),
+		createRandomAccountInCurrency(t, payer.Currency),
+	}
+
+	arg := BatchTransferTxParams{}
+	for i, employee := range employees {
+		arg.Transfers = append(arg.Transfers, TransferTxParams{
+			FromAccountID: payer.ID,
+			ToAccountID:   employee.ID,
+			Amount:        int64(10 * (i + 1)),
+		})
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, len(employees))
+
+	for i, transferResult := range result.Transfers {
+		require.Equal(t, arg.Transfers[i].Amount, transferResult.Transfer.Amount)
+		require.Equal(t, -arg.Transfers[i].Amount, transferResult.FromEntry.Amount)
+		require.Equal(t, arg.Transfers[i].Amount, transferResult.ToEntry.Amount)
+		require.Equal(t, employees[i].Balance+arg.Transfers[i].Amount, transferResult.ToAccount.Balance)
+	}
+
+	updatedPayer, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, payer.Balance-60, updatedPayer.Balance)
+}
+
+func TestStore_BatchTransferTxRollback(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	employee := createRandomAccountInCurrency(t, payer.Currency)
+
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: payer.ID, ToAccountID: employee.ID, Amount: 10},
+			{FromAccountID: payer.ID, ToAccountID: -1, Amount: 10},
+		},
+	})
+	require.Error(t, err)
+
+	// the first transfer must be rolled back along with the failing one
+	updatedPayer, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, payer.Balance, updatedPayer.Balance)
+
+	updatedEmployee, err := testQueries.GetAccount(context.Background(), employee.ID)
+	require.NoError(t, err)
+	require.Equal(t, employee.Balance, updatedEmployee.Balance)
+}
+
+func TestStore_BatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	account3 := createRandomAccountInCurrency(t, account1.Currency)
+
+	n := 10
+	errs := make(chan error)
+
+	// batches walk the same accounts in opposite directions
+	for i := 0; i < n; i++ {
+		ids := []int64{account1.ID, account2.ID, account3.ID}
+		if i%2 == 1 {
+			ids = []int64{account3.ID, account2.ID, account1.ID}
+		}
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				Transfers: []TransferTxParams{
+					{FromAccountID: ids[0], ToAccountID: ids[1], Amount: 10},
+					{FromAccountID: ids[1], ToAccountID: ids[2], Amount: 10},
+					{FromAccountID: ids[2], ToAccountID: ids[0], Amount: 10},
+				},
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	for _, account := range []Account{account1, account2, account3} {
+		updated, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updated.Balance)
+	}
+}
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	AccountTypeEscrow = "escrow"
+
+	// EscrowOwner owns the house accounts holding escrowed funds, one per currency
+	EscrowOwner = "houseescrow"
+
+	EscrowFunded   = "funded"
+	EscrowDisputed = "disputed"
+	EscrowReleased = "released"
+	EscrowRefunded = "refunded"
+)
+
+var (
+	ErrEscrowSettled         = errors.New("escrow agreement is already settled")
+	ErrEscrowDisputed        = errors.New("escrow agreement is disputed and waits for a banker")
+	ErrEscrowDeadlinePassed  = errors.New("escrow agreement deadline has passed")
+	ErrEscrowDeadlineNotDue  = errors.New("escrow agreement deadline has not passed yet")
+	ErrEscrowAccountMismatch = errors.New("escrow funds can't move between an account and itself")
+)
+
+type CreateEscrowTxParams struct {
+	Buyer           string    `json:"buyer"`
+	BuyerAccountID  int64     `json:"buyer_account_id"`
+	SellerAccountID int64     `json:"seller_account_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Condition       string    `json:"condition"`
+	Deadline        time.Time `json:"deadline"`
+	// Screening is the fraud assessment that allowed the funding transfer
+	Screening *FraudAssessment `json:"-"`
+}
+
+type EscrowTxResult struct {
+	Agreement EscrowAgreement `json:"agreement"`
+	// Transfer funds the agreement, or settles it when released or refunded. It is empty on disputes.
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// EscrowFundingTransfer is the transfer moving the buyer's funds into the escrow account of the currency.
+// Its metadata links it back to the agreement once there is one.
+func EscrowFundingTransfer(agreement EscrowAgreement) TransferTxParams {
+	return TransferTxParams{
+		FromAccountID: agreement.BuyerAccountID,
+		ToAccountID:   agreement.EscrowAccountID,
+		Amount:        agreement.Amount,
+		Currency:      agreement.Currency,
+		Description:   "Escrow funding",
+		Metadata:      escrowMetadata(agreement),
+		Actor:         agreement.Buyer,
+	}
+}
+
+// CreateEscrowTx opens an agreement and moves the buyer's funds into escrow, charging the usual transfer fee.
+// The funds stay there until they are released to the seller or refunded to the buyer.
+func (store *SQLStore) CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (EscrowTxResult, error) {
+	var result EscrowTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		if arg.BuyerAccountID == arg.SellerAccountID {
+			return ErrEscrowAccountMismatch
+		}
+		escrowAccount, err := queries.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:    EscrowOwner,
+			Currency: arg.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot find escrow account for %s: %w", arg.Currency, err)
+		}
+
+		agreement, err := queries.CreateEscrowAgreement(ctx, CreateEscrowAgreementParams{
+			Buyer:           arg.Buyer,
+			BuyerAccountID:  arg.BuyerAccountID,
+			SellerAccountID: arg.SellerAccountID,
+			EscrowAccountID: escrowAccount.ID,
+			Amount:          arg.Amount,
+			Currency:        arg.Currency,
+			Condition:       arg.Condition,
+			Deadline:        arg.Deadline,
+		})
+		if err != nil {
+			return err
+		}
+
+		transfer := EscrowFundingTransfer(agreement)
+		transfer.Screening = arg.Screening
+		transferResult, err := performTransfer(ctx, queries, transfer)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &transferResult
+
+		result.Agreement, err = queries.SetEscrowFundingTransfer(ctx, SetEscrowFundingTransferParams{
+			ID:                agreement.ID,
+			FundingTransferID: sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return result, err
+}
+
+type SettleEscrowTxParams struct {
+	AgreementID int64 `json:"agreement_id"`
+	// Actor released or refunded the funds
+	Actor string `json:"actor"`
+	// ResolveDispute lets a banker settle a disputed agreement, the parties can't
+	ResolveDispute bool `json:"resolve_dispute"`
+}
+
+// ReleaseEscrowTx pays the escrowed funds out to the seller once the condition is met
+func (store *SQLStore) ReleaseEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error) {
+	return store.settleEscrowTx(ctx, arg, EscrowReleased)
+}
+
+// RefundEscrowTx returns the escrowed funds to the buyer
+func (store *SQLStore) RefundEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error) {
+	return store.settleEscrowTx(ctx, arg, EscrowRefunded)
+}
+
+func (store *SQLStore) settleEscrowTx(ctx context.Context, arg SettleEscrowTxParams, status string) (EscrowTxResult, error) {
+	var result EscrowTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		agreement, err := lockEscrowAgreement(ctx, queries, arg.AgreementID, arg.ResolveDispute)
+		if err != nil {
+			return err
+		}
+
+		result, err = settleEscrow(ctx, queries, agreement, status, sql.NullString{String: arg.Actor, Valid: true})
+		return err
+	})
+	return result, err
+}
+
+// ExpireEscrowTx refunds the buyer of an agreement neither released nor disputed by its deadline
+func (store *SQLStore) ExpireEscrowTx(ctx context.Context, agreementID int64) (EscrowTxResult, error) {
+	var result EscrowTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		agreement, err := lockEscrowAgreement(ctx, queries, agreementID, false)
+		if err != nil {
+			return err
+		}
+		if agreement.Deadline.After(time.Now()) {
+			return ErrEscrowDeadlineNotDue
+		}
+
+		result, err = settleEscrow(ctx, queries, agreement, EscrowRefunded, sql.NullString{})
+		return err
+	})
+	return result, err
+}
+
+type DisputeEscrowTxParams struct {
+	AgreementID int64  `json:"agreement_id"`
+	DisputedBy  string `json:"disputed_by"`
+	Reason      string `json:"reason"`
+}
+
+// DisputeEscrowTx stops the deadline from refunding the buyer and leaves the outcome to a banker.
+// Disputes are only raised before the deadline, so a seller can't hold on to a refund that is due.
+func (store *SQLStore) DisputeEscrowTx(ctx context.Context, arg DisputeEscrowTxParams) (EscrowTxResult, error) {
+	var result EscrowTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		agreement, err := lockEscrowAgreement(ctx, queries, arg.AgreementID, false)
+		if err != nil {
+			return err
+		}
+		if !agreement.Deadline.After(time.Now()) {
+			return ErrEscrowDeadlinePassed
+		}
+
+		result.Agreement, err = queries.DisputeEscrowAgreement(ctx, DisputeEscrowAgreementParams{
+			ID:            agreement.ID,
+			DisputedBy:    sql.NullString{String: arg.DisputedBy, Valid: true},
+			DisputeReason: arg.Reason,
+		})
+		return err
+	})
+	return result, err
+}
+
+// lockEscrowAgreement locks an agreement whose funds are still in escrow.
+// A disputed one is only returned when the dispute is being resolved.
+func lockEscrowAgreement(ctx context.Context, q *Queries, agreementID int64, resolveDispute bool) (EscrowAgreement, error) {
+	agreement, err := q.GetEscrowAgreementForUpdate(ctx, agreementID)
+	if err != nil {
+		return agreement, err
+	}
+
+	switch agreement.Status {
+	case EscrowFunded:
+		return agreement, nil
+	case EscrowDisputed:
+		if resolveDispute {
+			return agreement, nil
+		}
+		return agreement, ErrEscrowDisputed
+	default:
+		return agreement, ErrEscrowSettled
+	}
+}
+
+// settleEscrow moves the escrowed funds to the seller when released or back to the buyer when refunded.
+// The house already took its fee on funding, so the move out of escrow is free.
+func settleEscrow(ctx context.Context, q *Queries, agreement EscrowAgreement, status string, actor sql.NullString) (EscrowTxResult, error) {
+	var result EscrowTxResult
+
+	toAccountID, description := agreement.SellerAccountID, "Escrow release"
+	if status == EscrowRefunded {
+		toAccountID, description = agreement.BuyerAccountID, "Escrow refund"
+	}
+
+	transferResult, err := transfer(ctx, q, CreateTransferParams{
+		FromAccountID: agreement.EscrowAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        agreement.Amount,
+		Description:   description,
+		Metadata:      escrowMetadata(agreement),
+	}, Fee{})
+	if err != nil {
+		return result, err
+	}
+	result.Transfer = &transferResult
+
+	result.Agreement, err = q.SettleEscrowAgreement(ctx, SettleEscrowAgreementParams{
+		ID:                   agreement.ID,
+		Status:               status,
+		SettlementTransferID: sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true},
+		ResolvedBy:           actor,
+	})
+	return result, err
+}
+
+func escrowMetadata(agreement EscrowAgreement) json.RawMessage {
+	// a map of strings always marshals
+	metadata, _ := json.Marshal(map[string]string{"escrow_agreement_id": strconv.FormatInt(agreement.ID, 10)})
+	return metadata
+}
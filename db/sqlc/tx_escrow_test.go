@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func createFundedEscrow(t *testing.T, buyer, seller Account, deadline time.Time) EscrowTxResult {
+	store := NewStore(testDB)
+
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: buyer.ID, Balance: 1000})
+	require.NoError(t, err)
+
+	result, err := store.CreateEscrowTx(context.Background(), CreateEscrowTxParams{
+		Buyer:           buyer.Owner,
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+		Amount:          60,
+		Currency:        buyer.Currency,
+		Condition:       "Ship the bike",
+		Deadline:        deadline,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowFunded, result.Agreement.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Agreement.FundingTransferID.Int64)
+	require.Equal(t, result.Agreement.EscrowAccountID, result.Transfer.ToAccount.ID)
+	require.Equal(t, AccountTypeEscrow, result.Transfer.ToAccount.Type)
+	require.Equal(t, int64(1000-60)-result.Transfer.Fee.Total, result.Transfer.FromAccount.Balance)
+
+	metadata := `{"escrow_agreement_id": "` + strconv.FormatInt(result.Agreement.ID, 10) + `"}`
+	require.JSONEq(t, metadata, string(result.Transfer.Transfer.Metadata))
+	return result
+}
+
+func TestStore_ReleaseEscrow(t *testing.T) {
+	store := NewStore(testDB)
+
+	buyer := createRandomAccount(t)
+	seller := createRandomAccountInCurrency(t, buyer.Currency)
+	funded := createFundedEscrow(t, buyer, seller, time.Now().Add(time.Hour))
+
+	released, err := store.ReleaseEscrowTx(context.Background(), SettleEscrowTxParams{
+		AgreementID: funded.Agreement.ID,
+		Actor:       buyer.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowReleased, released.Agreement.Status)
+	require.Equal(t, buyer.Owner, released.Agreement.ResolvedBy.String)
+	require.Equal(t, released.Transfer.Transfer.ID, released.Agreement.SettlementTransferID.Int64)
+	require.Equal(t, seller.Balance+60, released.Transfer.ToAccount.Balance)
+	require.Zero(t, released.Transfer.Fee.Total)
+
+	_, err = store.RefundEscrowTx(context.Background(), SettleEscrowTxParams{
+		AgreementID: funded.Agreement.ID,
+		Actor:       seller.Owner,
+	})
+	require.ErrorIs(t, err, ErrEscrowSettled)
+}
+
+func TestStore_DisputeEscrow(t *testing.T) {
+	store := NewStore(testDB)
+
+	buyer := createRandomAccount(t)
+	seller := createRandomAccountInCurrency(t, buyer.Currency)
+	funded := createFundedEscrow(t, buyer, seller, time.Now().Add(time.Hour))
+
+	disputed, err := store.DisputeEscrowTx(context.Background(), DisputeEscrowTxParams{
+		AgreementID: funded.Agreement.ID,
+		DisputedBy:  seller.Owner,
+		Reason:      "Paid for the wrong bike",
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowDisputed, disputed.Agreement.Status)
+	require.Equal(t, seller.Owner, disputed.Agreement.DisputedBy.String)
+	require.Nil(t, disputed.Transfer)
+
+	// only a banker settles a disputed agreement
+	_, err = store.ReleaseEscrowTx(context.Background(), SettleEscrowTxParams{
+		AgreementID: funded.Agreement.ID,
+		Actor:       buyer.Owner,
+	})
+	require.ErrorIs(t, err, ErrEscrowDisputed)
+
+	banker := createRandomUser(t)
+	refunded, err := store.RefundEscrowTx(context.Background(), SettleEscrowTxParams{
+		AgreementID:    funded.Agreement.ID,
+		Actor:          banker.Username,
+		ResolveDispute: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowRefunded, refunded.Agreement.Status)
+	require.Equal(t, buyer.ID, refunded.Transfer.ToAccount.ID)
+	require.Equal(t, funded.Transfer.FromAccount.Balance+60, refunded.Transfer.ToAccount.Balance)
+}
+
+func TestStore_ExpireEscrow(t *testing.T) {
+	store := NewStore(testDB)
+
+	buyer := createRandomAccount(t)
+	seller := createRandomAccountInCurrency(t, buyer.Currency)
+
+	pending := createFundedEscrow(t, buyer, seller, time.Now().Add(time.Hour))
+	_, err := store.ExpireEscrowTx(context.Background(), pending.Agreement.ID)
+	require.ErrorIs(t, err, ErrEscrowDeadlineNotDue)
+
+	funded := createFundedEscrow(t, buyer, seller, time.Now().Add(-time.Minute))
+	_, err = store.DisputeEscrowTx(context.Background(), DisputeEscrowTxParams{
+		AgreementID: funded.Agreement.ID,
+		DisputedBy:  seller.Owner,
+		Reason:      "Too late",
+	})
+	require.ErrorIs(t, err, ErrEscrowDeadlinePassed)
+
+	overdue, err := testQueries.ListOverdueEscrowAgreements(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, overdue, funded.Agreement)
+	require.NotContains(t, overdue, pending.Agreement)
+
+	refunded, err := store.ExpireEscrowTx(context.Background(), funded.Agreement.ID)
+	require.NoError(t, err)
+	require.Equal(t, EscrowRefunded, refunded.Agreement.Status)
+	require.False(t, refunded.Agreement.ResolvedBy.Valid)
+	require.Equal(t, buyer.ID, refunded.Transfer.ToAccount.ID)
+
+	agreements, err := testQueries.ListEscrowAgreements(context.Background(), ListEscrowAgreementsParams{
+		AccountID: seller.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, agreements, 2)
+	require.Equal(t, funded.Agreement.ID, agreements[0].ID)
+}
+
+func TestStore_CreateEscrowSameAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	_, err := store.CreateEscrowTx(context.Background(), CreateEscrowTxParams{
+		Buyer:           account.Owner,
+		BuyerAccountID:  account.ID,
+		SellerAccountID: account.ID,
+		Amount:          10,
+		Currency:        account.Currency,
+		Condition:       "Nothing",
+		Deadline:        time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrEscrowAccountMismatch)
+}
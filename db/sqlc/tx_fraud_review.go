@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	FraudDecisionAllow  = "allow"
+	FraudDecisionReview = "review"
+	FraudDecisionDeny   = "deny"
+
+	FraudReviewPending  = "pending"
+	FraudReviewApproved = "approved"
+	FraudReviewRejected = "rejected"
+)
+
+var ErrReviewNotPending = errors.New("fraud review was already completed")
+
+// FraudAssessment is the outcome of screening a transfer against the fraud rules
+type FraudAssessment struct {
+	Decision string `json:"decision"`
+	// Score adds up the scores of the matched rules
+	Score        int64    `json:"score"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+// RecordFraudDecision stores the screening of a transfer that was not performed.
+// Reviews wait in the queue until a banker approves or rejects them.
+func (store *SQLStore) RecordFraudDecision(ctx context.Context, arg TransferTxParams, assessment FraudAssessment) (FraudDecision, error) {
+	var reviewStatus sql.NullString
+	if assessment.Decision == FraudDecisionReview {
+		reviewStatus = sql.NullString{String: FraudReviewPending, Valid: true}
+	}
+	var decision FraudDecision
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		decision, err = insertFraudDecision(ctx, queries, arg, assessment, reviewStatus, sql.NullInt64{})
+		return err
+	})
+	return decision, err
+}
+
+// recordScreening stores the screening that allowed a transfer, linked to it
+func recordScreening(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) error {
+	if arg.Screening == nil {
+		return nil
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	decision, err := insertFraudDecision(ctx, q, arg, *arg.Screening, sql.NullString{}, transferID)
+	if err != nil {
+		return err
+	}
+	result.FraudDecision = &decision
+	return nil
+}
+
+func insertFraudDecision(ctx context.Context, q *Queries, arg TransferTxParams, assessment FraudAssessment,
+	reviewStatus sql.NullString, transferID sql.NullInt64) (FraudDecision, error) {
+	request, err := json.Marshal(arg)
+	if err != nil {
+		return FraudDecision{}, err
+	}
+	matched := assessment.MatchedRules
+	if matched == nil {
+		matched = []string{}
+	}
+	matchedRules, err := json.Marshal(matched)
+	if err != nil {
+		return FraudDecision{}, err
+	}
+
+	return q.CreateFraudDecision(ctx, CreateFraudDecisionParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Currency:      arg.Currency,
+		Decision:      assessment.Decision,
+		Score:         assessment.Score,
+		MatchedRules:  matchedRules,
+		Request:       request,
+		ReviewStatus:  reviewStatus,
+		TransferID:    transferID,
+	})
+}
+
+type ReviewFraudDecisionTxParams struct {
+	DecisionID int64  `json:"decision_id"`
+	Approve    bool   `json:"approve"`
+	Reviewer   string `json:"reviewer"`
+}
+
+type ReviewFraudDecisionTxResult struct {
+	Decision FraudDecision `json:"decision"`
+	// Transfer is only set when the review is approved
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// ReviewFraudDecisionTx completes a pending review. Approving performs the transfer as it was requested,
+// with the fee and limits of the moment; rejecting drops it.
+func (store *SQLStore) ReviewFraudDecisionTx(ctx context.Context, arg ReviewFraudDecisionTxParams) (ReviewFraudDecisionTxResult, error) {
+	var result ReviewFraudDecisionTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		decision, err := queries.GetFraudDecisionForUpdate(ctx, arg.DecisionID)
+		if err != nil {
+			return err
+		}
+		if decision.ReviewStatus.String != FraudReviewPending {
+			return ErrReviewNotPending
+		}
+
+		status := FraudReviewRejected
+		var transferID sql.NullInt64
+		if arg.Approve {
+			status = FraudReviewApproved
+
+			var request TransferTxParams
+			if err := json.Unmarshal(decision.Request, &request); err != nil {
+				return err
+			}
+			transferResult, err := performTransfer(ctx, queries, request)
+			if err != nil {
+				return err
+			}
+			result.Transfer = &transferResult
+			transferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+		}
+
+		result.Decision, err = queries.CompleteFraudReview(ctx, CompleteFraudReviewParams{
+			ReviewStatus: sql.NullString{String: status, Valid: true},
+			ReviewedBy:   sql.NullString{String: arg.Reviewer, Valid: true},
+			TransferID:   transferID,
+			ID:           decision.ID,
+		})
+		return err
+	})
+	return result, err
+}
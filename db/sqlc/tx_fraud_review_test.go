@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_ReviewFraudDecisionTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+	reviewer := createRandomUser(t)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		Description:   "held",
+	}
+	assessment := FraudAssessment{Decision: FraudDecisionReview, Score: 30, MatchedRules: []string{"bursts"}}
+
+	decision, err := store.RecordFraudDecision(context.Background(), arg, assessment)
+	require.NoError(t, err)
+	require.Equal(t, FraudDecisionReview, decision.Decision)
+	require.Equal(t, FraudReviewPending, decision.ReviewStatus.String)
+	require.False(t, decision.TransferID.Valid)
+
+	var matched []string
+	require.NoError(t, json.Unmarshal(decision.MatchedRules, &matched))
+	require.Equal(t, assessment.MatchedRules, matched)
+
+	result, err := store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: decision.ID,
+		Approve:    true,
+		Reviewer:   reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, arg.Amount, result.Transfer.Transfer.Amount)
+	require.Equal(t, "held", result.Transfer.Transfer.Description)
+	require.Equal(t, FraudReviewApproved, result.Decision.ReviewStatus.String)
+	require.Equal(t, reviewer.Username, result.Decision.ReviewedBy.String)
+	require.True(t, result.Decision.ReviewedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Decision.TransferID.Int64)
+
+	_, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: decision.ID,
+		Reviewer:   reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrReviewNotPending)
+
+	decision, err = store.RecordFraudDecision(context.Background(), arg, assessment)
+	require.NoError(t, err)
+
+	result, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: decision.ID,
+		Reviewer:   reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.Transfer)
+	require.Equal(t, FraudReviewRejected, result.Decision.ReviewStatus.String)
+	require.False(t, result.Decision.TransferID.Valid)
+}
+
+func TestStore_TransferTxRecordsScreening(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountInCurrency(t, account1.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		Screening:     &FraudAssessment{Decision: FraudDecisionAllow},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.FraudDecision)
+	require.Equal(t, FraudDecisionAllow, result.FraudDecision.Decision)
+	require.Equal(t, result.Transfer.ID, result.FraudDecision.TransferID.Int64)
+	require.False(t, result.FraudDecision.ReviewStatus.Valid)
+	require.JSONEq(t, `[]`, string(result.FraudDecision.MatchedRules))
+}
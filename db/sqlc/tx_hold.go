@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	HoldStatusPending  = "pending"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	HoldStatusExpired  = "expired"
+
+	// HoldPurposePayment holds are captured by their recipient
+	HoldPurposePayment = "payment"
+	// HoldPurposeApproval holds reserve a transfer waiting for approval, only its review or expiry releases them
+	HoldPurposeApproval = "approval"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("insufficient available balance")
+	ErrHoldNotPending     = errors.New("hold is no longer pending")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrHoldNotExpired     = errors.New("hold has not expired yet")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+	ErrApprovalHold       = errors.New("hold reserves a transfer waiting for approval")
+)
+
+type AuthorizeHoldTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// Screening is the fraud assessment that allowed the hold, recorded along with the transfer capturing it
+	Screening *FraudAssessment `json:"-"`
+}
+
+type HoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// AvailableBalance is what an account can still spend: its balance less pending holds, plus its overdraft limit
+func AvailableBalance(account Account) int64 {
+	return account.Balance - account.HeldBalance + account.OverdraftLimit
+}
+
+// checkFunds fails when a debit took the locked account past what it could spend,
+// so funds held for a capture or covered by no overdraft can't be sent away
+func checkFunds(account Account) error {
+	if AvailableBalance(account) < 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// AuthorizeHoldTx reserves funds on an account without moving them.
+// The held amount lowers the available balance until the hold is captured, voided or expires.
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		result, err = authorizeHold(ctx, queries, arg, HoldPurposePayment)
+		return err
+	})
+	return result, err
+}
+
+// authorizeHold does the work of AuthorizeHoldTx with the given queries, for a hold with the purpose
+func authorizeHold(ctx context.Context, q *Queries, arg AuthorizeHoldTxParams, purpose string) (result HoldTxResult, err error) {
+	account, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+	if err != nil {
+		return
+	}
+	if err = checkAccountActive(account); err != nil {
+		return
+	}
+	if AvailableBalance(account) < arg.Amount {
+		err = ErrInsufficientFunds
+		return
+	}
+	screening, err := json.Marshal(arg.Screening)
+	if err != nil {
+		return
+	}
+
+	result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ExpiresAt:     arg.ExpiresAt,
+		Screening:     screening,
+		Purpose:       purpose,
+	})
+	if err != nil {
+		return
+	}
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		Amount: arg.Amount,
+		ID:     arg.FromAccountID,
+	})
+	return
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount to settle; zero captures the whole hold
+	Amount int64 `json:"amount"`
+	// Screening is the fraud assessment of the capture, the one that allowed the hold is recorded when it's nil
+	Screening *FraudAssessment `json:"-"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx settles a pending hold with a transfer of up to the held amount,
+// charged the fee and counted against the limits of the moment like any other transfer.
+// Whatever is not captured goes back to the available balance.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		hold, err := queries.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if err := checkPaymentHold(hold); err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		// the fee is quoted in the currency of the sender, which the recipient shares
+		from, err := queries.GetAccount(ctx, hold.FromAccountID)
+		if err != nil {
+			return err
+		}
+		request := TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			Currency:      from.Currency,
+		}
+		if request.Screening = arg.Screening; request.Screening == nil {
+			if err := json.Unmarshal(hold.Screening, &request.Screening); err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = settleTransfer(ctx, queries, request, &hold)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = queries.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return result, err
+}
+
+// VoidHoldTx cancels a pending hold and releases its funds.
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		hold, err := queries.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if err := checkPaymentHold(hold); err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, queries, hold, HoldStatusVoided)
+		return err
+	})
+	return result, err
+}
+
+// ExpireHoldTx releases a pending hold whose expiration time has passed.
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		hold, err := queries.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if err := checkPaymentHold(hold); err != nil {
+			return err
+		}
+		if hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldNotExpired
+		}
+
+		result, err = releaseHold(ctx, queries, hold, HoldStatusExpired)
+		return err
+	})
+	return result, err
+}
+
+// checkPaymentHold fails unless the hold is pending and captured by its recipient,
+// the holds of transfers waiting for approval are left to the approval
+func checkPaymentHold(hold Hold) error {
+	if hold.Purpose != HoldPurposePayment {
+		return ErrApprovalHold
+	}
+	if hold.Status != HoldStatusPending {
+		return ErrHoldNotPending
+	}
+	return nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (result HoldTxResult, err error) {
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		Amount: -hold.Amount,
+		ID:     hold.FromAccountID,
+	})
+	if err != nil {
+		return
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return
+}
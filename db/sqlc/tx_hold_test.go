@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_AuthorizeAndCaptureHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	merchant := createRandomAccountInCurrency(t, payer.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	authorized, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        60,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		Screening:     &FraudAssessment{Decision: FraudDecisionAllow, MatchedRules: []string{}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusPending, authorized.Hold.Status)
+	require.Equal(t, HoldPurposePayment, authorized.Hold.Purpose)
+	require.Equal(t, int64(100), authorized.FromAccount.Balance)
+	require.Equal(t, int64(60), authorized.FromAccount.HeldBalance)
+
+	// the held funds can't be reserved twice
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        50,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: authorized.Hold.ID,
+		Amount: 61,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	captured, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:    authorized.Hold.ID,
+		Amount:    45,
+		Screening: &FraudAssessment{Decision: FraudDecisionAllow, Score: 5, MatchedRules: []string{}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, captured.Hold.Status)
+	require.Equal(t, int64(45), captured.Hold.CapturedAmount)
+	require.Equal(t, captured.Transfer.Transfer.ID, captured.Hold.TransferID.Int64)
+	// the capture is charged its fee and recorded with its own screening
+	require.Equal(t, 55-captured.Transfer.Fee.Total, captured.Transfer.FromAccount.Balance)
+	require.Zero(t, captured.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, merchant.Balance+45, captured.Transfer.ToAccount.Balance)
+	require.NotNil(t, captured.Transfer.FraudDecision)
+	require.Equal(t, captured.Transfer.Transfer.ID, captured.Transfer.FraudDecision.TransferID.Int64)
+	require.Equal(t, int64(5), captured.Transfer.FraudDecision.Score)
+
+	_, err = store.VoidHoldTx(context.Background(), authorized.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+func TestStore_AuthorizeHoldWithinOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	merchant := createRandomAccountInCurrency(t, payer.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+	_, err = testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:             payer.ID,
+		OverdraftLimit: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	authorized, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        150,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, AvailableBalance(authorized.FromAccount))
+
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestStore_VoidAndExpireHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	merchant := createRandomAccountInCurrency(t, payer.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	voidable, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        30,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ExpireHoldTx(context.Background(), voidable.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotExpired)
+
+	voided, err := store.VoidHoldTx(context.Background(), voidable.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusVoided, voided.Hold.Status)
+	require.Zero(t, voided.FromAccount.HeldBalance)
+
+	expiring, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        40,
+		ExpiresAt:     time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	holds, err := testQueries.ListExpiredHolds(context.Background(), 1000)
+	require.NoError(t, err)
+	found := false
+	for _, hold := range holds {
+		require.Equal(t, HoldStatusPending, hold.Status)
+		found = found || hold.ID == expiring.Hold.ID
+	}
+	require.True(t, found)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: expiring.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	expired, err := store.ExpireHoldTx(context.Background(), expiring.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, expired.Hold.Status)
+	require.Zero(t, expired.FromAccount.HeldBalance)
+	require.Equal(t, int64(100), expired.FromAccount.Balance)
+}
+
+func TestStore_TransferTxRespectsHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccount(t)
+	merchant := createRandomAccountInCurrency(t, payer.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        80,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        30,
+		Currency:      payer.Currency,
+	}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the overdraft covers what the hold doesn't leave
+	_, err = testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:             payer.ID,
+		OverdraftLimit: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, AvailableBalance(result.FromAccount), int64(0))
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+
+	// InterestExpenseOwner owns the house accounts paying interest, one per currency
+	InterestExpenseOwner = "houseinterest"
+
+	// interestDenominator turns a balance times an annual rate in basis points into a daily amount,
+	// every year counts as 365 days
+	interestDenominator = 10000 * 365
+)
+
+var ErrInterestOverflow = errors.New("interest on the balance at this rate is too large to compute")
+
+// AccrueDailyInterest computes one day of interest on a balance, in minor units.
+// The carry is the fraction of a minor unit left over by the previous day, in 1/3650000ths.
+// It is added before rounding half to even, so the fractions add up over time instead of being lost.
+// Negative balances earn nothing. Rates are never negative.
+func AccrueDailyInterest(balance, annualRateBps, carry int64) (amount, nextCarry int64, err error) {
+	if balance < 0 {
+		balance = 0
+	}
+	hi, lo := bits.Mul64(uint64(balance), uint64(annualRateBps))
+	if hi != 0 || lo > math.MaxInt64 {
+		return 0, 0, ErrInterestOverflow
+	}
+	product := int64(lo)
+	exact := product + carry
+	if (carry > 0 && exact < product) || (carry < 0 && exact > product) {
+		return 0, 0, ErrInterestOverflow
+	}
+
+	amount = exact / interestDenominator
+	remainder := 2 * (exact % interestDenominator)
+	switch {
+	case remainder > interestDenominator, remainder == interestDenominator && amount%2 != 0:
+		amount++
+	case remainder < -interestDenominator, remainder == -interestDenominator && amount%2 != 0:
+		amount--
+	}
+	return amount, exact - amount*interestDenominator, nil
+}
+
+type AccrueInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// AccrualDate is the UTC midnight starting the day the interest is accrued for
+	AccrualDate time.Time `json:"accrual_date"`
+}
+
+// AccrueInterestTx records the interest an account earned over a day on its balance at the end of that day.
+// Accruing the same day again returns the existing accrual.
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error) {
+	var result InterestAccrual
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		account, err := queries.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result, err = queries.GetInterestAccrual(ctx, GetInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: arg.AccrualDate,
+		})
+		if err != sql.ErrNoRows {
+			// the day is already accrued, or the lookup failed
+			return err
+		}
+
+		var rateBps int64
+		rate, err := queries.GetInterestRate(ctx, GetInterestRateParams{
+			Currency:    account.Currency,
+			AccountType: account.Type,
+		})
+		if err == nil {
+			rateBps = rate.AnnualRateBps
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		balance, err := getBalanceAsOf(ctx, queries, account.ID, arg.AccrualDate.Add(24*time.Hour))
+		if err != nil {
+			return err
+		}
+
+		var carry int64
+		previous, err := queries.GetPreviousInterestAccrual(ctx, GetPreviousInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: arg.AccrualDate,
+		})
+		if err == nil {
+			carry = previous.Carry
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		amount, carry, err := AccrueDailyInterest(balance, rateBps, carry)
+		if err != nil {
+			return err
+		}
+		result, err = queries.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:     account.ID,
+			AccrualDate:   arg.AccrualDate,
+			Balance:       balance,
+			AnnualRateBps: rateBps,
+			Amount:        amount,
+			Carry:         carry,
+		})
+		return err
+	})
+	return result, err
+}
+
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Period is the UTC midnight starting the month whose accruals are credited
+	Period time.Time `json:"period"`
+}
+
+type PostInterestTxResult struct {
+	Posting InterestPosting `json:"posting"`
+	// Transfer is empty when nothing was accrued over the period
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// PostInterestTx credits an account with the interest accrued over a month,
+// through a transfer from the house interest expense account of its currency.
+// Posting the same month again returns the existing posting without moving any money.
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		account, err := queries.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result.Posting, err = queries.GetInterestPosting(ctx, GetInterestPostingParams{
+			AccountID: account.ID,
+			Period:    arg.Period,
+		})
+		if err != sql.ErrNoRows {
+			// the month is already posted, or the lookup failed
+			return err
+		}
+
+		total, err := queries.SumInterestAccruals(ctx, SumInterestAccrualsParams{
+			AccountID:   account.ID,
+			PeriodStart: arg.Period,
+			PeriodEnd:   arg.Period.AddDate(0, 1, 0),
+		})
+		if err != nil {
+			return err
+		}
+
+		var transferID sql.NullInt64
+		if total > 0 {
+			expenseAccount, err := queries.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+				Owner:    InterestExpenseOwner,
+				Currency: account.Currency,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot find interest expense account for %s: %w", account.Currency, err)
+			}
+
+			result.Transfer, err = transfer(ctx, queries, CreateTransferParams{
+				FromAccountID: expenseAccount.ID,
+				ToAccountID:   account.ID,
+				Amount:        total,
+				Description:   fmt.Sprintf("Interest for %s", arg.Period.Format("January 2006")),
+			}, Fee{})
+			if err != nil {
+				return err
+			}
+			transferID = sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}
+		}
+
+		result.Posting, err = queries.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID:  account.ID,
+			Period:     arg.Period,
+			Amount:     total,
+			TransferID: transferID,
+		})
+		return err
+	})
+	return result, err
+}
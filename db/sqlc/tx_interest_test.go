@@ -0,0 +1,137 @@
+package db
+
+import (
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAccrueDailyInterest(t *testing.T) {
+	testCases := []struct {
+		name          string
+		balance       int64
+		annualRateBps int64
+		carry         int64
+		amount        int64
+		nextCarry     int64
+		err           error
+	}{
+		{name: "Exact", balance: 36500, annualRateBps: 100, amount: 1},
+		{name: "HalfRoundsUpToEven", balance: 54750, annualRateBps: 100, amount: 2, nextCarry: -interestDenominator / 2},
+		{name: "HalfRoundsDownToEven", balance: 91250, annualRateBps: 100, amount: 2, nextCarry: interestDenominator / 2},
+		{name: "CarryAdded", balance: 54750, annualRateBps: 100, carry: -interestDenominator / 2, amount: 1},
+		{name: "BelowHalf", balance: 100, annualRateBps: 100, amount: 0, nextCarry: 10000},
+		{name: "NegativeBalance", balance: -5000, annualRateBps: 100, carry: 10, amount: 0, nextCarry: 10},
+		// math.MaxInt64 is 92233720368547758.07 times 100
+		{name: "LargestBalance", balance: math.MaxInt64 / 100, annualRateBps: 100, carry: 7,
+			amount: math.MaxInt64/interestDenominator + 1, nextCarry: math.MaxInt64%interestDenominator - interestDenominator},
+		{name: "BalanceOverflows", balance: math.MaxInt64/100 + 1, annualRateBps: 100, err: ErrInterestOverflow},
+		{name: "CarryOverflows", balance: math.MaxInt64 / 100, annualRateBps: 100, carry: 8, err: ErrInterestOverflow},
+		{name: "RateOverflows", balance: math.MaxInt64, annualRateBps: math.MaxInt64, err: ErrInterestOverflow},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			amount, carry, err := AccrueDailyInterest(tc.balance, tc.annualRateBps, tc.carry)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.amount, amount)
+			require.Equal(t, tc.nextCarry, carry)
+		})
+	}
+}
+
+func TestAccrueDailyInterestOverAYear(t *testing.T) {
+	// 2.5% of 1000.00 is 25.00 over a year, even though no single day is a whole number of cents
+	var total, carry int64
+	for day := 0; day < 365; day++ {
+		amount, nextCarry, err := AccrueDailyInterest(100000, 250, carry)
+		require.NoError(t, err)
+		carry = nextCarry
+		total += amount
+	}
+	require.Equal(t, int64(2500), total)
+	require.Zero(t, carry)
+}
+
+func TestStore_AccrueAndPostInterest(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := testQueries.UpsertInterestRate(context.Background(), UpsertInterestRateParams{
+		Currency:      util.CAD,
+		AccountType:   AccountTypeSavings,
+		AnnualRateBps: 365,
+	})
+	require.NoError(t, err)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Balance:  15000,
+		Currency: util.CAD,
+		Type:     sql.NullString{String: AccountTypeSavings, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountTypeSavings, account.Type)
+
+	// a new account accrues from the day it was opened
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	accounts, err := testQueries.ListAccountsToAccrue(context.Background(), ListAccountsToAccrueParams{
+		AccrualDate: today,
+		Limit:       1000,
+	})
+	require.NoError(t, err)
+	found := false
+	for _, toAccrue := range accounts {
+		if toAccrue.AccountID == account.ID {
+			found = true
+			require.True(t, today.Equal(toAccrue.NextAccrualDate))
+		}
+	}
+	require.True(t, found)
+
+	// 1.5 cents a day: rounded to 2 on the first day, the half carried over makes the second day 1
+	period := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, AccrualDate: period})
+	require.NoError(t, err)
+	require.Equal(t, int64(15000), first.Balance)
+	require.Equal(t, int64(2), first.Amount)
+
+	second, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, AccrualDate: period.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), second.Amount)
+	require.Zero(t, second.Carry)
+
+	again, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, AccrualDate: period})
+	require.NoError(t, err)
+	require.Equal(t, first.ID, again.ID)
+
+	posted, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), posted.Posting.Amount)
+	require.True(t, posted.Posting.TransferID.Valid)
+	require.Equal(t, int64(15003), posted.Transfer.ToAccount.Balance)
+
+	expenseAccount, err := testQueries.GetAccount(context.Background(), posted.Transfer.Transfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, InterestExpenseOwner, expenseAccount.Owner)
+
+	// posting the month again moves nothing
+	repost, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period})
+	require.NoError(t, err)
+	require.Equal(t, posted.Posting.ID, repost.Posting.ID)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(15003), account.Balance)
+
+	// a month without accruals is posted without a transfer
+	empty, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period.AddDate(0, 1, 0)})
+	require.NoError(t, err)
+	require.Zero(t, empty.Posting.Amount)
+	require.False(t, empty.Posting.TransferID.Valid)
+}
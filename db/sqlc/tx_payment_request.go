@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	PaymentRequestPending  = "pending"
+	PaymentRequestSettled  = "settled"
+	PaymentRequestDeclined = "declined"
+	PaymentRequestExpired  = "expired"
+
+	PaymentRequestPayerPending  = "pending"
+	PaymentRequestPayerAccepted = "accepted"
+	PaymentRequestPayerDeclined = "declined"
+	PaymentRequestPayerExpired  = "expired"
+)
+
+var (
+	ErrPaymentRequestNotPending = errors.New("payment request is no longer pending")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+	ErrPaymentRequestNotExpired = errors.New("payment request has not expired yet")
+)
+
+// PaymentRequestShare is the amount one payer is asked for
+type PaymentRequestShare struct {
+	Payer  string `json:"payer"`
+	Amount int64  `json:"amount"`
+}
+
+type CreatePaymentRequestTxParams struct {
+	Requester   string                `json:"requester"`
+	ToAccountID int64                 `json:"to_account_id"`
+	Currency    string                `json:"currency"`
+	Description string                `json:"description"`
+	ExpiresAt   time.Time             `json:"expires_at"`
+	Shares      []PaymentRequestShare `json:"shares"`
+}
+
+type PaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest        `json:"payment_request"`
+	Payers         []PaymentRequestPayer `json:"payers"`
+	// Transfer is only set when a payer accepted
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// CreatePaymentRequestTx asks one or more payers for a share each of an amount, paid into the requester's account
+func (store *SQLStore) CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestTxParams) (PaymentRequestTxResult, error) {
+	var result PaymentRequestTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var amount int64
+		for _, share := range arg.Shares {
+			amount += share.Amount
+		}
+
+		var err error
+		result.PaymentRequest, err = queries.CreatePaymentRequest(ctx, CreatePaymentRequestParams{
+			Requester:   arg.Requester,
+			ToAccountID: arg.ToAccountID,
+			Amount:      amount,
+			Currency:    arg.Currency,
+			Description: arg.Description,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payers = make([]PaymentRequestPayer, len(arg.Shares))
+		for i, share := range arg.Shares {
+			result.Payers[i], err = queries.CreatePaymentRequestPayer(ctx, CreatePaymentRequestPayerParams{
+				PaymentRequestID: result.PaymentRequest.ID,
+				Payer:            share.Payer,
+				Amount:           share.Amount,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// PaymentRequestTransfer is the transfer paying a payer's share of a request from one of their accounts.
+// Its metadata links it back to the request.
+func PaymentRequestTransfer(request PaymentRequest, payer PaymentRequestPayer, fromAccountID int64) TransferTxParams {
+	// a map of strings always marshals
+	metadata, _ := json.Marshal(map[string]string{"payment_request_id": strconv.FormatInt(request.ID, 10)})
+	return TransferTxParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   request.ToAccountID,
+		Amount:        payer.Amount,
+		Currency:      request.Currency,
+		Description:   request.Description,
+		Metadata:      metadata,
+		Actor:         payer.Payer,
+	}
+}
+
+type AcceptPaymentRequestTxParams struct {
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Payer            string `json:"payer"`
+	FromAccountID    int64  `json:"from_account_id"`
+	// Screening is the fraud assessment that allowed the transfer of the share
+	Screening *FraudAssessment `json:"-"`
+}
+
+// AcceptPaymentRequestTx pays the payer's share of a request from their account. The request is settled
+// once every payer answered.
+func (store *SQLStore) AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (PaymentRequestTxResult, error) {
+	var result PaymentRequestTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		request, payer, err := lockPaymentRequestPayer(ctx, queries, arg.PaymentRequestID, arg.Payer)
+		if err != nil {
+			return err
+		}
+
+		transfer := PaymentRequestTransfer(request, payer, arg.FromAccountID)
+		transfer.Screening = arg.Screening
+		transferResult, err := performTransfer(ctx, queries, transfer)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &transferResult
+
+		_, err = queries.UpdatePaymentRequestPayer(ctx, UpdatePaymentRequestPayerParams{
+			Status:     PaymentRequestPayerAccepted,
+			TransferID: sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true},
+			ID:         payer.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, result.Payers, err = settlePaymentRequest(ctx, queries, request)
+		return err
+	})
+	return result, err
+}
+
+type DeclinePaymentRequestTxParams struct {
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Payer            string `json:"payer"`
+}
+
+// DeclinePaymentRequestTx refuses the payer's share of a request
+func (store *SQLStore) DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequestTxResult, error) {
+	var result PaymentRequestTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		request, payer, err := lockPaymentRequestPayer(ctx, queries, arg.PaymentRequestID, arg.Payer)
+		if err != nil {
+			return err
+		}
+
+		_, err = queries.UpdatePaymentRequestPayer(ctx, UpdatePaymentRequestPayerParams{
+			Status: PaymentRequestPayerDeclined,
+			ID:     payer.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, result.Payers, err = settlePaymentRequest(ctx, queries, request)
+		return err
+	})
+	return result, err
+}
+
+// ExpirePaymentRequestTx gives up on the shares of a request nobody answered in time
+func (store *SQLStore) ExpirePaymentRequestTx(ctx context.Context, paymentRequestID int64) (PaymentRequestTxResult, error) {
+	var result PaymentRequestTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		request, err := queries.GetPaymentRequestForUpdate(ctx, paymentRequestID)
+		if err != nil {
+			return err
+		}
+		if request.Status != PaymentRequestPending {
+			return ErrPaymentRequestNotPending
+		}
+		if request.ExpiresAt.After(time.Now()) {
+			return ErrPaymentRequestNotExpired
+		}
+
+		if err := queries.ExpirePaymentRequestPayers(ctx, request.ID); err != nil {
+			return err
+		}
+		result.PaymentRequest, err = queries.UpdatePaymentRequestStatus(ctx, UpdatePaymentRequestStatusParams{
+			ID:     request.ID,
+			Status: PaymentRequestExpired,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payers, err = queries.ListPaymentRequestPayers(ctx, request.ID)
+		return err
+	})
+	return result, err
+}
+
+// lockPaymentRequestPayer locks a pending request, then the payer's share of it as long as it's unanswered.
+// The request is locked first so payers answering at once settle it in turn.
+func lockPaymentRequestPayer(ctx context.Context, q *Queries, paymentRequestID int64, payer string) (PaymentRequest, PaymentRequestPayer, error) {
+	request, err := q.GetPaymentRequestForUpdate(ctx, paymentRequestID)
+	if err != nil {
+		return request, PaymentRequestPayer{}, err
+	}
+	if request.Status != PaymentRequestPending {
+		return request, PaymentRequestPayer{}, ErrPaymentRequestNotPending
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		return request, PaymentRequestPayer{}, ErrPaymentRequestExpired
+	}
+
+	share, err := q.GetPaymentRequestPayerForUpdate(ctx, GetPaymentRequestPayerForUpdateParams{
+		PaymentRequestID: request.ID,
+		Payer:            payer,
+	})
+	if err != nil {
+		return request, share, err
+	}
+	if share.Status != PaymentRequestPayerPending {
+		return request, share, ErrPaymentRequestNotPending
+	}
+	return request, share, nil
+}
+
+// settlePaymentRequest closes a request once none of its payers is left to answer:
+// it is settled when at least one of them paid and declined otherwise
+func settlePaymentRequest(ctx context.Context, q *Queries, request PaymentRequest) (PaymentRequest, []PaymentRequestPayer, error) {
+	payers, err := q.ListPaymentRequestPayers(ctx, request.ID)
+	if err != nil {
+		return request, nil, err
+	}
+
+	status := PaymentRequestDeclined
+	for _, payer := range payers {
+		switch payer.Status {
+		case PaymentRequestPayerPending:
+			return request, payers, nil
+		case PaymentRequestPayerAccepted:
+			status = PaymentRequestSettled
+		}
+	}
+
+	request, err = q.UpdatePaymentRequestStatus(ctx, UpdatePaymentRequestStatusParams{
+		ID:     request.ID,
+		Status: status,
+	})
+	return request, payers, err
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStore_SplitPaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+
+	to := createRandomAccount(t)
+	first := createRandomAccountInCurrency(t, to.Currency)
+	second := createRandomAccountInCurrency(t, to.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: first.ID, Balance: 100})
+	require.NoError(t, err)
+
+	created, err := store.CreatePaymentRequestTx(context.Background(), CreatePaymentRequestTxParams{
+		Requester:   to.Owner,
+		ToAccountID: to.ID,
+		Currency:    to.Currency,
+		Description: "Dinner",
+		ExpiresAt:   time.Now().Add(time.Hour),
+		Shares: []PaymentRequestShare{
+			{Payer: first.Owner, Amount: 60},
+			{Payer: second.Owner, Amount: 40},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), created.PaymentRequest.Amount)
+	require.Equal(t, PaymentRequestPending, created.PaymentRequest.Status)
+	require.Len(t, created.Payers, 2)
+
+	accepted, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: created.PaymentRequest.ID,
+		Payer:            first.Owner,
+		FromAccountID:    first.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestPending, accepted.PaymentRequest.Status)
+	require.NotNil(t, accepted.Transfer)
+	require.Equal(t, int64(40), accepted.Transfer.FromAccount.Balance)
+	require.Equal(t, to.Balance+60, accepted.Transfer.ToAccount.Balance)
+	require.JSONEq(t, `{"payment_request_id": "`+strconv.FormatInt(created.PaymentRequest.ID, 10)+`"}`, string(accepted.Transfer.Transfer.Metadata))
+	require.Equal(t, PaymentRequestPayerAccepted, accepted.Payers[0].Status)
+	require.Equal(t, accepted.Transfer.Transfer.ID, accepted.Payers[0].TransferID.Int64)
+
+	// a share is only answered once
+	_, err = store.DeclinePaymentRequestTx(context.Background(), DeclinePaymentRequestTxParams{
+		PaymentRequestID: created.PaymentRequest.ID,
+		Payer:            first.Owner,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	declined, err := store.DeclinePaymentRequestTx(context.Background(), DeclinePaymentRequestTxParams{
+		PaymentRequestID: created.PaymentRequest.ID,
+		Payer:            second.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestSettled, declined.PaymentRequest.Status)
+	require.Equal(t, PaymentRequestPayerDeclined, declined.Payers[1].Status)
+	require.Nil(t, declined.Transfer)
+}
+
+func TestStore_DeclinePaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+
+	to := createRandomAccount(t)
+	payer := createRandomUser(t)
+
+	created, err := store.CreatePaymentRequestTx(context.Background(), CreatePaymentRequestTxParams{
+		Requester:   to.Owner,
+		ToAccountID: to.ID,
+		Currency:    to.Currency,
+		ExpiresAt:   time.Now().Add(time.Hour),
+		Shares:      []PaymentRequestShare{{Payer: payer.Username, Amount: 10}},
+	})
+	require.NoError(t, err)
+
+	declined, err := store.DeclinePaymentRequestTx(context.Background(), DeclinePaymentRequestTxParams{
+		PaymentRequestID: created.PaymentRequest.ID,
+		Payer:            payer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestDeclined, declined.PaymentRequest.Status)
+
+	_, err = store.ExpirePaymentRequestTx(context.Background(), created.PaymentRequest.ID)
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
+
+func TestStore_ExpirePaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+
+	to := createRandomAccount(t)
+	payer := createRandomAccountInCurrency(t, to.Currency)
+
+	pending, err := store.CreatePaymentRequestTx(context.Background(), CreatePaymentRequestTxParams{
+		Requester:   to.Owner,
+		ToAccountID: to.ID,
+		Currency:    to.Currency,
+		ExpiresAt:   time.Now().Add(time.Hour),
+		Shares:      []PaymentRequestShare{{Payer: payer.Owner, Amount: 10}},
+	})
+	require.NoError(t, err)
+	_, err = store.ExpirePaymentRequestTx(context.Background(), pending.PaymentRequest.ID)
+	require.ErrorIs(t, err, ErrPaymentRequestNotExpired)
+
+	created, err := store.CreatePaymentRequestTx(context.Background(), CreatePaymentRequestTxParams{
+		Requester:   to.Owner,
+		ToAccountID: to.ID,
+		Currency:    to.Currency,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		Shares:      []PaymentRequestShare{{Payer: payer.Owner, Amount: 10}},
+	})
+	require.NoError(t, err)
+
+	_, err = store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		PaymentRequestID: created.PaymentRequest.ID,
+		Payer:            payer.Owner,
+		FromAccountID:    payer.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+
+	expired, err := testQueries.ListExpiredPaymentRequests(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, expired, created.PaymentRequest)
+
+	result, err := store.ExpirePaymentRequestTx(context.Background(), created.PaymentRequest.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestExpired, result.PaymentRequest.Status)
+	require.Len(t, result.Payers, 1)
+	require.Equal(t, PaymentRequestPayerExpired, result.Payers[0].Status)
+}
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	PendingTransferPending  = "pending"
+	PendingTransferApproved = "approved"
+	PendingTransferRejected = "rejected"
+	PendingTransferExpired  = "expired"
+
+	// the requested action only appears in the trail
+	PendingTransferRequested = "requested"
+)
+
+var (
+	ErrPendingTransferNotPending = errors.New("transfer is no longer pending approval")
+	ErrPendingTransferExpired    = errors.New("transfer approval has expired")
+	ErrPendingTransferNotExpired = errors.New("transfer approval has not expired yet")
+	ErrSelfApproval              = errors.New("a transfer must be approved by someone other than its maker")
+)
+
+type RequestTransferApprovalTxParams struct {
+	Transfer  TransferTxParams `json:"transfer"`
+	Maker     string           `json:"maker"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+type PendingTransferTxResult struct {
+	PendingTransfer PendingTransfer `json:"pending_transfer"`
+	// Trail lists every step of the approval, oldest first
+	Trail []PendingTransferEvent `json:"trail"`
+	// Transfer is only set once approved
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// RequestTransferApprovalTx holds a transfer until someone other than its maker approves it.
+// The amount is reserved on the sender with a hold expiring along with the approval.
+func (store *SQLStore) RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		request, err := json.Marshal(arg.Transfer)
+		if err != nil {
+			return err
+		}
+		screening, err := json.Marshal(arg.Transfer.Screening)
+		if err != nil {
+			return err
+		}
+
+		hold, err := authorizeHold(ctx, queries, AuthorizeHoldTxParams{
+			FromAccountID: arg.Transfer.FromAccountID,
+			ToAccountID:   arg.Transfer.ToAccountID,
+			Amount:        arg.Transfer.Amount,
+			ExpiresAt:     arg.ExpiresAt,
+		}, HoldPurposeApproval)
+		if err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = queries.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID: arg.Transfer.FromAccountID,
+			ToAccountID:   arg.Transfer.ToAccountID,
+			Amount:        arg.Transfer.Amount,
+			Currency:      arg.Transfer.Currency,
+			Request:       request,
+			Screening:     screening,
+			Maker:         arg.Maker,
+			HoldID:        hold.Hold.ID,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Trail, err = addPendingTransferEvent(ctx, queries, result.PendingTransfer.ID, PendingTransferRequested, arg.Maker, "")
+		return err
+	})
+	return result, err
+}
+
+type ReviewPendingTransferTxParams struct {
+	PendingTransferID int64  `json:"pending_transfer_id"`
+	Approve           bool   `json:"approve"`
+	Approver          string `json:"approver"`
+	Note              string `json:"note"`
+}
+
+// ReviewPendingTransferTx approves or rejects a transfer waiting for approval. Approving performs it
+// as it was requested, with the fee and limits of the moment; either way the reserved amount is released.
+func (store *SQLStore) ReviewPendingTransferTx(ctx context.Context, arg ReviewPendingTransferTxParams) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		pending, err := queries.GetPendingTransferForUpdate(ctx, arg.PendingTransferID)
+		if err != nil {
+			return err
+		}
+		if pending.Status != PendingTransferPending {
+			return ErrPendingTransferNotPending
+		}
+		if !pending.ExpiresAt.After(time.Now()) {
+			return ErrPendingTransferExpired
+		}
+		if arg.Approver == pending.Maker {
+			return ErrSelfApproval
+		}
+
+		hold, err := queries.GetHoldForUpdate(ctx, pending.HoldID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != HoldStatusPending {
+			return ErrPendingTransferExpired
+		}
+
+		status := PendingTransferRejected
+		var transferID sql.NullInt64
+		if arg.Approve {
+			status = PendingTransferApproved
+
+			var request TransferTxParams
+			if err := json.Unmarshal(pending.Request, &request); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(pending.Screening, &request.Screening); err != nil {
+				return err
+			}
+			// the maker spends, not the approver
+			request.Actor = pending.Maker
+
+			transferResult, err := settleTransfer(ctx, queries, request, &hold)
+			if err != nil {
+				return err
+			}
+			transferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+
+			_, err = queries.UpdateHold(ctx, UpdateHoldParams{
+				ID:             hold.ID,
+				Status:         HoldStatusCaptured,
+				CapturedAmount: hold.Amount,
+				TransferID:     transferID,
+			})
+			if err != nil {
+				return err
+			}
+			result.Transfer = &transferResult
+		} else if _, err := releaseHold(ctx, queries, hold, HoldStatusVoided); err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = queries.UpdatePendingTransferStatus(ctx, UpdatePendingTransferStatusParams{
+			Status:     status,
+			TransferID: transferID,
+			ID:         pending.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Trail, err = addPendingTransferEvent(ctx, queries, pending.ID, status, arg.Approver, arg.Note)
+		return err
+	})
+	return result, err
+}
+
+// ExpirePendingTransferTx gives up on a transfer nobody approved in time and releases its reserved amount.
+func (store *SQLStore) ExpirePendingTransferTx(ctx context.Context, pendingTransferID int64) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		pending, err := queries.GetPendingTransferForUpdate(ctx, pendingTransferID)
+		if err != nil {
+			return err
+		}
+		if pending.Status != PendingTransferPending {
+			return ErrPendingTransferNotPending
+		}
+		if pending.ExpiresAt.After(time.Now()) {
+			return ErrPendingTransferNotExpired
+		}
+
+		hold, err := queries.GetHoldForUpdate(ctx, pending.HoldID)
+		if err != nil {
+			return err
+		}
+		if hold.Status == HoldStatusPending {
+			if _, err := releaseHold(ctx, queries, hold, HoldStatusExpired); err != nil {
+				return err
+			}
+		}
+
+		result.PendingTransfer, err = queries.UpdatePendingTransferStatus(ctx, UpdatePendingTransferStatusParams{
+			Status: PendingTransferExpired,
+			ID:     pending.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Trail, err = addPendingTransferEvent(ctx, queries, pending.ID, PendingTransferExpired, "", "")
+		return err
+	})
+	return result, err
+}
+
+// addPendingTransferEvent records a step of the approval, without an actor when the system took it,
+// and returns the whole trail
+func addPendingTransferEvent(ctx context.Context, q *Queries, pendingTransferID int64, action, actor, note string) ([]PendingTransferEvent, error) {
+	_, err := q.CreatePendingTransferEvent(ctx, CreatePendingTransferEventParams{
+		PendingTransferID: pendingTransferID,
+		Action:            action,
+		Actor:             sql.NullString{String: actor, Valid: actor != ""},
+		Note:              note,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q.ListPendingTransferEvents(ctx, pendingTransferID)
+}
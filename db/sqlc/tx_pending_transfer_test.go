@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStore_ApprovePendingTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	payer := createRandomAccount(t)
+	payee := createRandomAccountInCurrency(t, payer.Currency)
+	approver := createRandomUser(t)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	arg := RequestTransferApprovalTxParams{
+		Transfer: TransferTxParams{
+			FromAccountID: payer.ID,
+			ToAccountID:   payee.ID,
+			Amount:        60,
+			Currency:      payer.Currency,
+			Description:   "equipment",
+			Screening:     &FraudAssessment{Decision: FraudDecisionAllow},
+		},
+		Maker:     payer.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	requested, err := store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferPending, requested.PendingTransfer.Status)
+	require.Len(t, requested.Trail, 1)
+	require.Equal(t, PendingTransferRequested, requested.Trail[0].Action)
+	require.Equal(t, payer.Owner, requested.Trail[0].Actor.String)
+
+	// the amount is reserved while pending
+	account, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), account.HeldBalance)
+
+	_, err = store.RequestTransferApprovalTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.ReviewPendingTransferTx(context.Background(), ReviewPendingTransferTxParams{
+		PendingTransferID: requested.PendingTransfer.ID,
+		Approve:           true,
+		Approver:          payer.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	approved, err := store.ReviewPendingTransferTx(context.Background(), ReviewPendingTransferTxParams{
+		PendingTransferID: requested.PendingTransfer.ID,
+		Approve:           true,
+		Approver:          approver.Username,
+		Note:              "checked the invoice",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferApproved, approved.PendingTransfer.Status)
+	require.NotNil(t, approved.Transfer)
+	require.Equal(t, int64(60), approved.Transfer.Transfer.Amount)
+	require.Equal(t, "equipment", approved.Transfer.Transfer.Description)
+	require.Equal(t, approved.Transfer.Transfer.ID, approved.PendingTransfer.TransferID.Int64)
+	require.Zero(t, approved.Transfer.FromAccount.HeldBalance)
+	require.NotNil(t, approved.Transfer.FraudDecision)
+
+	require.Len(t, approved.Trail, 2)
+	require.Equal(t, PendingTransferApproved, approved.Trail[1].Action)
+	require.Equal(t, approver.Username, approved.Trail[1].Actor.String)
+	require.Equal(t, "checked the invoice", approved.Trail[1].Note)
+
+	hold, err := testQueries.GetHold(context.Background(), requested.PendingTransfer.HoldID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, hold.Status)
+	require.Equal(t, approved.PendingTransfer.TransferID, hold.TransferID)
+
+	byTransfer, err := testQueries.GetPendingTransferByTransfer(context.Background(), approved.PendingTransfer.TransferID)
+	require.NoError(t, err)
+	require.Equal(t, approved.PendingTransfer.ID, byTransfer.ID)
+
+	_, err = store.ReviewPendingTransferTx(context.Background(), ReviewPendingTransferTxParams{
+		PendingTransferID: requested.PendingTransfer.ID,
+		Approver:          approver.Username,
+	})
+	require.ErrorIs(t, err, ErrPendingTransferNotPending)
+}
+
+func TestStore_RejectAndExpirePendingTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	payer := createRandomAccount(t)
+	payee := createRandomAccountInCurrency(t, payer.Currency)
+	approver := createRandomUser(t)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	arg := RequestTransferApprovalTxParams{
+		Transfer:  TransferTxParams{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 40, Currency: payer.Currency},
+		Maker:     payer.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	requested, err := store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.ExpirePendingTransferTx(context.Background(), requested.PendingTransfer.ID)
+	require.ErrorIs(t, err, ErrPendingTransferNotExpired)
+
+	rejected, err := store.ReviewPendingTransferTx(context.Background(), ReviewPendingTransferTxParams{
+		PendingTransferID: requested.PendingTransfer.ID,
+		Approver:          approver.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferRejected, rejected.PendingTransfer.Status)
+	require.Nil(t, rejected.Transfer)
+	require.False(t, rejected.PendingTransfer.TransferID.Valid)
+
+	account, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+	require.Equal(t, int64(100), account.Balance)
+
+	arg.ExpiresAt = time.Now().Add(-time.Minute)
+	requested, err = store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.ReviewPendingTransferTx(context.Background(), ReviewPendingTransferTxParams{
+		PendingTransferID: requested.PendingTransfer.ID,
+		Approve:           true,
+		Approver:          approver.Username,
+	})
+	require.ErrorIs(t, err, ErrPendingTransferExpired)
+
+	expired, err := store.ExpirePendingTransferTx(context.Background(), requested.PendingTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferExpired, expired.PendingTransfer.Status)
+	require.Len(t, expired.Trail, 2)
+	require.False(t, expired.Trail[1].Actor.Valid)
+
+	account, err = testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+}
+
+func TestStore_ApprovalHoldIsLeftToTheApproval(t *testing.T) {
+	store := NewStore(testDB)
+	payer := createRandomAccount(t)
+	payee := createRandomAccountInCurrency(t, payer.Currency)
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: payer.ID, Balance: 100})
+	require.NoError(t, err)
+
+	requested, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		Transfer:  TransferTxParams{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 40, Currency: payer.Currency},
+		Maker:     payer.Owner,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	hold, err := testQueries.GetHold(context.Background(), requested.PendingTransfer.HoldID)
+	require.NoError(t, err)
+	require.Equal(t, HoldPurposeApproval, hold.Purpose)
+
+	// the recipient can't collect the transfer without an approver, nor the maker release its funds
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrApprovalHold)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrApprovalHold)
+
+	// the hold sweeper leaves it to the expiry of the approval
+	_, err = store.ExpireHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrApprovalHold)
+
+	holds, err := testQueries.ListExpiredHolds(context.Background(), 1000)
+	require.NoError(t, err)
+	for _, expired := range holds {
+		require.NotEqual(t, hold.ID, expired.ID)
+	}
+
+	account, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), account.HeldBalance)
+
+	_, err = store.ExpirePendingTransferTx(context.Background(), requested.PendingTransfer.ID)
+	require.NoError(t, err)
+
+	hold, err = testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, hold.Status)
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	ReconciliationStatusClean    = "clean"
+	ReconciliationStatusOpen     = "open"
+	ReconciliationStatusRepaired = "repaired"
+
+	FindingBalanceDrift       = "balance_drift"
+	FindingOrphanEntry        = "orphan_entry"
+	FindingUnbalancedTransfer = "unbalanced_transfer"
+)
+
+var ErrReportNotOpen = errors.New("reconciliation report has no open findings")
+
+type ReconciliationResult struct {
+	Report   ReconciliationReport    `json:"report"`
+	Findings []ReconciliationFinding `json:"findings"`
+}
+
+// ReconcileTx checks the ledger against itself and records what it finds in a new report.
+// It looks for accounts whose balance is not the sum of their entries, entries outside of any transfer,
+// and transfers whose entries don't move exactly the amount plus fees.
+func (store *SQLStore) ReconcileTx(ctx context.Context) (ReconciliationResult, error) {
+	var result ReconciliationResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var findings []CreateReconciliationFindingParams
+
+		drifts, err := queries.ListBalanceDrifts(ctx)
+		if err != nil {
+			return err
+		}
+		for _, drift := range drifts {
+			findings = append(findings, CreateReconciliationFindingParams{
+				Kind:      FindingBalanceDrift,
+				AccountID: sql.NullInt64{Int64: drift.AccountID, Valid: true},
+				Expected:  drift.EntriesTotal,
+				Actual:    drift.Balance,
+			})
+		}
+
+		orphans, err := queries.ListOrphanEntries(ctx)
+		if err != nil {
+			return err
+		}
+		for _, entry := range orphans {
+			findings = append(findings, CreateReconciliationFindingParams{
+				Kind:      FindingOrphanEntry,
+				AccountID: sql.NullInt64{Int64: entry.AccountID, Valid: true},
+				EntryID:   sql.NullInt64{Int64: entry.ID, Valid: true},
+				Actual:    entry.Amount,
+			})
+		}
+
+		transfers, err := queries.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, transfer := range transfers {
+			actual := transfer.Credits
+			if actual == transfer.Expected {
+				actual = transfer.Debits
+			}
+			findings = append(findings, CreateReconciliationFindingParams{
+				Kind:       FindingUnbalancedTransfer,
+				TransferID: sql.NullInt64{Int64: transfer.TransferID, Valid: true},
+				Expected:   transfer.Expected,
+				Actual:     actual,
+			})
+		}
+
+		status := ReconciliationStatusClean
+		if len(findings) > 0 {
+			status = ReconciliationStatusOpen
+		}
+		result.Report, err = queries.CreateReconciliationReport(ctx, CreateReconciliationReportParams{
+			Status:        status,
+			FindingsCount: int64(len(findings)),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Findings = make([]ReconciliationFinding, len(findings))
+		for i, arg := range findings {
+			arg.ReportID = result.Report.ID
+			result.Findings[i], err = queries.CreateReconciliationFinding(ctx, arg)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+type RepairReconciliationTxParams struct {
+	ReportID   int64  `json:"report_id"`
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+// RepairReconciliationTx applies an open report once a reviewer approved it.
+// Drifted balances are reset to the sum of their entries, since entries are the source of truth.
+// Orphan entries and unbalanced transfers need a decision on where the money goes, so they are left
+// for a banker to correct by hand and are never marked repaired.
+func (store *SQLStore) RepairReconciliationTx(ctx context.Context, arg RepairReconciliationTxParams) (ReconciliationResult, error) {
+	var result ReconciliationResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		report, err := queries.GetReconciliationReportForUpdate(ctx, arg.ReportID)
+		if err != nil {
+			return err
+		}
+		if report.Status != ReconciliationStatusOpen {
+			return ErrReportNotOpen
+		}
+
+		findings, err := queries.ListReconciliationFindings(ctx, report.ID)
+		if err != nil {
+			return err
+		}
+
+		for i, finding := range findings {
+			if finding.Kind != FindingBalanceDrift {
+				continue
+			}
+
+			_, err = queries.GetAccountForUpdate(ctx, finding.AccountID.Int64)
+			if err != nil {
+				return err
+			}
+			_, err = queries.ResetAccountBalanceToEntries(ctx, finding.AccountID.Int64)
+			if err != nil {
+				return err
+			}
+
+			findings[i], err = queries.MarkReconciliationFindingRepaired(ctx, finding.ID)
+			if err != nil {
+				return err
+			}
+		}
+		result.Findings = findings
+
+		result.Report, err = queries.ReviewReconciliationReport(ctx, ReviewReconciliationReportParams{
+			ID:         report.ID,
+			Status:     ReconciliationStatusRepaired,
+			ReviewedBy: sql.NullString{String: arg.ReviewedBy, Valid: true},
+		})
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_ReconcileTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// a balance with no entries behind it
+	drifted := createRandomAccount(t)
+	drifted, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: drifted.ID, Balance: 100})
+	require.NoError(t, err)
+
+	// an entry outside of any transfer
+	account := createRandomAccount(t)
+	orphan, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 25})
+	require.NoError(t, err)
+
+	// a transfer record without its entries
+	unbalanced := createRandomTransfer(t, account, drifted)
+
+	result, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ReconciliationStatusOpen, result.Report.Status)
+	require.Equal(t, int64(len(result.Findings)), result.Report.FindingsCount)
+
+	found := map[string]ReconciliationFinding{}
+	for _, finding := range result.Findings {
+		require.Equal(t, result.Report.ID, finding.ReportID)
+		switch {
+		case finding.Kind == FindingBalanceDrift && finding.AccountID.Int64 == drifted.ID:
+			found[finding.Kind] = finding
+		case finding.Kind == FindingOrphanEntry && finding.EntryID.Int64 == orphan.ID:
+			found[finding.Kind] = finding
+		case finding.Kind == FindingUnbalancedTransfer && finding.TransferID.Int64 == unbalanced.ID:
+			found[finding.Kind] = finding
+		}
+	}
+	require.Len(t, found, 3)
+	require.Equal(t, int64(0), found[FindingBalanceDrift].Expected)
+	require.Equal(t, int64(100), found[FindingBalanceDrift].Actual)
+	require.Equal(t, int64(25), found[FindingOrphanEntry].Actual)
+	require.Equal(t, unbalanced.Amount, found[FindingUnbalancedTransfer].Expected)
+	require.Equal(t, int64(0), found[FindingUnbalancedTransfer].Actual)
+
+	reviewer := createRandomUser(t)
+	repaired, err := store.RepairReconciliationTx(context.Background(), RepairReconciliationTxParams{
+		ReportID:   result.Report.ID,
+		ReviewedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReconciliationStatusRepaired, repaired.Report.Status)
+	require.Equal(t, reviewer.Username, repaired.Report.ReviewedBy.String)
+	require.True(t, repaired.Report.ReviewedAt.Valid)
+
+	for _, finding := range repaired.Findings {
+		require.Equal(t, finding.Kind == FindingBalanceDrift, finding.RepairedAt.Valid)
+	}
+
+	// the balance is back to the sum of its entries, the orphan entry is left alone
+	drifted, err = testQueries.GetAccount(context.Background(), drifted.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), drifted.Balance)
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), account.Balance)
+
+	_, err = store.RepairReconciliationTx(context.Background(), RepairReconciliationTxParams{
+		ReportID:   result.Report.ID,
+		ReviewedBy: reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrReportNotOpen)
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrTransferNotReversible   = errors.New("transfer is a reversal and cannot be reversed")
+	ErrReversalExceedsTransfer = errors.New("reversal amount exceeds the amount left to reverse")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount to give back; zero reverses everything not yet reversed
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	Reversal         TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx moves money back from the recipient to the sender of an existing transfer.
+// The reversal is a new transfer pointing at the original one, and the original row is locked
+// so the sum of all its reversals can never exceed its amount.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+
+		result.OriginalTransfer, err = queries.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		original := result.OriginalTransfer
+		if original.OriginalTransferID.Valid {
+			return ErrTransferNotReversible
+		}
+
+		originalID := sql.NullInt64{Int64: original.ID, Valid: true}
+		reversed, err := queries.GetReversedAmount(ctx, originalID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - reversed
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		result.Reversal, err = transfer(ctx, queries, CreateTransferParams{
+			FromAccountID:      original.ToAccountID,
+			ToAccountID:        original.FromAccountID,
+			Amount:             amount,
+			OriginalTransferID: originalID,
+		}, Fee{})
+		if err != nil {
+			return err
+		}
+		// the recipient must still have the money to give back
+		return checkFunds(result.Reversal.FromAccount)
+	})
+	return result, err
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_ReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+	amount := int64(30)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	// partial reversal
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.ID, partial.OriginalTransfer.ID)
+
+	reversal := partial.Reversal.Transfer
+	require.Equal(t, toAccount.ID, reversal.FromAccountID)
+	require.Equal(t, fromAccount.ID, reversal.ToAccountID)
+	require.Equal(t, int64(10), reversal.Amount)
+	require.True(t, reversal.OriginalTransferID.Valid)
+	require.Equal(t, original.Transfer.ID, reversal.OriginalTransferID.Int64)
+
+	require.Equal(t, int64(-10), partial.Reversal.FromEntry.Amount)
+	require.Equal(t, reversal.ID, partial.Reversal.FromEntry.TransferID.Int64)
+	require.Equal(t, int64(10), partial.Reversal.ToEntry.Amount)
+	require.Equal(t, reversal.ID, partial.Reversal.ToEntry.TransferID.Int64)
+
+	// reversing more than what is left fails
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     amount,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// zero amount reverses the remainder
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount-10, rest.Reversal.Transfer.Amount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// a reversal can't be reversed itself
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+}
+
+func TestStore_ReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+	amount := int64(10)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+
+	// every goroutine tries to reverse the full amount, only one may succeed
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: original.Transfer.ID,
+				Amount:     amount,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+	}
+	require.Equal(t, 1, succeeded)
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// houseAccounts lists the owner and type of the house accounts every tenant has in each of its currencies,
+// along with the house user owning them in the tenant
+var houseAccounts = []struct {
+	Owner    string
+	FullName string
+	Email    string
+	Type     sql.NullString
+}{
+	{Owner: FeeRevenueOwner, FullName: "Fee revenue", Email: "fees@house.internal"},
+	{Owner: InterestExpenseOwner, FullName: "Interest expense", Email: "interest@house.internal"},
+	{Owner: EscrowOwner, FullName: "Escrow", Email: "escrow@house.internal",
+		Type: sql.NullString{String: AccountTypeEscrow, Valid: true}},
+}
+
+// TenantLimit is a currency limit, it applies to every account of the tenant in the currency
+type TenantLimit struct {
+	Currency  string        `json:"currency"`
+	Period    string        `json:"period"`
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	MaxCount  sql.NullInt64 `json:"max_count"`
+}
+
+type CreateTenantTxParams struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// Host is optional, requests to it are scoped to the tenant
+	Host       string        `json:"host"`
+	Currencies []string      `json:"currencies"`
+	Limits     []TenantLimit `json:"limits"`
+}
+
+type TenantTxResult struct {
+	Tenant Tenant `json:"tenant"`
+	// HouseAccounts are the house accounts opened for the currencies of the tenant
+	HouseAccounts []Account       `json:"house_accounts"`
+	Limits        []TransferLimit `json:"limits"`
+}
+
+// CreateTenantTx registers a brand with its own house accounts and currency limits.
+// Its users, accounts and settings are only seen by transactions scoped to it.
+func (store *SQLStore) CreateTenantTx(ctx context.Context, arg CreateTenantTxParams) (TenantTxResult, error) {
+	var result TenantTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		result.Tenant, err = queries.CreateTenant(ctx, CreateTenantParams{
+			Slug:       arg.Slug,
+			Name:       arg.Name,
+			Host:       sql.NullString{String: arg.Host, Valid: arg.Host != ""},
+			Currencies: arg.Currencies,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the rest of the rows belong to the new tenant
+		err = setTenant(ctx, queries.db, result.Tenant.ID)
+		if err != nil {
+			return err
+		}
+
+		result.HouseAccounts, err = openHouseAccounts(ctx, queries, arg.Currencies)
+		if err != nil {
+			return err
+		}
+
+		for _, limit := range arg.Limits {
+			transferLimit, err := queries.UpsertTransferLimit(ctx, UpsertTransferLimitParams{
+				Scope:     LimitScopeCurrency,
+				Currency:  limit.Currency,
+				Period:    limit.Period,
+				MaxAmount: limit.MaxAmount,
+				MaxCount:  limit.MaxCount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Limits = append(result.Limits, transferLimit)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// UpdateTenantTx changes the name, host or currencies of a tenant, opening the house accounts of new currencies.
+// Accounts already open in a currency that is dropped are kept.
+func (store *SQLStore) UpdateTenantTx(ctx context.Context, arg UpdateTenantParams) (TenantTxResult, error) {
+	var result TenantTxResult
+
+	err := store.execTx(ctx, func(queries *Queries) error {
+		var err error
+		result.Tenant, err = queries.UpdateTenant(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = setTenant(ctx, queries.db, result.Tenant.ID)
+		if err != nil {
+			return err
+		}
+
+		result.HouseAccounts, err = openHouseAccounts(ctx, queries, result.Tenant.Currencies)
+		return err
+	})
+	return result, err
+}
+
+// openHouseAccounts opens the house accounts missing in the currencies for the tenant of the transaction,
+// and the house users owning them when the tenant doesn't have them yet
+func openHouseAccounts(ctx context.Context, q *Queries, currencies []string) ([]Account, error) {
+	for _, house := range houseAccounts {
+		err := q.CreateHouseUser(ctx, CreateHouseUserParams{
+			Username: house.Owner,
+			FullName: house.FullName,
+			Email:    house.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var opened []Account
+	for _, currency := range currencies {
+		for _, house := range houseAccounts {
+			_, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+				Owner:    house.Owner,
+				Currency: currency,
+			})
+			if err == nil {
+				continue
+			}
+			if err != sql.ErrNoRows {
+				return nil, err
+			}
+
+			account, err := q.CreateAccount(ctx, CreateAccountParams{
+				Owner:    house.Owner,
+				Currency: currency,
+				Type:     house.Type,
+			})
+			if err != nil {
+				return nil, err
+			}
+			opened = append(opened, account)
+		}
+	}
+	return opened, nil
+}
@@ -5,12 +5,31 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const createHouseUser = `-- name: CreateHouseUser :exec
+INSERT INTO users (username, hashed_password, full_name, email)
+VALUES ($1, '', $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type CreateHouseUserParams struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// house users own the house accounts, nobody can log in with an empty password hash
+func (q *Queries) CreateHouseUser(ctx context.Context, arg CreateHouseUserParams) error {
+	_, err := q.db.ExecContext(ctx, createHouseUser, arg.Username, arg.FullName, arg.Email)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, hashed_password, full_name, email)
 VALUES ($1, $2, $3, $4)
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id
 `
 
 type CreateUserParams struct {
@@ -35,12 +54,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -54,6 +77,209 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getUserByAlias = `-- name: GetUserByAlias :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id FROM users
+WHERE lower(alias) = lower($1) LIMIT 1
+`
+
+func (q *Queries) GetUserByAlias(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByAlias, lower)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getUserByVerifiedEmail = `-- name: GetUserByVerifiedEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id FROM users
+WHERE lower(email) = lower($1)
+  AND email_verified_at IS NOT NULL
+LIMIT 1
+`
+
+func (q *Queries) GetUserByVerifiedEmail(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByVerifiedEmail, lower)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id FROM users
+ORDER BY created_at, username
+LIMIT $1
+OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.Alias,
+			&i.EmailVerifiedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersAfter = `-- name: ListUsersAfter :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id FROM users
+WHERE $1::timestamptz IS NULL
+   OR (created_at, username) > ($1, $2::varchar)
+ORDER BY created_at, username
+LIMIT $3
+`
+
+type ListUsersAfterParams struct {
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorUsername  sql.NullString `json:"cursor_username"`
+	Limit           int32          `json:"limit"`
+}
+
+// keyset page ordered by (created_at, username), starting from the beginning without a cursor
+func (q *Queries) ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersAfter, arg.CursorCreatedAt, arg.CursorUsername, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.Alias,
+			&i.EmailVerifiedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserAlias = `-- name: SetUserAlias :one
+UPDATE users
+SET alias = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id
+`
+
+type SetUserAliasParams struct {
+	Alias    sql.NullString `json:"alias"`
+	Username string         `json:"username"`
+}
+
+// a null alias removes it
+func (q *Queries) SetUserAlias(ctx context.Context, arg SetUserAliasParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserAlias, arg.Alias, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now())
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, alias, email_verified_at, tenant_id
+`
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Alias,
+		&i.EmailVerifiedAt,
+		&i.TenantID,
 	)
 	return i, err
 }
@@ -3,7 +3,9 @@ package db
 import (
 	"code-with-go/util"
 	"context"
+	"database/sql"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -22,6 +24,74 @@ func TestQueries_GetUser(t *testing.T) {
 	require.Equal(t, retrievedUser.Email, user.Email)
 }
 
+func TestQueries_ListUsersAfter(t *testing.T) {
+	first := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomUser(t)
+	}
+
+	users, err := testQueries.ListUsersAfter(context.Background(), ListUsersAfterParams{
+		CursorCreatedAt: sql.NullTime{Time: first.CreatedAt, Valid: true},
+		CursorUsername:  sql.NullString{String: first.Username, Valid: true},
+		Limit:           3,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+
+	previous := first
+	for _, user := range users {
+		require.True(t, user.CreatedAt.After(previous.CreatedAt) ||
+			user.CreatedAt.Equal(previous.CreatedAt) && user.Username > previous.Username)
+		previous = user
+	}
+}
+
+func TestQueries_SetUserAlias(t *testing.T) {
+	user := createRandomUser(t)
+	alias := util.RandomString(12)
+
+	updatedUser, err := testQueries.SetUserAlias(context.Background(), SetUserAliasParams{
+		Alias:    sql.NullString{String: alias, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, alias, updatedUser.Alias.String)
+
+	// aliases are looked up and kept unique regardless of case
+	found, err := testQueries.GetUserByAlias(context.Background(), strings.ToUpper(alias))
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	_, err = testQueries.SetUserAlias(context.Background(), SetUserAliasParams{
+		Alias:    sql.NullString{String: strings.ToUpper(alias), Valid: true},
+		Username: createRandomUser(t).Username,
+	})
+	require.Error(t, err)
+
+	updatedUser, err = testQueries.SetUserAlias(context.Background(), SetUserAliasParams{Username: user.Username})
+	require.NoError(t, err)
+	require.False(t, updatedUser.Alias.Valid)
+
+	_, err = testQueries.GetUserByAlias(context.Background(), alias)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_GetUserByVerifiedEmail(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.EmailVerifiedAt.Valid)
+
+	_, err := testQueries.GetUserByVerifiedEmail(context.Background(), user.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	verifiedUser, err := testQueries.VerifyUserEmail(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, verifiedUser.EmailVerifiedAt.Valid)
+
+	found, err := testQueries.GetUserByVerifiedEmail(context.Background(), strings.ToUpper(user.Email))
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+}
+
 func createRandomUser(t *testing.T) User {
 	password, err := util.HashPassword(util.RandomString(6))
 	require.NoError(t, err)
@@ -0,0 +1,115 @@
+// Command storegen writes store_querier.go, which runs every query of the Querier interface
+// in a transaction of the SQLStore, so each of them is scoped to the tenant of its context.
+// It runs from the db/sqlc directory with go generate, after sqlc generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	source = "querier.go"
+	output = "store_querier.go"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, 0)
+	if err != nil {
+		log.Fatal("cannot parse querier: ", err)
+	}
+
+	querier := findInterface(file, "Querier")
+	if querier == nil {
+		log.Fatalf("cannot find the Querier interface in %s", source)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by storegen. DO NOT EDIT.\n\npackage db\n\n")
+	writeImports(&buf, file)
+	for _, method := range querier.Methods.List {
+		if err := writeMethod(&buf, fset, method); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal("cannot format the store: ", err)
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		log.Fatal("cannot write the store: ", err)
+	}
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok && typeSpec.Name.Name == name {
+				return iface
+			}
+		}
+	}
+	return nil
+}
+
+// writeImports copies the imports of the querier, the wrappers use the same types
+func writeImports(buf *bytes.Buffer, file *ast.File) {
+	buf.WriteString("import (\n")
+	for _, imp := range file.Imports {
+		fmt.Fprintf(buf, "\t%s\n", imp.Path.Value)
+	}
+	buf.WriteString(")\n")
+}
+
+func writeMethod(buf *bytes.Buffer, fset *token.FileSet, method *ast.Field) error {
+	if len(method.Names) != 1 {
+		return fmt.Errorf("unexpected embedded interface in the querier")
+	}
+	name := method.Names[0].Name
+	fn := method.Type.(*ast.FuncType)
+
+	var params, args []string
+	for _, param := range fn.Params.List {
+		for _, paramName := range param.Names {
+			params = append(params, paramName.Name+" "+expr(fset, param.Type))
+			args = append(args, paramName.Name)
+		}
+	}
+	call := fmt.Sprintf("queries.%s(%s)", name, strings.Join(args, ", "))
+
+	fmt.Fprintf(buf, "\nfunc (store *SQLStore) %s(%s) ", name, strings.Join(params, ", "))
+	switch len(fn.Results.List) {
+	case 1:
+		// the query only returns an error
+		fmt.Fprintf(buf, "error {\n\treturn store.execTx(ctx, func(queries *Queries) error {\n\t\treturn %s\n\t})\n}\n", call)
+	case 2:
+		result := expr(fset, fn.Results.List[0].Type)
+		fmt.Fprintf(buf, "(%s, error) {\n\tvar result %s\n\terr := store.execTx(ctx, func(queries *Queries) error {\n"+
+			"\t\tvar err error\n\t\tresult, err = %s\n\t\treturn err\n\t})\n\treturn result, err\n}\n", result, result, call)
+	default:
+		return fmt.Errorf("unexpected results of %s", name)
+	}
+	return nil
+}
+
+func expr(fset *token.FileSet, node ast.Expr) string {
+	var buf bytes.Buffer
+	// printing a type expression parsed from a file can't fail
+	_ = printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
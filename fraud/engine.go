@@ -0,0 +1,141 @@
+package fraud
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"sort"
+	"time"
+)
+
+// Engine screens transfers against the rules of the rules file and of the database
+type Engine struct {
+	store db.Store
+	rules []Rule
+	now   func() time.Time
+}
+
+func NewEngine(store db.Store, rules []Rule, now func() time.Time) *Engine {
+	return &Engine{store: store, rules: rules, now: now}
+}
+
+// Rules merges the database rules over the file ones, a database rule replaces the file rule of the same name
+// and a disabled one turns it off. They come sorted by name.
+func (engine *Engine) Rules(ctx context.Context) ([]Rule, error) {
+	stored, err := engine.store.ListFraudRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Rule, len(engine.rules)+len(stored))
+	for _, rule := range engine.rules {
+		byName[rule.Name] = rule
+	}
+	for _, rule := range stored {
+		if !rule.Enabled {
+			delete(byName, rule.Name)
+			continue
+		}
+		byName[rule.Name] = RuleFromDB(rule)
+	}
+
+	rules := make([]Rule, 0, len(byName))
+	for _, rule := range byName {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+// Screen assesses a transfer from the account. Any matching deny rule denies it,
+// otherwise any matching review rule sends it to review, and the score adds up every match.
+func (engine *Engine) Screen(ctx context.Context, from db.Account, arg db.TransferTxParams) (db.FraudAssessment, error) {
+	assessment := db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}}
+
+	rules, err := engine.Rules(ctx)
+	if err != nil {
+		return assessment, err
+	}
+
+	transfer := &screenedTransfer{store: engine.store, from: from, arg: arg, now: engine.now()}
+	for _, rule := range rules {
+		if rule.Currency != "" && rule.Currency != arg.Currency {
+			continue
+		}
+
+		matched, err := transfer.matches(ctx, rule)
+		if err != nil {
+			return assessment, err
+		}
+		if !matched {
+			continue
+		}
+
+		assessment.Score += rule.Score
+		assessment.MatchedRules = append(assessment.MatchedRules, rule.Name)
+		if rule.Action == db.FraudDecisionDeny {
+			assessment.Decision = db.FraudDecisionDeny
+		} else if assessment.Decision == db.FraudDecisionAllow {
+			assessment.Decision = db.FraudDecisionReview
+		}
+	}
+	return assessment, nil
+}
+
+// screenedTransfer looks up the history a rule needs, only when a rule needs it
+type screenedTransfer struct {
+	store db.Store
+	from  db.Account
+	arg   db.TransferTxParams
+	now   time.Time
+}
+
+func (t *screenedTransfer) matches(ctx context.Context, rule Rule) (bool, error) {
+	switch rule.Kind {
+	case KindLargeAmount:
+		return t.arg.Amount >= rule.MinAmount, nil
+
+	case KindNewPayeeLargeAmount:
+		if t.arg.Amount < rule.MinAmount {
+			return false, nil
+		}
+		count, err := t.store.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+			FromAccountID: t.arg.FromAccountID,
+			ToAccountID:   t.arg.ToAccountID,
+		})
+		return count == 0, err
+
+	case KindRapidSuccession:
+		count, err := t.store.CountTransfersSince(ctx, db.CountTransfersSinceParams{
+			FromAccountID: t.arg.FromAccountID,
+			CreatedAt:     t.now.Add(-rule.Window),
+		})
+		// this transfer is one of them
+		return count+1 >= rule.MinCount, err
+
+	case KindRoundAmountBurst:
+		if t.arg.Amount%rule.RoundTo != 0 {
+			return false, nil
+		}
+		count, err := t.store.CountRoundTransfersSince(ctx, db.CountRoundTransfersSinceParams{
+			FromAccountID: t.arg.FromAccountID,
+			Since:         t.now.Add(-rule.Window),
+			RoundTo:       rule.RoundTo,
+		})
+		return count+1 >= rule.MinCount, err
+
+	case KindFirstAfterPasswordChange:
+		owner, err := t.store.GetUser(ctx, t.from.Owner)
+		if err != nil {
+			return false, err
+		}
+		if t.now.Sub(owner.PasswordChangedAt) > rule.Window {
+			return false, nil
+		}
+		count, err := t.store.CountTransfersSince(ctx, db.CountTransfersSinceParams{
+			FromAccountID: t.arg.FromAccountID,
+			CreatedAt:     owner.PasswordChangedAt,
+		})
+		return count == 0, err
+	}
+	return false, nil
+}
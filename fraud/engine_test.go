@@ -0,0 +1,157 @@
+package fraud
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestEngine_Screen(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	from := db.Account{ID: 1, Owner: util.RandomOwner(), Currency: util.USD}
+
+	transferOf := func(amount int64) db.TransferTxParams {
+		return db.TransferTxParams{FromAccountID: from.ID, ToAccountID: 2, Amount: amount, Currency: util.USD}
+	}
+
+	testCases := []struct {
+		name       string
+		rules      []Rule
+		arg        db.TransferTxParams
+		buildStubs func(store *mockdb.MockStore)
+		expected   db.FraudAssessment
+	}{
+		{
+			name:  "NoMatch",
+			rules: []Rule{{Name: "large", Kind: KindLargeAmount, Action: "deny", Score: 90, MinAmount: 1000}},
+			arg:   transferOf(999),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}},
+		},
+		{
+			name: "DenyWinsOverReview",
+			rules: []Rule{
+				{Name: "large", Kind: KindLargeAmount, Action: "deny", Score: 90, MinAmount: 1000},
+				{Name: "new_payee", Kind: KindNewPayeeLargeAmount, Action: "review", Score: 20, MinAmount: 500},
+			},
+			arg: transferOf(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				arg := db.CountTransfersBetweenParams{FromAccountID: from.ID, ToAccountID: 2}
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionDeny, Score: 110, MatchedRules: []string{"large", "new_payee"}},
+		},
+		{
+			name:  "KnownPayee",
+			rules: []Rule{{Name: "new_payee", Kind: KindNewPayeeLargeAmount, Action: "review", Score: 20, MinAmount: 500}},
+			arg:   transferOf(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}},
+		},
+		{
+			name:  "RapidSuccession",
+			rules: []Rule{{Name: "bursts", Kind: KindRapidSuccession, Action: "review", Score: 30, MinCount: 3, Window: 10 * time.Minute}},
+			arg:   transferOf(10),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				arg := db.CountTransfersSinceParams{FromAccountID: from.ID, CreatedAt: now.Add(-10 * time.Minute)}
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(2), nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 30, MatchedRules: []string{"bursts"}},
+		},
+		{
+			name:  "RoundAmountBurst",
+			rules: []Rule{{Name: "round", Kind: KindRoundAmountBurst, Action: "review", Score: 20, RoundTo: 100, MinCount: 2, Window: time.Hour}},
+			arg:   transferOf(500),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				arg := db.CountRoundTransfersSinceParams{FromAccountID: from.ID, Since: now.Add(-time.Hour), RoundTo: 100}
+				store.EXPECT().CountRoundTransfersSince(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(1), nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 20, MatchedRules: []string{"round"}},
+		},
+		{
+			name:  "NotRound",
+			rules: []Rule{{Name: "round", Kind: KindRoundAmountBurst, Action: "review", Score: 20, RoundTo: 100, MinCount: 2, Window: time.Hour}},
+			arg:   transferOf(501),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				store.EXPECT().CountRoundTransfersSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}},
+		},
+		{
+			name:  "FirstAfterPasswordChange",
+			rules: []Rule{{Name: "password", Kind: KindFirstAfterPasswordChange, Action: "review", Score: 30, Window: 24 * time.Hour}},
+			arg:   transferOf(10),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+				changedAt := now.Add(-time.Hour)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(from.Owner)).Times(1).
+					Return(db.User{Username: from.Owner, PasswordChangedAt: changedAt}, nil)
+				arg := db.CountTransfersSinceParams{FromAccountID: from.ID, CreatedAt: changedAt}
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 30, MatchedRules: []string{"password"}},
+		},
+		{
+			name:  "OtherCurrency",
+			rules: []Rule{{Name: "large", Kind: KindLargeAmount, Action: "deny", Currency: util.EUR, MinAmount: 1}},
+			arg:   transferOf(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}},
+		},
+		{
+			name:  "DatabaseRuleOverridesFile",
+			rules: []Rule{{Name: "large", Kind: KindLargeAmount, Action: "deny", Score: 90, MinAmount: 1000}},
+			arg:   transferOf(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				rules := []db.FraudRule{
+					{Name: "large", Kind: KindLargeAmount, Action: "review", Score: 10, MinAmount: 1000, Enabled: true},
+				}
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return(rules, nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionReview, Score: 10, MatchedRules: []string{"large"}},
+		},
+		{
+			name:  "DatabaseRuleDisablesFile",
+			rules: []Rule{{Name: "large", Kind: KindLargeAmount, Action: "deny", Score: 90, MinAmount: 1000}},
+			arg:   transferOf(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				rules := []db.FraudRule{{Name: "large", Kind: KindLargeAmount, Action: "deny", Enabled: false}}
+				store.EXPECT().ListFraudRules(gomock.Any()).Times(1).Return(rules, nil)
+			},
+			expected: db.FraudAssessment{Decision: db.FraudDecisionAllow, MatchedRules: []string{}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			engine := NewEngine(store, tc.rules, func() time.Time { return now })
+			assessment, err := engine.Screen(context.Background(), from, tc.arg)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, assessment)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package fraud
+
+import (
+	db "code-with-go/db/sqlc"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// the patterns a rule can look for
+const (
+	// KindLargeAmount matches amounts of at least MinAmount
+	KindLargeAmount = "large_amount"
+	// KindNewPayeeLargeAmount matches amounts of at least MinAmount sent to an account the sender never paid before
+	KindNewPayeeLargeAmount = "new_payee_large_amount"
+	// KindRapidSuccession matches the MinCount-th transfer from the account within Window
+	KindRapidSuccession = "rapid_succession"
+	// KindRoundAmountBurst matches the MinCount-th multiple of RoundTo sent from the account within Window
+	KindRoundAmountBurst = "round_amount_burst"
+	// KindFirstAfterPasswordChange matches the first transfer of an owner who changed their password within Window
+	KindFirstAfterPasswordChange = "first_after_password_change"
+)
+
+// Rule flags the transfers showing a pattern. A match adds its score and asks for its action, review or deny.
+type Rule struct {
+	Name   string `mapstructure:"name"`
+	Kind   string `mapstructure:"kind"`
+	Action string `mapstructure:"action"`
+	Score  int64  `mapstructure:"score"`
+	// Currency restricts the rule to transfers in that currency when set
+	Currency  string        `mapstructure:"currency"`
+	MinAmount int64         `mapstructure:"min_amount"`
+	MinCount  int64         `mapstructure:"min_count"`
+	Window    time.Duration `mapstructure:"window"`
+	RoundTo   int64         `mapstructure:"round_to"`
+}
+
+// Validate checks the rule has what its kind needs
+func (rule Rule) Validate() error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if rule.Action != db.FraudDecisionReview && rule.Action != db.FraudDecisionDeny {
+		return fmt.Errorf("action must be %s or %s", db.FraudDecisionReview, db.FraudDecisionDeny)
+	}
+	if rule.Score < 0 || rule.MinAmount < 0 || rule.MinCount < 0 || rule.Window < 0 || rule.RoundTo < 0 {
+		return errors.New("score, amounts, counts and windows can't be negative")
+	}
+
+	switch rule.Kind {
+	case KindLargeAmount, KindNewPayeeLargeAmount:
+		return nil
+	case KindRapidSuccession:
+		if rule.MinCount == 0 || rule.Window == 0 {
+			return errors.New("min_count and window are required")
+		}
+	case KindRoundAmountBurst:
+		if rule.RoundTo == 0 || rule.MinCount == 0 || rule.Window == 0 {
+			return errors.New("round_to, min_count and window are required")
+		}
+	case KindFirstAfterPasswordChange:
+		if rule.Window == 0 {
+			return errors.New("window is required")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", rule.Kind)
+	}
+	return nil
+}
+
+// RuleFromDB converts a rule stored in the database
+func RuleFromDB(rule db.FraudRule) Rule {
+	return Rule{
+		Name:      rule.Name,
+		Kind:      rule.Kind,
+		Action:    rule.Action,
+		Score:     rule.Score,
+		Currency:  rule.Currency,
+		MinAmount: rule.MinAmount,
+		MinCount:  rule.MinCount,
+		Window:    time.Duration(rule.WindowSeconds) * time.Second,
+		RoundTo:   rule.RoundTo,
+	}
+}
+
+// LoadRules reads the rules listed under the rules key of a YAML file
+func LoadRules(path string) ([]Rule, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules []Rule `mapstructure:"rules"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(file.Rules))
+	for _, rule := range file.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("rule %q is defined twice", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return file.Rules, nil
+}
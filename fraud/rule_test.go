@@ -0,0 +1,79 @@
+package fraud
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	path := writeRules(t, `
+rules:
+  - name: large
+    kind: large_amount
+    action: deny
+    score: 90
+    currency: USD
+    min_amount: 100000
+  - name: bursts
+    kind: rapid_succession
+    action: review
+    min_count: 5
+    window: 10m
+`)
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Equal(t, []Rule{
+		{Name: "large", Kind: KindLargeAmount, Action: "deny", Score: 90, Currency: "USD", MinAmount: 100000},
+		{Name: "bursts", Kind: KindRapidSuccession, Action: "review", MinCount: 5, Window: 10 * time.Minute},
+	}, rules)
+}
+
+func TestLoadRulesShippedFile(t *testing.T) {
+	rules, err := LoadRules("rules.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, rules)
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "UnknownKind",
+			content: "rules:\n  - name: odd\n    kind: odd_hours\n    action: review\n",
+		},
+		{
+			name:    "InvalidAction",
+			content: "rules:\n  - name: large\n    kind: large_amount\n    action: block\n",
+		},
+		{
+			name:    "MissingWindow",
+			content: "rules:\n  - name: bursts\n    kind: rapid_succession\n    action: review\n    min_count: 3\n",
+		},
+		{
+			name:    "Duplicate",
+			content: "rules:\n  - name: large\n    kind: large_amount\n    action: review\n  - name: large\n    kind: large_amount\n    action: deny\n",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadRules(writeRules(t, tc.content))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"crypto/ed25519"
+	"time"
+)
+
+// ChainRootMessage is what gets signed for a day: the date followed by the root hash
+func ChainRootMessage(day time.Time, rootHash []byte) []byte {
+	return append([]byte(day.Format("2006-01-02")), rootHash...)
+}
+
+// SignChainRoot returns a task signing the root of the entry hash chains as they stood at the end of the previous UTC day.
+// The signed root can be published off-site, so any later rewrite of the entries can be proven.
+// A day is only signed once, later runs leave it alone.
+func SignChainRoot(store db.Store, key ed25519.PrivateKey, now func() time.Time) Task {
+	return func(ctx context.Context) error {
+		midnight := now().UTC().Truncate(24 * time.Hour)
+		day := midnight.AddDate(0, 0, -1)
+
+		heads, err := store.ListLastEntryHashes(ctx, midnight)
+		if err != nil {
+			return err
+		}
+
+		rootHash := db.ChainRootHash(heads)
+		_, err = store.CreateChainRoot(ctx, db.CreateChainRootParams{
+			Day:       day,
+			RootHash:  rootHash,
+			Signature: ed25519.Sign(key, ChainRootMessage(day, rootHash)),
+		})
+		return err
+	}
+}
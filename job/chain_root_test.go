@@ -0,0 +1,76 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestJob_SignChainRoot(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	now := func() time.Time {
+		return time.Date(2022, 3, 14, 0, 30, 0, 0, time.UTC)
+	}
+	midnight := time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)
+	day := time.Date(2022, 3, 13, 0, 0, 0, 0, time.UTC)
+	heads := []db.ListLastEntryHashesRow{
+		{AccountID: 1, Hash: []byte("head of 1")},
+		{AccountID: 2, Hash: []byte("head of 2")},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListLastEntryHashes(gomock.Any(), gomock.Eq(midnight)).Times(1).Return(heads, nil)
+				store.EXPECT().CreateChainRoot(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateChainRootParams) (int64, error) {
+						require.Equal(t, day, arg.Day)
+						require.Equal(t, db.ChainRootHash(heads), arg.RootHash)
+						require.True(t, ed25519.Verify(publicKey, ChainRootMessage(day, arg.RootHash), arg.Signature))
+						return 1, nil
+					})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListLastEntryHashes(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().CreateChainRoot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := SignChainRoot(store, privateKey, now)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
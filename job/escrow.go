@@ -0,0 +1,27 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"errors"
+)
+
+// RefundOverdueEscrows returns a task refunding the buyers of escrow agreements neither released nor
+// disputed by their deadline. Each agreement is refunded in its own transaction.
+func RefundOverdueEscrows(store db.Store, batchSize int32) Task {
+	return func(ctx context.Context) error {
+		agreements, err := store.ListOverdueEscrowAgreements(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, agreement := range agreements {
+			_, err := store.ExpireEscrowTx(ctx, agreement.ID)
+			// the agreement may have been settled or disputed since it was listed
+			if err != nil && !errors.Is(err, db.ErrEscrowSettled) && !errors.Is(err, db.ErrEscrowDisputed) {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJob_RefundOverdueEscrows(t *testing.T) {
+	agreements := []db.EscrowAgreement{
+		{ID: util.RandomInt(1, 1000), Status: db.EscrowFunded},
+		{ID: util.RandomInt(1001, 2000), Status: db.EscrowFunded},
+		{ID: util.RandomInt(2001, 3000), Status: db.EscrowFunded},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdueEscrowAgreements(gomock.Any(), gomock.Eq(int32(10))).Times(1).Return(agreements, nil)
+				for _, agreement := range agreements {
+					store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreement.ID)).Times(1)
+				}
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "SettledOrDisputedMeanwhile",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdueEscrowAgreements(gomock.Any(), gomock.Any()).Times(1).Return(agreements, nil)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[0].ID)).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowSettled)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[1].ID)).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowDisputed)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[2].ID)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdueEscrowAgreements(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "RefundError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdueEscrowAgreements(gomock.Any(), gomock.Any()).Times(1).Return(agreements, nil)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[0].ID)).Times(1).Return(db.EscrowTxResult{}, sql.ErrTxDone)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[1].ID)).Times(0)
+				store.EXPECT().ExpireEscrowTx(gomock.Any(), gomock.Eq(agreements[2].ID)).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := RefundOverdueEscrows(store, 10)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
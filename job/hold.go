@@ -0,0 +1,26 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"errors"
+)
+
+// ReleaseExpiredHolds returns a task giving back the funds of pending holds past their expiration.
+// Each hold is released in its own transaction so a single failure does not block the others.
+func ReleaseExpiredHolds(store db.Store, batchSize int32) Task {
+	return func(ctx context.Context) error {
+		holds, err := store.ListExpiredHolds(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			_, err := store.ExpireHoldTx(ctx, hold.ID)
+			if err != nil && !errors.Is(err, db.ErrHoldNotPending) {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJob_ReleaseExpiredHolds(t *testing.T) {
+	holds := []db.Hold{
+		{ID: util.RandomInt(1, 1000), Status: db.HoldStatusPending},
+		{ID: util.RandomInt(1001, 2000), Status: db.HoldStatusPending},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredHolds(gomock.Any(), gomock.Eq(int32(10))).Times(1).Return(holds, nil)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[0].ID)).Times(1)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[1].ID)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AlreadyReleased",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return(holds, nil)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[0].ID)).Times(1).Return(db.HoldTxResult{}, db.ErrHoldNotPending)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[1].ID)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "ExpireError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredHolds(gomock.Any(), gomock.Any()).Times(1).Return(holds, nil)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[0].ID)).Times(1).Return(db.HoldTxResult{}, sql.ErrTxDone)
+				store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(holds[1].ID)).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := ReleaseExpiredHolds(store, 10)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
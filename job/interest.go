@@ -0,0 +1,64 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"time"
+)
+
+// AccrueInterest returns a task accruing interest on every account earning some, day by day
+// from the day after its last accrual up to the last UTC day that is over.
+// Days missed while the task didn't run are caught up in order, so the carry rolls through each of them,
+// and an account failing halfway is picked up from where it stopped on the next run.
+func AccrueInterest(store db.Store, batchSize int32, now func() time.Time) Task {
+	return func(ctx context.Context) error {
+		lastDay := now().UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour)
+		accounts, err := store.ListAccountsToAccrue(ctx, db.ListAccountsToAccrueParams{
+			AccrualDate: lastDay,
+			Limit:       batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			for day := account.NextAccrualDate.UTC(); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+				_, err := store.AccrueInterestTx(ctx, db.AccrueInterestTxParams{
+					AccountID:   account.AccountID,
+					AccrualDate: day,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// PostInterest returns a task crediting the interest accrued over every month before the current one
+// that wasn't posted yet. Each account and month is posted in its own transaction.
+func PostInterest(store db.Store, batchSize int32, now func() time.Time) Task {
+	return func(ctx context.Context) error {
+		today := now().UTC()
+		monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		periods, err := store.ListUnpostedInterestPeriods(ctx, db.ListUnpostedInterestPeriodsParams{
+			Before: monthStart,
+			Limit:  batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, period := range periods {
+			_, err := store.PostInterestTx(ctx, db.PostInterestTxParams{
+				AccountID: period.AccountID,
+				Period:    period.Period,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
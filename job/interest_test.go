@@ -0,0 +1,155 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestJob_AccrueInterest(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2022, 3, 14, 15, 9, 26, 0, time.FixedZone("UTC-5", -5*60*60))
+	}
+	// the last day that is over in UTC
+	day := time.Date(2022, 3, 13, 0, 0, 0, 0, time.UTC)
+	accounts := []db.ListAccountsToAccrueRow{
+		{AccountID: 3, NextAccrualDate: day},
+		// the task didn't run for two days
+		{AccountID: 7, NextAccrualDate: day.AddDate(0, 0, -2)},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsToAccrueParams{
+					AccrualDate: day,
+					Limit:       10,
+				}
+				store.EXPECT().ListAccountsToAccrue(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+				gomock.InOrder(
+					store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 3, AccrualDate: day})).Times(1),
+					store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 7, AccrualDate: day.AddDate(0, 0, -2)})).Times(1),
+					store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 7, AccrualDate: day.AddDate(0, 0, -1)})).Times(1),
+					store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 7, AccrualDate: day})).Times(1),
+				)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsToAccrue(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "AccrueError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsToAccrue(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Any()).Times(1).Return(db.InterestAccrual{}, sql.ErrTxDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := AccrueInterest(store, 10, now)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestJob_PostInterest(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2022, 3, 1, 2, 0, 0, 0, time.UTC)
+	}
+	periods := []db.ListUnpostedInterestPeriodsRow{
+		{AccountID: 3, Period: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{AccountID: 3, Period: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUnpostedInterestPeriodsParams{
+					Before: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+					Limit:  10,
+				}
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Eq(arg)).Times(1).Return(periods, nil)
+				for _, period := range periods {
+					arg := db.PostInterestTxParams{AccountID: period.AccountID, Period: period.Period}
+					store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				}
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "PostError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Any()).Times(1).Return(periods, nil)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PostInterestTxResult{}, sql.ErrTxDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := PostInterest(store, 10, now)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
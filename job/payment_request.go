@@ -0,0 +1,38 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"code-with-go/notify"
+	"context"
+	"errors"
+)
+
+// ExpirePaymentRequests returns a task expiring the payment requests not answered in time, and notifying
+// their requester and the payers who didn't answer. Each request is expired in its own transaction.
+func ExpirePaymentRequests(store db.Store, notifier notify.Notifier, batchSize int32) Task {
+	return func(ctx context.Context) error {
+		requests, err := store.ListExpiredPaymentRequests(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, request := range requests {
+			result, err := store.ExpirePaymentRequestTx(ctx, request.ID)
+			if err != nil {
+				if errors.Is(err, db.ErrPaymentRequestNotPending) {
+					continue
+				}
+				return err
+			}
+
+			usernames := []string{result.PaymentRequest.Requester}
+			for _, payer := range result.Payers {
+				if payer.Status == db.PaymentRequestPayerExpired {
+					usernames = append(usernames, payer.Payer)
+				}
+			}
+			notify.Send(ctx, notifier, notify.KindPaymentRequestExpired, result, usernames...)
+		}
+		return nil
+	}
+}
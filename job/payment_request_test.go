@@ -0,0 +1,102 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/notify"
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (notifier *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	notifier.notifications = append(notifier.notifications, notification)
+	return nil
+}
+
+func TestJob_ExpirePaymentRequests(t *testing.T) {
+	requests := []db.PaymentRequest{
+		{ID: util.RandomInt(1, 1000), Requester: util.RandomOwner(), Status: db.PaymentRequestPending},
+		{ID: util.RandomInt(1001, 2000), Requester: util.RandomOwner(), Status: db.PaymentRequestPending},
+	}
+	expired := db.PaymentRequestTxResult{
+		PaymentRequest: db.PaymentRequest{ID: requests[1].ID, Requester: requests[1].Requester, Status: db.PaymentRequestExpired},
+		Payers: []db.PaymentRequestPayer{
+			{Payer: util.RandomOwner(), Status: db.PaymentRequestPayerAccepted},
+			{Payer: util.RandomOwner(), Status: db.PaymentRequestPayerExpired},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, err error, notifier *recordingNotifier)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPaymentRequests(gomock.Any(), gomock.Eq(int32(10))).Times(1).Return(requests, nil)
+				store.EXPECT().ExpirePaymentRequestTx(gomock.Any(), gomock.Eq(requests[0].ID)).Times(1).
+					Return(db.PaymentRequestTxResult{}, db.ErrPaymentRequestNotPending)
+				store.EXPECT().ExpirePaymentRequestTx(gomock.Any(), gomock.Eq(requests[1].ID)).Times(1).Return(expired, nil)
+			},
+			check: func(t *testing.T, err error, notifier *recordingNotifier) {
+				require.NoError(t, err)
+
+				// the payer who paid their share isn't told
+				require.Len(t, notifier.notifications, 2)
+				require.Equal(t, requests[1].Requester, notifier.notifications[0].Username)
+				require.Equal(t, expired.Payers[1].Payer, notifier.notifications[1].Username)
+				for _, notification := range notifier.notifications {
+					require.Equal(t, notify.KindPaymentRequestExpired, notification.Kind)
+				}
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPaymentRequests(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().ExpirePaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, err error, notifier *recordingNotifier) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+		{
+			name: "ExpireError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPaymentRequests(gomock.Any(), gomock.Any()).Times(1).Return(requests, nil)
+				store.EXPECT().ExpirePaymentRequestTx(gomock.Any(), gomock.Eq(requests[0].ID)).Times(1).
+					Return(db.PaymentRequestTxResult{}, sql.ErrTxDone)
+				store.EXPECT().ExpirePaymentRequestTx(gomock.Any(), gomock.Eq(requests[1].ID)).Times(0)
+			},
+			check: func(t *testing.T, err error, notifier *recordingNotifier) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+				require.Empty(t, notifier.notifications)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			notifier := &recordingNotifier{}
+			err := ExpirePaymentRequests(store, notifier, 10)(context.Background())
+			tc.check(t, err, notifier)
+		})
+	}
+}
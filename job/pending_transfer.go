@@ -0,0 +1,26 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"errors"
+)
+
+// ExpirePendingTransfers returns a task giving up on the transfers nobody approved in time and releasing their funds.
+// Each transfer is expired in its own transaction so a single failure does not block the others.
+func ExpirePendingTransfers(store db.Store, batchSize int32) Task {
+	return func(ctx context.Context) error {
+		pending, err := store.ListExpiredPendingTransfers(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, transfer := range pending {
+			_, err := store.ExpirePendingTransferTx(ctx, transfer.ID)
+			if err != nil && !errors.Is(err, db.ErrPendingTransferNotPending) {
+				return err
+			}
+		}
+		return nil
+	}
+}
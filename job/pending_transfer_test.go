@@ -0,0 +1,76 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"code-with-go/util"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJob_ExpirePendingTransfers(t *testing.T) {
+	pending := []db.PendingTransfer{
+		{ID: util.RandomInt(1, 1000), Status: db.PendingTransferPending},
+		{ID: util.RandomInt(1001, 2000), Status: db.PendingTransferPending},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPendingTransfers(gomock.Any(), gomock.Eq(int32(10))).Times(1).Return(pending, nil)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[0].ID)).Times(1)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[1].ID)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPendingTransfers(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[0].ID)).Times(1).
+					Return(db.PendingTransferTxResult{}, db.ErrPendingTransferNotPending)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[1].ID)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ExpireError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExpiredPendingTransfers(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[0].ID)).Times(1).
+					Return(db.PendingTransferTxResult{}, sql.ErrTxDone)
+				store.EXPECT().ExpirePendingTransferTx(gomock.Any(), gomock.Eq(pending[1].ID)).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := ExpirePendingTransfers(store, 10)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"log"
+)
+
+// Reconcile returns a task checking the ledger and recording a report.
+// Findings are only logged, repairing them waits for a banker to approve the report.
+func Reconcile(store db.Store) Task {
+	return func(ctx context.Context) error {
+		result, err := store.ReconcileTx(ctx)
+		if err != nil {
+			return err
+		}
+
+		if result.Report.Status == db.ReconciliationStatusOpen {
+			log.Printf("Reconciliation report %d has %d findings", result.Report.ID, result.Report.FindingsCount)
+		}
+		return nil
+	}
+}
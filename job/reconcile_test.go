@@ -0,0 +1,69 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJob_Reconcile(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "Clean",
+			buildStubs: func(store *mockdb.MockStore) {
+				result := db.ReconciliationResult{
+					Report: db.ReconciliationReport{ID: 1, Status: db.ReconciliationStatusClean},
+				}
+				store.EXPECT().ReconcileTx(gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Findings",
+			buildStubs: func(store *mockdb.MockStore) {
+				result := db.ReconciliationResult{
+					Report:   db.ReconciliationReport{ID: 2, Status: db.ReconciliationStatusOpen, FindingsCount: 1},
+					Findings: []db.ReconciliationFinding{{ID: 1, ReportID: 2, Kind: db.FindingBalanceDrift}},
+				}
+				store.EXPECT().ReconcileTx(gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReconcileTx(gomock.Any()).Times(1).Return(db.ReconciliationResult{}, sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := Reconcile(store)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
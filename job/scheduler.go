@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Task is a piece of background work run periodically by the Scheduler
+type Task func(ctx context.Context) error
+
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	task     Task
+}
+
+// Scheduler runs tasks in the background at fixed intervals
+type Scheduler struct {
+	tasks []scheduledTask
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers a task to be run each interval once the scheduler starts.
+func (scheduler *Scheduler) Every(name string, interval time.Duration, task Task) {
+	scheduler.tasks = append(scheduler.tasks, scheduledTask{
+		name:     name,
+		interval: interval,
+		task:     task,
+	})
+}
+
+// Start runs every registered task in its own goroutine until the context is cancelled.
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	for _, t := range scheduler.tasks {
+		go run(ctx, t)
+	}
+}
+
+func run(ctx context.Context, t scheduledTask) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.task(ctx); err != nil {
+				log.Printf("Error running job %s: %v", t.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"time"
+)
+
+// snapshotSettleDelay is how long a midnight is left alone before it gets snapshotted. A transfer's entries carry
+// the time its transaction began, so one still open at midnight only commits entries dated before it afterwards.
+// No transaction is expected to stay open this long.
+const snapshotSettleDelay = time.Hour
+
+// SnapshotBalances returns a task recording the balance of every account at the last UTC midnight that is
+// at least snapshotSettleDelay old, so every entry dated before it has committed by then.
+// Snapshots already taken for that midnight are kept, so running it many times a day is harmless.
+func SnapshotBalances(store db.Store, now func() time.Time) Task {
+	return func(ctx context.Context) error {
+		midnight := now().UTC().Add(-snapshotSettleDelay).Truncate(24 * time.Hour)
+		_, err := store.CreateBalanceSnapshots(ctx, midnight)
+		return err
+	}
+}
@@ -0,0 +1,88 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestJob_SnapshotBalances(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2022, 3, 14, 15, 9, 26, 0, time.FixedZone("UTC-5", -5*60*60))
+	}
+	midnight := time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		now        func() time.Time
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Eq(midnight)).Times(1).Return(int64(3), nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "JustAfterMidnight",
+			now: func() time.Time {
+				return time.Date(2022, 3, 14, 0, 5, 0, 0, time.UTC)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// transactions open at midnight may still commit entries dated before it, so the previous one is taken
+				store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Eq(midnight.AddDate(0, 0, -1))).Times(1).Return(int64(0), nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "MidnightSettled",
+			now: func() time.Time {
+				return midnight.Add(snapshotSettleDelay)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Eq(midnight)).Times(1).Return(int64(3), nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			clock := now
+			if tc.now != nil {
+				clock = tc.now
+			}
+			err := SnapshotBalances(store, clock)(context.Background())
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package job
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ForEachTenant returns a task running the task once for every tenant, with the context scoped to the tenant.
+// A tenant failing doesn't keep the task from running for the others.
+func ForEachTenant(store db.Store, task Task) Task {
+	return func(ctx context.Context) error {
+		tenants, err := store.ListTenants(ctx)
+		if err != nil {
+			return err
+		}
+
+		var failures []string
+		for _, tenant := range tenants {
+			if err := task(db.WithTenant(ctx, tenant.ID)); err != nil {
+				failures = append(failures, fmt.Sprintf("tenant %s: %v", tenant.Slug, err))
+			}
+		}
+		if len(failures) > 0 {
+			return errors.New(strings.Join(failures, "; "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package job
+
+import (
+	mockdb "code-with-go/db/mock"
+	db "code-with-go/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJob_ForEachTenant(t *testing.T) {
+	tenants := []db.Tenant{
+		{ID: db.DefaultTenantID, Slug: "default"},
+		{ID: 2, Slug: "brand"},
+		{ID: 3, Slug: "other"},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		task       func(ran *[]int64) Task
+		checkError func(t *testing.T, ran []int64, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTenants(gomock.Any()).Times(1).Return(tenants, nil)
+			},
+			task: func(ran *[]int64) Task {
+				return func(ctx context.Context) error {
+					*ran = append(*ran, db.TenantFromContext(ctx))
+					return nil
+				}
+			},
+			checkError: func(t *testing.T, ran []int64, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []int64{db.DefaultTenantID, 2, 3}, ran)
+			},
+		},
+		{
+			name: "TenantFails",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTenants(gomock.Any()).Times(1).Return(tenants, nil)
+			},
+			task: func(ran *[]int64) Task {
+				return func(ctx context.Context) error {
+					tenantID := db.TenantFromContext(ctx)
+					*ran = append(*ran, tenantID)
+					if tenantID == 2 {
+						return errors.New("boom")
+					}
+					return nil
+				}
+			},
+			checkError: func(t *testing.T, ran []int64, err error) {
+				require.EqualError(t, err, "tenant brand: boom")
+				require.Equal(t, []int64{db.DefaultTenantID, 2, 3}, ran)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTenants(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			task: func(ran *[]int64) Task {
+				return func(ctx context.Context) error {
+					*ran = append(*ran, db.TenantFromContext(ctx))
+					return nil
+				}
+			},
+			checkError: func(t *testing.T, ran []int64, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, ran)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var ran []int64
+			err := ForEachTenant(store, tc.task(&ran))(context.Background())
+			tc.checkError(t, ran, err)
+		})
+	}
+}
@@ -3,10 +3,17 @@ package main
 import (
 	"code-with-go/api"
 	db "code-with-go/db/sqlc"
+	"code-with-go/job"
+	"code-with-go/notify"
 	"code-with-go/util"
+	"context"
 	"database/sql"
+	"fmt"
 	_ "github.com/lib/pq"
 	"log"
+	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -20,6 +27,48 @@ func main() {
 		log.Fatal("Cannot connect to db: ", err)
 	}
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile":
+			reconcile(store)
+		case "verify-chain":
+			verifyChain(store, os.Args[2:])
+		default:
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+		return
+	}
+
+	// every job runs over the data of each tenant in turn
+	scheduler := job.NewScheduler()
+	scheduler.Every("release expired holds", config.HoldSweepInterval,
+		job.ForEachTenant(store, job.ReleaseExpiredHolds(store, 100)))
+	scheduler.Every("expire pending transfers", config.TransferApprovalSweepInterval,
+		job.ForEachTenant(store, job.ExpirePendingTransfers(store, 100)))
+	scheduler.Every("expire payment requests", config.PaymentRequestSweepInterval,
+		job.ForEachTenant(store, job.ExpirePaymentRequests(store, notify.New(config.NotificationWebhookURL), 100)))
+	scheduler.Every("refund overdue escrows", config.EscrowSweepInterval,
+		job.ForEachTenant(store, job.RefundOverdueEscrows(store, 100)))
+	scheduler.Every("snapshot balances", config.SnapshotInterval,
+		job.ForEachTenant(store, job.SnapshotBalances(store, time.Now)))
+	scheduler.Every("reconcile ledger", config.ReconcileInterval,
+		job.ForEachTenant(store, job.Reconcile(store)))
+	scheduler.Every("accrue interest", config.InterestAccrualInterval,
+		job.ForEachTenant(store, job.AccrueInterest(store, 100, time.Now)))
+	scheduler.Every("post interest", config.InterestPostingInterval,
+		job.ForEachTenant(store, job.PostInterest(store, 100, time.Now)))
+
+	chainKey, err := config.ChainSigningKey()
+	if err != nil {
+		log.Fatal("Cannot load the chain signing key: ", err)
+	}
+	if chainKey != nil {
+		scheduler.Every("sign chain root", config.ChainRootInterval,
+			job.ForEachTenant(store, job.SignChainRoot(store, chainKey, time.Now)))
+	}
+	scheduler.Start(context.Background())
+
 	server, err := api.NewServer(store, config)
 	if err != nil {
 		log.Fatal("Cannot create the server: ", err)
@@ -30,3 +79,59 @@ func main() {
 		log.Fatal("Cannot start the server: ", err)
 	}
 }
+
+// tenants lists every tenant, the commands check each of them
+func tenants(store db.Store) []db.Tenant {
+	tenants, err := store.ListTenants(context.Background())
+	if err != nil {
+		log.Fatal("Cannot list the tenants: ", err)
+	}
+	return tenants
+}
+
+// reconcile checks the ledger of every tenant once, prints the findings and exits with status 1 when there are any
+func reconcile(store db.Store) {
+	found := false
+	for _, tenant := range tenants(store) {
+		result, err := store.ReconcileTx(db.WithTenant(context.Background(), tenant.ID))
+		if err != nil {
+			log.Fatalf("Cannot reconcile the ledger of %s: %v", tenant.Slug, err)
+		}
+
+		fmt.Printf("%s report %d: %s, %d findings\n", tenant.Slug, result.Report.ID, result.Report.Status, result.Report.FindingsCount)
+		for _, finding := range result.Findings {
+			fmt.Printf("%s account=%d entry=%d transfer=%d expected=%d actual=%d\n", finding.Kind,
+				finding.AccountID.Int64, finding.EntryID.Int64, finding.TransferID.Int64, finding.Expected, finding.Actual)
+		}
+		found = found || len(result.Findings) > 0
+	}
+	if found {
+		os.Exit(1)
+	}
+}
+
+// verifyChain walks the entry hash chains of every tenant, of a single account when its id is given,
+// and exits with status 1 at the first broken link
+func verifyChain(store db.Store, args []string) {
+	var accountID int64
+	if len(args) > 0 {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatal("Invalid account id: ", err)
+		}
+		accountID = id
+	}
+
+	for _, tenant := range tenants(store) {
+		chainBreak, err := store.VerifyEntryChain(db.WithTenant(context.Background(), tenant.ID), accountID)
+		if err != nil {
+			log.Fatalf("Cannot verify the entry chain of %s: %v", tenant.Slug, err)
+		}
+
+		if chainBreak != nil {
+			fmt.Printf("%s: broken link at entry %d of account %d: %s\n", tenant.Slug, chainBreak.EntryID, chainBreak.AccountID, chainBreak.Reason)
+			os.Exit(1)
+		}
+	}
+	fmt.Println("entry chain is intact")
+}
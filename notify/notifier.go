@@ -0,0 +1,65 @@
+package notify
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	KindPaymentRequestCreated  = "payment_request.created"
+	KindPaymentRequestAccepted = "payment_request.accepted"
+	KindPaymentRequestDeclined = "payment_request.declined"
+	KindPaymentRequestExpired  = "payment_request.expired"
+)
+
+// Notification tells a user that something they take part in changed.
+// Usernames are only unique within a tenant, the tenant tells which user it is.
+type Notification struct {
+	TenantID int64  `json:"tenant_id"`
+	Username string `json:"username"`
+	Kind     string `json:"kind"`
+	// Subject is what changed
+	Subject   interface{} `json:"subject"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// New posts the notifications to the webhook, or only logs them when no webhook is configured
+func New(webhookURL string) Notifier {
+	if webhookURL == "" {
+		return LogNotifier{}
+	}
+	return NewWebhookNotifier(webhookURL)
+}
+
+// Send notifies each of the users of the tenant of the context.
+// A failed notification is only logged, what it tells already happened.
+func Send(ctx context.Context, notifier Notifier, kind string, subject interface{}, usernames ...string) {
+	tenantID := db.TenantFromContext(ctx)
+	for _, username := range usernames {
+		err := notifier.Notify(ctx, Notification{
+			TenantID:  tenantID,
+			Username:  username,
+			Kind:      kind,
+			Subject:   subject,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("Cannot notify %s of %s: %v", username, kind, err)
+		}
+	}
+}
+
+// LogNotifier writes the notifications to the log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, notification Notification) error {
+	log.Printf("Notify %s of tenant %d of %s", notification.Username, notification.TenantID, notification.Kind)
+	return nil
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	db "code-with-go/db/sqlc"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var received []Notification
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var notification Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		received = append(received, notification)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL)
+	require.IsType(t, &WebhookNotifier{}, notifier)
+
+	err := notifier.Notify(context.Background(), Notification{Username: "alice", Kind: KindPaymentRequestCreated, Subject: 1})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, "alice", received[0].Username)
+	require.Equal(t, KindPaymentRequestCreated, received[0].Kind)
+
+	status = http.StatusInternalServerError
+	err = notifier.Notify(context.Background(), Notification{Username: "bob", Kind: KindPaymentRequestDeclined})
+	require.Error(t, err)
+
+	// Send keeps going when a notification fails
+	Send(context.Background(), notifier, KindPaymentRequestExpired, nil, "alice", "bob")
+	require.Len(t, received, 4)
+	require.Equal(t, db.DefaultTenantID, received[2].TenantID)
+}
+
+func TestSend_Tenant(t *testing.T) {
+	var received []Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		received = append(received, notification)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	Send(db.WithTenant(context.Background(), 7), New(server.URL), KindPaymentRequestCreated, nil, "alice")
+	require.Len(t, received, 1)
+	require.Equal(t, int64(7), received[0].TenantID)
+	require.Equal(t, "alice", received[0].Username)
+}
+
+func TestNew_WithoutWebhook(t *testing.T) {
+	notifier := New("")
+	require.Equal(t, LogNotifier{}, notifier)
+	require.NoError(t, notifier.Notify(context.Background(), Notification{Username: "alice"}))
+}
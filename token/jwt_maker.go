@@ -20,8 +20,8 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *JWTMaker) CreateToken(username string, role string, tenantID int64, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, role, tenantID, duration)
 	if err != nil {
 		return "", err
 	}
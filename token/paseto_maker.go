@@ -12,8 +12,8 @@ type PasetoMaker struct {
 	symmetricKey []byte
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, role string, tenantID int64, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, role, tenantID, duration)
 	if err != nil {
 		return "", err
 	}
@@ -13,12 +13,14 @@ func TestPasetoToken_JWTMaker(t *testing.T) {
 
 	require.NoError(t, err)
 	username := util.RandomOwner()
+	role := util.DepositorRole
+	tenantID := util.RandomInt(1, 1000)
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expireAt := time.Now().Add(duration)
 
-	token, err := maker.CreateToken(username, duration)
+	token, err := maker.CreateToken(username, role, tenantID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -28,6 +30,8 @@ func TestPasetoToken_JWTMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, tenantID, payload.TenantID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expireAt, payload.ExpiredAt, time.Second)
 }
@@ -36,7 +40,7 @@ func TestPasetoToken_ExpiredToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, err := maker.CreateToken(util.RandomOwner(), -time.Minute)
+	token, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, 1, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -47,7 +51,7 @@ func TestPasetoToken_ExpiredToken(t *testing.T) {
 }
 
 func TestPasetoToken_InvalidOrNoneAlg(t *testing.T) {
-	payload, err := NewPayload(util.RandomOwner(), time.Minute)
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, 1, time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
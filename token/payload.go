@@ -9,14 +9,17 @@ import (
 var ErrInvalidToken = errors.New("invalid expired")
 var ErrExpiredToken = errors.New("token expired")
 
+// Payload is carried by a token. Requests made with it are scoped to the tenant the user signed up with.
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	TenantID  int64     `json:"tenant_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, tenantID int64, duration time.Duration) (*Payload, error) {
 	ID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -24,6 +27,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        ID,
 		Username:  username,
+		Role:      role,
+		TenantID:  tenantID,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -1,16 +1,49 @@
 package util
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
 	"github.com/spf13/viper"
 	"time"
 )
 
 type Config struct {
-	DBDriver      string        `mapstructure:"DB_DRIVER"`
-	DBSource      string        `mapstructure:"DB_SOURCE"`
-	ServerAddress string        `mapstructure:"SERVER_ADDRESS"`
-	TokenKey      string        `mapstructure:"TOKEN_KEY"`
-	TokenDuration time.Duration `mapstructure:"TOKEN_DURATION"`
+	DBDriver          string        `mapstructure:"DB_DRIVER"`
+	DBSource          string        `mapstructure:"DB_SOURCE"`
+	ServerAddress     string        `mapstructure:"SERVER_ADDRESS"`
+	TokenKey          string        `mapstructure:"TOKEN_KEY"`
+	TokenDuration     time.Duration `mapstructure:"TOKEN_DURATION"`
+	HoldDuration      time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldSweepInterval time.Duration `mapstructure:"HOLD_SWEEP_INTERVAL"`
+	SnapshotInterval  time.Duration `mapstructure:"SNAPSHOT_INTERVAL"`
+	ReconcileInterval time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	// ChainSigningSeed is the hex encoded ed25519 seed signing the daily root of the entry hash chains
+	ChainSigningSeed  string        `mapstructure:"CHAIN_SIGNING_SEED"`
+	ChainRootInterval time.Duration `mapstructure:"CHAIN_ROOT_INTERVAL"`
+	// the interest jobs are idempotent per day and per month, the intervals only bound how late they catch up
+	InterestAccrualInterval time.Duration `mapstructure:"INTEREST_ACCRUAL_INTERVAL"`
+	InterestPostingInterval time.Duration `mapstructure:"INTEREST_POSTING_INTERVAL"`
+	// FraudRulesFile is the YAML file of the fraud rules transfers are screened against, bankers can add more at runtime
+	FraudRulesFile string `mapstructure:"FRAUD_RULES_FILE"`
+	// transfers above the threshold wait for a second person's approval, zero turns approvals off
+	TransferApprovalThreshold     int64         `mapstructure:"TRANSFER_APPROVAL_THRESHOLD"`
+	TransferApprovalDuration      time.Duration `mapstructure:"TRANSFER_APPROVAL_DURATION"`
+	TransferApprovalSweepInterval time.Duration `mapstructure:"TRANSFER_APPROVAL_SWEEP_INTERVAL"`
+	// a new beneficiary can't receive more than the large amount until its cooling-off is over, zero turns it off
+	BeneficiaryCoolingOff  time.Duration `mapstructure:"BENEFICIARY_COOLING_OFF"`
+	BeneficiaryLargeAmount int64         `mapstructure:"BENEFICIARY_LARGE_AMOUNT"`
+	// payment requests left unanswered for the duration expire
+	PaymentRequestDuration      time.Duration `mapstructure:"PAYMENT_REQUEST_DURATION"`
+	PaymentRequestSweepInterval time.Duration `mapstructure:"PAYMENT_REQUEST_SWEEP_INTERVAL"`
+	// escrow deadlines can't be further away than the max duration, funds still in escrow by then are refunded
+	EscrowMaxDuration   time.Duration `mapstructure:"ESCROW_MAX_DURATION"`
+	EscrowSweepInterval time.Duration `mapstructure:"ESCROW_SWEEP_INTERVAL"`
+	// TenantByHost scopes the requests to a host a tenant is served on to that tenant,
+	// otherwise only the tokens tell tenants apart
+	TenantByHost bool `mapstructure:"TENANT_BY_HOST"`
+	// NotificationWebhookURL receives the notifications to users as JSON posts, they are only logged when it's empty
+	NotificationWebhookURL string `mapstructure:"NOTIFICATION_WEBHOOK_URL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -28,3 +61,19 @@ func LoadConfig(path string) (config Config, err error) {
 	err = viper.Unmarshal(&config)
 	return
 }
+
+// ChainSigningKey decodes the chain signing seed, it returns a nil key when no seed is configured.
+func (config Config) ChainSigningKey() (ed25519.PrivateKey, error) {
+	if config.ChainSigningSeed == "" {
+		return nil, nil
+	}
+
+	seed, err := hex.DecodeString(config.ChainSigningSeed)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("chain signing seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_ChainSigningKey(t *testing.T) {
+	key, err := Config{}.ChainSigningKey()
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	seed := RandomString(ed25519.SeedSize)
+	key, err = Config{ChainSigningSeed: hex.EncodeToString([]byte(seed))}.ChainSigningKey()
+	require.NoError(t, err)
+	require.Equal(t, []byte(seed), []byte(key.Seed()))
+
+	_, err = Config{ChainSigningSeed: "not hex"}.ChainSigningKey()
+	require.Error(t, err)
+
+	_, err = Config{ChainSigningSeed: "abcd"}.ChainSigningKey()
+	require.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package util
+
+import "strings"
+
+// MaskName keeps the first letter of each word of a name and hides the rest,
+// enough for a payer to recognise the payee without revealing the full name
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		letters := []rune(word)
+		words[i] = string(letters[0]) + strings.Repeat("*", len(letters)-1)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,13 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_MaskName(t *testing.T) {
+	require.Equal(t, "J*** S****", MaskName("John Smith"))
+	require.Equal(t, "Z** Ø******", MaskName("  Zoë   Ødegård "))
+	require.Equal(t, "A", MaskName("A"))
+	require.Empty(t, MaskName(""))
+}
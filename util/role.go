@@ -0,0 +1,8 @@
+package util
+
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	// ApproverRole may approve the transfers waiting for a second person
+	ApproverRole = "approver"
+)